@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/xml"
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -11,6 +11,9 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"onvifctl/onvif"
+	"onvifctl/soap"
 )
 
 // WS-Discovery 相关结构
@@ -23,6 +26,11 @@ type ProbeMatch struct {
 
 // 设备发现
 func DiscoverDevices(timeout int, ifaceName string, debug bool) ([]ProbeMatch, error) {
+	messageID, err := soap.NewUUID()
+	if err != nil {
+		return nil, fmt.Errorf("生成 MessageID 失败: %w", err)
+	}
+
 	// 构建 WS-Discovery Probe 消息
 	probeMsg := `<?xml version="1.0" encoding="UTF-8"?>
 <s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" 
@@ -30,7 +38,7 @@ func DiscoverDevices(timeout int, ifaceName string, debug bool) ([]ProbeMatch, e
             xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery">
   <s:Header>
     <a:Action>http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe</a:Action>
-    <a:MessageID>uuid:` + generateUUID() + `</a:MessageID>
+    <a:MessageID>uuid:` + messageID + `</a:MessageID>
     <a:To>urn:schemas-xmlsoap-org:ws:2005:04:discovery</a:To>
   </s:Header>
   <s:Body>
@@ -141,16 +149,6 @@ func DiscoverDevices(timeout int, ifaceName string, debug bool) ([]ProbeMatch, e
 	return result, nil
 }
 
-// 生成 UUID
-func generateUUID() string {
-	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
-		time.Now().UnixNano()&0xFFFFFFFF,
-		time.Now().UnixNano()>>32&0xFFFF,
-		0x4000|(time.Now().UnixNano()>>48&0x0FFF),
-		0x8000|(time.Now().UnixNano()>>60&0x3FFF),
-		time.Now().UnixNano()&0xFFFFFFFFFFFF)
-}
-
 // 保存设备列表到文件
 func SaveDevicesToFile(devices []ProbeMatch, filename string) error {
 	data, err := yaml.Marshal(devices)
@@ -160,449 +158,159 @@ func SaveDevicesToFile(devices []ProbeMatch, filename string) error {
 	return os.WriteFile(filename, data, 0644)
 }
 
-// 时间同步相关结构
-type SetSystemDateAndTime struct {
-	XMLName         xml.Name     `xml:"http://www.onvif.org/ver10/device/wsdl SetSystemDateAndTime"`
-	DateTimeType    string       `xml:"DateTimeType"`
-	DaylightSavings bool         `xml:"DaylightSavings"`
-	UTCDateTime     *UTCDateTime `xml:"UTCDateTime,omitempty"`
-}
-
-type SetNTP struct {
-	XMLName   xml.Name      `xml:"http://www.onvif.org/ver10/device/wsdl SetNTP"`
-	FromDHCP  bool          `xml:"FromDHCP"`
-	NTPManual []NetworkHost `xml:"NTPManual"`
-}
-
-type NetworkHost struct {
-	Type        string `xml:"Type"`
-	IPv4Address string `xml:"IPv4Address,omitempty"`
-	DNSname     string `xml:"DNSname,omitempty"`
-}
-
-// 获取系统时间
-func (c *ONVIFClient) GetSystemTime() error {
-	respData, err := c.sendRequest(c.XAddr, &GetSystemDateAndTime{})
-	if err != nil {
-		return err
-	}
-
-	var timeResp struct {
-		Body struct {
-			GetSystemDateAndTimeResponse GetSystemDateAndTimeResponse
-		}
-	}
-
-	if err := xml.Unmarshal(respData, &timeResp); err != nil {
-		return fmt.Errorf("解析时间失败: %w", err)
-	}
-
-	dt := timeResp.Body.GetSystemDateAndTimeResponse.SystemDateAndTime
-
-	fmt.Println("=== 设备时间 ===")
-	fmt.Printf("时间类型: %s\n", dt.DateTimeType)
-	fmt.Printf("设备时间: %04d-%02d-%02d %02d:%02d:%02d UTC\n",
-		dt.UTCDateTime.Date.Year,
-		dt.UTCDateTime.Date.Month,
-		dt.UTCDateTime.Date.Day,
-		dt.UTCDateTime.Time.Hour,
-		dt.UTCDateTime.Time.Minute,
-		dt.UTCDateTime.Time.Second,
-	)
-
-	// 显示与系统时间的差异
-	deviceTime := time.Date(
-		dt.UTCDateTime.Date.Year,
-		time.Month(dt.UTCDateTime.Date.Month),
-		dt.UTCDateTime.Date.Day,
-		dt.UTCDateTime.Time.Hour,
-		dt.UTCDateTime.Time.Minute,
-		dt.UTCDateTime.Time.Second,
-		0, time.UTC,
-	)
-
-	diff := time.Since(deviceTime)
-	fmt.Printf("时间差异: %.0f 秒\n", diff.Seconds())
-
-	return nil
-}
-
-// 同步系统时间
-func (c *ONVIFClient) SyncSystemTime() error {
-	now := time.Now().UTC()
-
-	setTimeReq := SetSystemDateAndTime{
-		DateTimeType:    "Manual",
-		DaylightSavings: false,
-		UTCDateTime: &UTCDateTime{
-			Time: Time{
-				Hour:   now.Hour(),
-				Minute: now.Minute(),
-				Second: now.Second(),
-			},
-			Date: Date{
-				Year:  now.Year(),
-				Month: int(now.Month()),
-				Day:   now.Day(),
-			},
-		},
-	}
-
-	_, err := c.sendRequest(c.XAddr, &setTimeReq)
-	if err != nil {
-		return fmt.Errorf("同步时间失败: %w", err)
-	}
-
-	fmt.Println("✓ 时间同步成功")
-	fmt.Printf("  设备时间已设置为: %s\n", now.Format("2006-01-02 15:04:05 UTC"))
-
-	return nil
-}
-
-// 设置 NTP 服务器
-func (c *ONVIFClient) SetNTP(ntpServer string) error {
-	setNTPReq := SetNTP{
-		FromDHCP: false,
-		NTPManual: []NetworkHost{
-			{
-				Type:        "IPv4",
-				IPv4Address: ntpServer,
-			},
-		},
-	}
-
-	_, err := c.sendRequest(c.XAddr, &setNTPReq)
-	if err != nil {
-		return fmt.Errorf("设置 NTP 失败: %w", err)
-	}
-
-	fmt.Println("✓ NTP 服务器设置成功")
-	fmt.Printf("  NTP 服务器: %s\n", ntpServer)
-
-	return nil
-}
-
-// 事件订阅相关结构
-type CreatePullPointSubscription struct {
-	XMLName                xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl CreatePullPointSubscription"`
-	Filter                 *Filter  `xml:"Filter,omitempty"`
-	InitialTerminationTime string   `xml:"InitialTerminationTime,omitempty"`
-}
-
-type Filter struct {
-	TopicExpression string `xml:"TopicExpression,omitempty"`
-}
-
-type CreatePullPointSubscriptionResponse struct {
-	SubscriptionReference SubscriptionReference `xml:"SubscriptionReference"`
-	CurrentTime           string                `xml:"CurrentTime"`
-	TerminationTime       string                `xml:"TerminationTime"`
-}
-
-type SubscriptionReference struct {
-	Address string `xml:"Address"`
-}
-
-type PullMessages struct {
-	XMLName      xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl PullMessages"`
-	Timeout      string   `xml:"Timeout"`
-	MessageLimit int      `xml:"MessageLimit"`
-}
-
-type PullMessagesResponse struct {
-	CurrentTime         string                `xml:"CurrentTime"`
-	TerminationTime     string                `xml:"TerminationTime"`
-	NotificationMessage []NotificationMessage `xml:"NotificationMessage"`
-}
-
-type NotificationMessage struct {
-	Topic   Topic   `xml:"Topic"`
-	Message Message `xml:"Message"`
-}
-
-type Topic struct {
-	Dialect string `xml:"Dialect,attr"`
-	Value   string `xml:",chardata"`
-}
-
-type Message struct {
-	UtcTime string      `xml:"UtcTime,attr"`
-	Source  EventSource `xml:"Source"`
-	Data    EventData   `xml:"Data"`
-}
-
-type EventSource struct {
-	SimpleItem []SimpleItem `xml:"SimpleItem"`
-}
-
-type EventData struct {
-	SimpleItem []SimpleItem `xml:"SimpleItem"`
-}
-
-type SimpleItem struct {
-	Name  string `xml:"Name,attr"`
-	Value string `xml:"Value,attr"`
-}
-
-type Renew struct {
-	XMLName         xml.Name `xml:"http://docs.oasis-open.org/wsn/b-2 Renew"`
-	TerminationTime string   `xml:"TerminationTime"`
-}
-
-type RenewResponse struct {
-	TerminationTime string `xml:"TerminationTime"`
-	CurrentTime     string `xml:"CurrentTime"`
-}
-
-type Unsubscribe struct {
-	XMLName xml.Name `xml:"http://docs.oasis-open.org/wsn/b-2 Unsubscribe"`
-}
-
-// 事件订阅完整实现
-func (c *ONVIFClient) SubscribeEvents(duration int, filter string) error {
-	fmt.Printf("正在订阅设备事件 (持续 %d 秒)...\n\n", duration)
-
-	eventAddr := fmt.Sprintf("%s://%s:%d/onvif/event_service",
-		map[bool]string{true: "https", false: "http"}[c.UseHTTPS], c.Host, c.Port)
+// 订阅并打印设备事件, 持续 duration 秒或直到 ctx 被取消
+func subscribeEvents(ctx context.Context, client *onvif.Client, duration int, filter string) error {
+	statusf("正在订阅设备事件 (持续 %d 秒)...\n\n", duration)
 
 	// 1. 创建 PullPoint 订阅
-	fmt.Println("步骤 1: 创建 PullPoint 订阅...")
+	statusf("步骤 1: 创建 PullPoint 订阅...\n")
 
-	subscribeReq := CreatePullPointSubscription{
-		InitialTerminationTime: fmt.Sprintf("PT%dS", duration),
-	}
+	termination := time.Duration(duration) * time.Second
 
-	// 如果指定了过滤器
-	if filter != "" {
-		subscribeReq.Filter = &Filter{
-			TopicExpression: filter,
-		}
-	}
-
-	respData, err := c.sendRequest(eventAddr, &subscribeReq)
+	sub, err := client.CreatePullPointSubscription(ctx, filter, termination)
 	if err != nil {
 		return fmt.Errorf("创建订阅失败: %w", err)
 	}
 
-	var subscribeResp struct {
-		Body struct {
-			CreatePullPointSubscriptionResponse CreatePullPointSubscriptionResponse
-		}
-	}
-
-	if err = xml.Unmarshal(respData, &subscribeResp); err != nil {
-		return fmt.Errorf("解析订阅响应失败: %w", err)
-	}
-
-	subRef := subscribeResp.Body.CreatePullPointSubscriptionResponse
-	pullPointAddr := subRef.SubscriptionReference.Address
-
-	fmt.Printf("✓ 订阅成功\n")
-	fmt.Printf("  订阅地址: %s\n", pullPointAddr)
-	fmt.Printf("  当前时间: %s\n", subRef.CurrentTime)
-	fmt.Printf("  终止时间: %s\n\n", subRef.TerminationTime)
+	statusf("✓ 订阅成功\n")
+	statusf("  订阅地址: %s\n", sub.Address)
+	statusf("  当前时间: %s\n", sub.CurrentTime)
+	statusf("  终止时间: %s\n\n", sub.TerminationTime)
 
 	// 2. 开始拉取消息
-	fmt.Println("步骤 2: 开始监听事件...")
-	fmt.Println("----------------------------------------")
+	statusf("步骤 2: 开始监听事件...\n")
+	statusf("----------------------------------------\n")
 
 	startTime := time.Now()
-	endTime := startTime.Add(time.Duration(duration) * time.Second)
+	endTime := startTime.Add(termination)
 	messageCount := 0
-	renewInterval := time.Duration(duration/2) * time.Second
+	renewInterval := termination / 2
 	lastRenew := startTime
 
-	for time.Now().Before(endTime) {
+	var out streamRenderer
+	defer out.close()
+
+	for time.Now().Before(endTime) && ctx.Err() == nil {
 		// 定期续订
 		if time.Since(lastRenew) > renewInterval {
-			if err = c.renewSubscription(pullPointAddr, duration); err != nil {
-				fmt.Printf("⚠ 续订失败: %v\n", err)
+			if err := sub.Renew(ctx, termination); err != nil {
+				statusf("⚠ 续订失败: %v\n", err)
 			} else {
 				lastRenew = time.Now()
-				if c.Debug {
-					fmt.Println("✓ 订阅已续订")
+				if client.Debug {
+					statusf("✓ 订阅已续订\n")
 				}
 			}
 		}
 
 		// 拉取消息
-		pullReq := PullMessages{
-			Timeout:      "PT5S",
-			MessageLimit: 10,
-		}
-
-		respData, err = c.sendRequest(pullPointAddr, &pullReq)
+		messages, err := sub.PullMessages(ctx, 5*time.Second, 10)
 		if err != nil {
-			if c.Debug {
-				fmt.Printf("拉取消息失败: %v\n", err)
+			if client.Debug {
+				statusf("拉取消息失败: %v\n", err)
 			}
-			time.Sleep(2 * time.Second)
+			sleepContext(ctx, 2*time.Second)
 			continue
 		}
 
-		var pullResp struct {
-			Body struct {
-				PullMessagesResponse PullMessagesResponse
-			}
-		}
-
-		if err = xml.Unmarshal(respData, &pullResp); err != nil {
-			if c.Debug {
-				fmt.Printf("解析消息失败: %v\n", err)
-			}
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		messages := pullResp.Body.PullMessagesResponse.NotificationMessage
-
 		// 处理接收到的消息
 		for _, msg := range messages {
 			messageCount++
-			c.printEventMessage(messageCount, msg)
+			if err := out.emit(newEventOutput(msg), func() { printEventMessage(messageCount, msg) }); err != nil {
+				return err
+			}
 		}
 
 		// 如果没有消息，短暂休眠
 		if len(messages) == 0 {
-			time.Sleep(1 * time.Second)
+			sleepContext(ctx, 1*time.Second)
 		}
 	}
 
-	// 3. 取消订阅
-	fmt.Println("\n----------------------------------------")
-	fmt.Println("步骤 3: 取消订阅...")
+	// 3. 取消订阅 (被中断时也要清理设备端订阅, 因此不使用已取消的 ctx)
+	statusf("\n----------------------------------------\n")
+	statusf("步骤 3: 取消订阅...\n")
+
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	if err := c.unsubscribe(pullPointAddr); err != nil {
-		fmt.Printf("⚠ 取消订阅失败: %v\n", err)
+	if err := sub.Unsubscribe(cleanupCtx); err != nil {
+		statusf("⚠ 取消订阅失败: %v\n", err)
 	} else {
-		fmt.Println("✓ 订阅已取消")
+		statusf("✓ 订阅已取消\n")
 	}
 
-	fmt.Printf("\n事件监听完成，共接收 %d 条消息\n", messageCount)
+	statusf("\n事件监听完成，共接收 %d 条消息\n", messageCount)
 
-	return nil
+	return ctx.Err()
 }
 
-// 续订订阅
-func (c *ONVIFClient) renewSubscription(pullPointAddr string, duration int) error {
-	renewReq := Renew{
-		TerminationTime: fmt.Sprintf("PT%dS", duration),
-	}
-
-	respData, err := c.sendRequest(pullPointAddr, &renewReq)
-	if err != nil {
-		return err
-	}
-
-	var renewResp struct {
-		Body struct {
-			RenewResponse RenewResponse
-		}
-	}
+// 可被 ctx 打断的休眠
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	if err := xml.Unmarshal(respData, &renewResp); err != nil {
-		return err
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
 	}
-
-	return nil
 }
 
-// 取消订阅
-func (c *ONVIFClient) unsubscribe(pullPointAddr string) error {
-	unsubReq := Unsubscribe{}
-	_, err := c.sendRequest(pullPointAddr, &unsubReq)
-	return err
+// 批量配置管理
+type BatchConfig struct {
+	Devices []DeviceConfig `yaml:"devices"`
 }
 
-// 打印事件消息
-func (c *ONVIFClient) printEventMessage(count int, msg NotificationMessage) {
-	fmt.Printf("\n[事件 #%d]\n", count)
-	fmt.Printf("时间: %s\n", msg.Message.UtcTime)
-	fmt.Printf("主题: %s\n", msg.Topic.Value)
-
-	// 打印事件源
-	if len(msg.Message.Source.SimpleItem) > 0 {
-		fmt.Println("来源:")
-		for _, item := range msg.Message.Source.SimpleItem {
-			fmt.Printf("  %s: %s\n", item.Name, item.Value)
-		}
-	}
+type DeviceConfig struct {
+	Name     string `yaml:"name"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password,omitempty"`
+	UseHTTPS bool   `yaml:"use_https"`
+	Auth     string `yaml:"auth,omitempty"` // 认证模式, 留空为 ws-security, 混合厂商环境可用 auto
 
-	// 打印事件数据
-	if len(msg.Message.Data.SimpleItem) > 0 {
-		fmt.Println("数据:")
-		for _, item := range msg.Message.Data.SimpleItem {
-			fmt.Printf("  %s: %s\n", item.Name, item.Value)
-		}
-	}
+	PasswordFile string `yaml:"password_file,omitempty"` // 从文件读取密码 (取第一行), 相对路径相对于配置文件所在目录
+	PasswordCmd  string `yaml:"password_cmd,omitempty"`  // 执行命令取得密码 (取标准输出第一行), 可对接系统密钥环
 
-	// 解析常见事件类型
-	c.parseEventType(msg)
-}
+	TLSFingerprint string `yaml:"tls_fingerprint,omitempty"` // 固定的设备证书 SHA-256 指纹, 设置后不再按 CA 或 known_hosts 校验
 
-// 解析事件类型
-func (c *ONVIFClient) parseEventType(msg NotificationMessage) {
-	topic := msg.Topic.Value
-
-	// 运动检测
-	if strings.Contains(topic, "MotionDetector") || strings.Contains(topic, "CellMotionDetector") {
-		for _, item := range msg.Message.Data.SimpleItem {
-			if item.Name == "State" {
-				if item.Value == "true" {
-					fmt.Println(">>> 检测到运动!")
-				} else {
-					fmt.Println(">>> 运动结束")
-				}
-			}
-		}
-	}
+	configDir string // 所在配置文件的目录
+}
 
-	// 篡改检测
-	if strings.Contains(topic, "TamperDetector") {
-		for _, item := range msg.Message.Data.SimpleItem {
-			if item.Name == "State" {
-				if item.Value == "true" {
-					fmt.Println(">>> 检测到篡改!")
-				} else {
-					fmt.Println(">>> 篡改结束")
-				}
-			}
-		}
+// 根据设备配置创建客户端
+func newClientFromDevice(dev DeviceConfig) (*onvif.Client, error) {
+	if dev.Auth != "" && !onvif.IsValidAuthMode(dev.Auth) {
+		return nil, fmt.Errorf("未知的认证模式: %s", dev.Auth)
 	}
 
-	// 音频检测
-	if strings.Contains(topic, "AudioAnalytics") {
-		fmt.Println(">>> 音频事件触发")
+	pass, err := dev.resolvePassword()
+	if err != nil {
+		return nil, err
 	}
 
-	// 区域入侵
-	if strings.Contains(topic, "FieldDetector") {
-		fmt.Println(">>> 区域入侵检测")
+	tlsOpts, err := tlsOptions(dev.TLSFingerprint)
+	if err != nil {
+		return nil, err
 	}
 
-	// 越界检测
-	if strings.Contains(topic, "LineDetector") {
-		fmt.Println(">>> 越界检测")
-	}
+	return onvif.NewClient(onvif.Config{
+		Host:     dev.Host,
+		Port:     dev.Port,
+		Username: dev.Username,
+		Password: pass,
+		UseHTTPS: dev.UseHTTPS,
+		AuthMode: dev.Auth,
+		Timeouts: timeoutsFromFlags(),
+		TLS:      tlsOpts,
+		Debug:    debug,
+		Recorder: traceRecorder,
+	})
 }
 
-// 批量配置管理
-type BatchConfig struct {
-	Devices []DeviceConfig `yaml:"devices"`
-}
-
-type DeviceConfig struct {
-	Name     string `yaml:"name"`
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
-	UseHTTPS bool   `yaml:"use_https"`
-}
-
-// 加载批量配置
+// 加载批量配置 (已加密的文件使用主密钥解密)
 func LoadBatchConfig(filename string) (*BatchConfig, error) {
-	data, err := os.ReadFile(filename)
+	data, _, err := readConfigFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -611,163 +319,153 @@ func LoadBatchConfig(filename string) (*BatchConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	config.setConfigDir(filepath.Dir(filename))
 
 	return &config, nil
 }
 
-// 保存批量配置
-func SaveBatchConfig(config *BatchConfig, filename string) error {
+// 保存批量配置, key 不为空时使用主密钥加密
+func SaveBatchConfig(config *BatchConfig, filename string, key []byte) error {
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filename, data, 0644)
+	return writeConfigFile(filename, data, key)
 }
 
-// 批量获取设备信息
-func BatchGetInfo(config *BatchConfig) error {
-	fmt.Printf("正在获取 %d 个设备的信息...\n\n", len(config.Devices))
+// 记录设备所在配置文件的目录 (用于解析 password_file 相对路径)
+func (c *BatchConfig) setConfigDir(dir string) {
+	for i := range c.Devices {
+		c.Devices[i].configDir = dir
+	}
+}
 
-	var wg sync.WaitGroup
-	results := make(chan string, len(config.Devices))
+// 去掉明文密码后的配置副本 (password_file / password_cmd 不是秘密, 保留)
+func (c *BatchConfig) withoutPasswords() *BatchConfig {
+	stripped := &BatchConfig{Devices: make([]DeviceConfig, len(c.Devices))}
+	for i, dev := range c.Devices {
+		dev.Password = ""
+		stripped.Devices[i] = dev
+	}
+	return stripped
+}
 
+// 批量获取设备信息, 结果按配置文件中的设备顺序返回
+func BatchGetInfo(ctx context.Context, config *BatchConfig) []batchInfoOutput {
+	statusf("正在获取 %d 个设备的信息...\n\n", len(config.Devices))
+
+	results := make([]batchInfoOutput, len(config.Devices))
+
+	var wg sync.WaitGroup
 	for i, device := range config.Devices {
 		wg.Add(1)
-		go func(idx int, dev DeviceConfig) {
+		go func(result *batchInfoOutput, dev DeviceConfig) {
 			defer wg.Done()
 
-			client, err := NewONVIFClient(dev.Host, dev.Port, dev.Username, dev.Password, false, dev.UseHTTPS)
+			*result = batchInfoOutput{Name: dev.Name, Host: dev.Host, Port: dev.Port}
+
+			client, err := newClientFromDevice(dev)
 			if err != nil {
-				results <- fmt.Sprintf("[%d] %s - 连接失败: %v", idx+1, dev.Name, err)
+				result.Error = fmt.Sprintf("连接失败: %v", err)
 				return
 			}
+			result.Port = client.Port
 
 			// 获取设备信息
-			respData, err := client.sendRequest(client.XAddr, &GetDeviceInformation{})
+			info, err := client.GetDeviceInformation(ctx)
 			if err != nil {
-				results <- fmt.Sprintf("[%d] %s - 获取信息失败: %v", idx+1, dev.Name, err)
+				result.Error = fmt.Sprintf("获取信息失败: %v", err)
 				return
 			}
 
-			var infoResp struct {
-				Body struct {
-					GetDeviceInformationResponse GetDeviceInformationResponse
-				}
-			}
-
-			if err := xml.Unmarshal(respData, &infoResp); err != nil {
-				results <- fmt.Sprintf("[%d] %s - 解析失败: %v", idx+1, dev.Name, err)
-				return
-			}
-
-			info := infoResp.Body.GetDeviceInformationResponse
-			results <- fmt.Sprintf("[%d] %s - %s %s (固件: %s)",
-				idx+1, dev.Name, info.Manufacturer, info.Model, info.FirmwareVersion)
-		}(i, device)
+			result.OK = true
+			result.Manufacturer = info.Manufacturer
+			result.Model = info.Model
+			result.FirmwareVersion = info.FirmwareVersion
+			result.SerialNumber = info.SerialNumber
+		}(&results[i], device)
 	}
 
-	// 等待所有任务完成
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// 打印结果
-	for result := range results {
-		fmt.Println(result)
-	}
-
-	fmt.Println("\n✓ 批量查询完成")
-	return nil
+	wg.Wait()
+	return results
 }
 
-// 批量抓图
-func BatchSnapshot(config *BatchConfig, outputDir string) error {
+// 批量抓图, 结果按配置文件中的设备顺序返回
+func BatchSnapshot(ctx context.Context, config *BatchConfig, outputDir string) ([]batchSnapshotOutput, error) {
 	// 创建输出目录
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("创建输出目录失败: %w", err)
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
 	}
 
-	fmt.Printf("正在从 %d 个设备抓取图像...\n\n", len(config.Devices))
+	statusf("正在从 %d 个设备抓取图像...\n\n", len(config.Devices))
 
-	var wg sync.WaitGroup
-	results := make(chan string, len(config.Devices))
+	results := make([]batchSnapshotOutput, len(config.Devices))
 
+	var wg sync.WaitGroup
 	for i, device := range config.Devices {
 		wg.Add(1)
-		go func(idx int, dev DeviceConfig) {
+		go func(result *batchSnapshotOutput, dev DeviceConfig) {
 			defer wg.Done()
 
-			client, err := NewONVIFClient(dev.Host, dev.Port, dev.Username, dev.Password, false, dev.UseHTTPS)
+			*result = batchSnapshotOutput{Name: dev.Name, Host: dev.Host, Port: dev.Port}
+
+			client, err := newClientFromDevice(dev)
 			if err != nil {
-				results <- fmt.Sprintf("[%d] %s - 连接失败: %v", idx+1, dev.Name, err)
+				result.Error = fmt.Sprintf("连接失败: %v", err)
 				return
 			}
+			result.Port = client.Port
 
 			outputFile := filepath.Join(outputDir, fmt.Sprintf("%s.jpg", dev.Name))
-			if err := client.GetSnapshot(outputFile, 0); err != nil {
-				results <- fmt.Sprintf("[%d] %s - 抓图失败: %v", idx+1, dev.Name, err)
+			_, size, err := saveSnapshot(ctx, client, outputFile, 0)
+			if err != nil {
+				result.Error = fmt.Sprintf("抓图失败: %v", err)
 				return
 			}
 
-			results <- fmt.Sprintf("[%d] %s - ✓ 已保存到 %s", idx+1, dev.Name, outputFile)
-		}(i, device)
+			result.OK = true
+			result.File = outputFile
+			result.Size = size
+		}(&results[i], device)
 	}
 
-	// 等待所有任务完成
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// 打印结果
-	for result := range results {
-		fmt.Println(result)
-	}
-
-	fmt.Printf("\n✓ 批量抓图完成，图像保存在: %s\n", outputDir)
-	return nil
+	wg.Wait()
+	return results, nil
 }
 
-// 批量同步时间
-func BatchSyncTime(config *BatchConfig) error {
-	fmt.Printf("正在同步 %d 个设备的时间...\n\n", len(config.Devices))
+// 批量同步时间, 结果按配置文件中的设备顺序返回
+func BatchSyncTime(ctx context.Context, config *BatchConfig) []batchSyncTimeOutput {
+	statusf("正在同步 %d 个设备的时间...\n\n", len(config.Devices))
 
-	var wg sync.WaitGroup
-	results := make(chan string, len(config.Devices))
+	results := make([]batchSyncTimeOutput, len(config.Devices))
 
+	var wg sync.WaitGroup
 	for i, device := range config.Devices {
 		wg.Add(1)
-		go func(idx int, dev DeviceConfig) {
+		go func(result *batchSyncTimeOutput, dev DeviceConfig) {
 			defer wg.Done()
 
-			client, err := NewONVIFClient(dev.Host, dev.Port, dev.Username, dev.Password, false, dev.UseHTTPS)
+			*result = batchSyncTimeOutput{Name: dev.Name, Host: dev.Host, Port: dev.Port}
+
+			client, err := newClientFromDevice(dev)
 			if err != nil {
-				results <- fmt.Sprintf("[%d] %s - 连接失败: %v", idx+1, dev.Name, err)
+				result.Error = fmt.Sprintf("连接失败: %v", err)
 				return
 			}
+			result.Port = client.Port
 
-			if err := client.SyncSystemTime(); err != nil {
-				results <- fmt.Sprintf("[%d] %s - 同步失败: %v", idx+1, dev.Name, err)
+			now := time.Now()
+			if err := client.SetSystemDateAndTime(ctx, now); err != nil {
+				result.Error = fmt.Sprintf("同步失败: %v", err)
 				return
 			}
 
-			results <- fmt.Sprintf("[%d] %s - ✓ 时间同步成功", idx+1, dev.Name)
-		}(i, device)
-	}
-
-	// 等待所有任务完成
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// 打印结果
-	for result := range results {
-		fmt.Println(result)
+			result.OK = true
+			result.Time = formatTime(now)
+		}(&results[i], device)
 	}
 
-	fmt.Println("\n✓ 批量时间同步完成")
-	return nil
+	wg.Wait()
+	return results
 }
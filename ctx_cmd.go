@@ -0,0 +1,395 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"onvifctl/onvif"
+)
+
+// 用户配置文件: 保存命名的设备上下文和当前上下文
+// devices 与批量配置文件格式相同, 两者可以互换使用
+type UserConfig struct {
+	CurrentContext string `yaml:"current-context,omitempty"`
+	BatchConfig    `yaml:",inline"`
+
+	key []byte // 文件已加密时的主密钥, 保存时重新加密
+}
+
+// 用户配置文件路径
+// 优先使用 ONVIFCTL_CONFIG, 其次为 $XDG_CONFIG_HOME/onvifctl/config.yaml 或 ~/.config/onvifctl/config.yaml
+func userConfigPath() (string, error) {
+	if path := os.Getenv("ONVIFCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("无法确定用户目录: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "onvifctl", "config.yaml"), nil
+}
+
+// 加载用户配置, 文件不存在时返回空配置 (已加密的文件使用主密钥解密)
+func LoadUserConfig() (*UserConfig, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var config UserConfig
+	data, key, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	config.key = key
+	config.setConfigDir(filepath.Dir(path))
+
+	return &config, nil
+}
+
+// 保存用户配置, 原文件已加密时仍加密保存
+func SaveUserConfig(config *UserConfig) error {
+	path, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	return writeConfigFile(path, data, config.key)
+}
+
+// 按名称查找设备上下文, 返回其在列表中的位置
+func (c *UserConfig) find(name string) int {
+	for i, dev := range c.Devices {
+		if dev.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// 获取指定名称的设备上下文
+func (c *UserConfig) device(name string) (DeviceConfig, error) {
+	i := c.find(name)
+	if i < 0 {
+		return DeviceConfig{}, fmt.Errorf("未找到设备上下文: %s (使用 ctx list 查看)", name)
+	}
+	return c.Devices[i], nil
+}
+
+// 根据 --device / 当前上下文和全局参数确定目标设备
+// 未指定 --device 时: 指定了 -H 则只使用命令行参数, 否则使用当前上下文
+// 显式指定的命令行参数总是覆盖上下文中的值
+func deviceFromFlags(cmd *cobra.Command) (DeviceConfig, error) {
+	flags := cmd.Flags()
+
+	name := deviceName
+	if name == "" && !flags.Changed("host") {
+		config, err := LoadUserConfig()
+		if err != nil {
+			return DeviceConfig{}, err
+		}
+		name = config.CurrentContext
+	}
+
+	dev := DeviceConfig{
+		Host:     host,
+		Username: username,
+		UseHTTPS: useHTTPS,
+		Auth:     authMode,
+	}
+
+	if name != "" {
+		config, err := LoadUserConfig()
+		if err != nil {
+			return DeviceConfig{}, err
+		}
+		if dev, err = config.device(name); err != nil {
+			return DeviceConfig{}, err
+		}
+
+		if flags.Changed("host") {
+			dev.Host = host
+		}
+		if flags.Changed("user") {
+			dev.Username = username
+		}
+		if flags.Changed("https") {
+			dev.UseHTTPS = useHTTPS
+			// 上下文中的端口对应原协议, 切换协议时按新协议取默认端口
+			dev.Port = 0
+		}
+		if flags.Changed("auth") {
+			dev.Auth = authMode
+		}
+	}
+
+	if flags.Changed("tls-fingerprint") {
+		dev.TLSFingerprint = tlsFingerprint
+	}
+
+	if flags.Changed("port") {
+		dev.Port = port
+	}
+
+	if dev.Host == "" {
+		return DeviceConfig{}, fmt.Errorf("必须指定设备地址 (-H/--host) 或设备上下文 (--device)")
+	}
+	if flags.Changed("port") && (port < 1 || port > 65535) {
+		return DeviceConfig{}, fmt.Errorf("端口号必须在 1-65535 之间")
+	}
+
+	// 密码优先级: -w / --password-stdin > 设备上下文 > ONVIFCTL_PASSWORD
+	pass, ok, err := passwordFromFlags(cmd)
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	if ok {
+		dev.Password, dev.PasswordFile, dev.PasswordCmd = pass, "", ""
+	} else if !dev.hasPassword() {
+		dev.Password = os.Getenv(envPassword)
+	}
+
+	return dev, nil
+}
+
+func ctxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ctx",
+		Short: "设备上下文管理",
+		Long: `管理保存在用户配置文件中的命名设备 (默认 ~/.config/onvifctl/config.yaml, 可用 ONVIFCTL_CONFIG 指定)
+设置当前上下文后, 其它命令无需再指定 -H/-P/-u/-w/-a/-s, 也可以用 --device 临时选择设备`,
+		Example: `  # 添加设备 (第一个添加的设备自动成为当前上下文), 只保存密码文件路径
+  onvifctl ctx add lobby -H 192.168.1.100 -u admin --password-file ~/.secrets/lobby
+
+  # 切换当前上下文
+  onvifctl ctx use lobby
+
+  # 临时使用其它设备
+  onvifctl info --device gate`,
+	}
+
+	cmd.AddCommand(ctxAddCmd())
+	cmd.AddCommand(ctxUseCmd())
+	cmd.AddCommand(ctxListCmd())
+	cmd.AddCommand(ctxRemoveCmd())
+
+	return cmd
+}
+
+func ctxAddCmd() *cobra.Command {
+	var (
+		use          bool
+		plaintext    bool
+		passwordFile string
+		passwordCmd  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "添加或更新设备上下文 (使用全局参数 -H/-P/-u/-w/-a/-s/--tls-fingerprint)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			flags := cmd.Flags()
+
+			if host == "" {
+				return fmt.Errorf("必须指定设备地址 (-H/--host)")
+			}
+
+			// 未显式指定端口时按协议保存默认端口
+			devicePort := port
+			if useHTTPS && !flags.Changed("port") {
+				devicePort = 443
+			}
+			if devicePort < 1 || devicePort > 65535 {
+				return fmt.Errorf("端口号必须在 1-65535 之间")
+			}
+
+			pass, _, err := passwordFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			if passwordFile != "" {
+				// 保存绝对路径, 避免相对于配置文件目录解析
+				if passwordFile, err = filepath.Abs(passwordFile); err != nil {
+					return err
+				}
+			}
+
+			dev := DeviceConfig{
+				Name:         name,
+				Host:         host,
+				Port:         devicePort,
+				Username:     username,
+				Password:     pass,
+				UseHTTPS:     useHTTPS,
+				PasswordFile: passwordFile,
+				PasswordCmd:  passwordCmd,
+			}
+			if tlsFingerprint != "" {
+				if dev.TLSFingerprint, err = onvif.NormalizeFingerprint(tlsFingerprint); err != nil {
+					return err
+				}
+			}
+			if err := dev.checkPasswordSources(); err != nil {
+				return err
+			}
+			if flags.Changed("auth") {
+				if !onvif.IsValidAuthMode(authMode) {
+					return fmt.Errorf("未知的认证模式: %s", authMode)
+				}
+				dev.Auth = authMode
+			}
+
+			config, err := LoadUserConfig()
+			if err != nil {
+				return err
+			}
+
+			// 配置文件未加密时不写入明文密码, 除非显式指定 --plaintext
+			if dev.Password != "" && config.key == nil && !plaintext {
+				return fmt.Errorf("配置文件未加密, 不会以明文保存密码: 请改用 --password-file 或 --password-cmd, " +
+					"或先用 batch encrypt 加密配置文件; 确需明文保存时指定 --plaintext")
+			}
+
+			updated := false
+			if i := config.find(name); i >= 0 {
+				config.Devices[i] = dev
+				updated = true
+			} else {
+				config.Devices = append(config.Devices, dev)
+			}
+
+			if use || config.CurrentContext == "" {
+				config.CurrentContext = name
+			}
+
+			if err := SaveUserConfig(config); err != nil {
+				return fmt.Errorf("保存配置文件失败: %w", err)
+			}
+
+			if updated {
+				statusf("✓ 已更新设备上下文: %s\n", name)
+			} else {
+				statusf("✓ 已添加设备上下文: %s\n", name)
+			}
+			if config.CurrentContext == name {
+				statusf("  当前上下文: %s\n", name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&use, "use", false, "同时设为当前上下文")
+	cmd.Flags().BoolVar(&plaintext, "plaintext", false, "配置文件未加密时仍以明文保存 -w/--password-stdin 指定的密码")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "从文件读取密码 (保存文件路径而非密码)")
+	cmd.Flags().StringVar(&passwordCmd, "password-cmd", "", "执行命令取得密码 (保存命令而非密码)")
+
+	return cmd
+}
+
+func ctxUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use NAME",
+		Short: "切换当前设备上下文",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadUserConfig()
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.device(args[0]); err != nil {
+				return err
+			}
+
+			config.CurrentContext = args[0]
+			if err := SaveUserConfig(config); err != nil {
+				return fmt.Errorf("保存配置文件失败: %w", err)
+			}
+
+			statusf("✓ 当前上下文: %s\n", args[0])
+
+			return nil
+		},
+	}
+}
+
+func ctxListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出设备上下文",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadUserConfig()
+			if err != nil {
+				return err
+			}
+
+			contexts := newContextOutputs(config)
+			return render(contexts, func() { printContexts(contexts) })
+		},
+	}
+}
+
+func ctxRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "删除设备上下文",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadUserConfig()
+			if err != nil {
+				return err
+			}
+
+			i := config.find(args[0])
+			if i < 0 {
+				return fmt.Errorf("未找到设备上下文: %s (使用 ctx list 查看)", args[0])
+			}
+
+			config.Devices = append(config.Devices[:i], config.Devices[i+1:]...)
+			if config.CurrentContext == args[0] {
+				config.CurrentContext = ""
+			}
+
+			if err := SaveUserConfig(config); err != nil {
+				return fmt.Errorf("保存配置文件失败: %w", err)
+			}
+
+			statusf("✓ 已删除设备上下文: %s\n", args[0])
+
+			return nil
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -14,18 +15,18 @@ import (
 	"onvifctl/discovery" // 根据你的项目路径调整
 )
 
-// DiscoveredDevice 发现的设备信息
+// DiscoveredDevice 发现的设备信息 (同时是 discover 命令的输出结构)
 type DiscoveredDevice struct {
-	IP           string
-	Port         int
-	XAddr        string
-	Manufacturer string
-	Model        string
-	FirmwareVer  string
-	SerialNumber string
-	AuthType     string // ws-security / digest / basic / none / unknown
-	AuthResult   string // 认证结果: success / failed / untested
-	Reachable    bool
+	IP           string `json:"ip" yaml:"ip"`
+	Port         int    `json:"port" yaml:"port"`
+	XAddr        string `json:"xaddr" yaml:"xaddr"`
+	Manufacturer string `json:"manufacturer" yaml:"manufacturer"`
+	Model        string `json:"model" yaml:"model"`
+	FirmwareVer  string `json:"firmware" yaml:"firmware"`
+	SerialNumber string `json:"serial" yaml:"serial"`
+	AuthType     string `json:"authType" yaml:"authType"`     // ws-security / digest / basic / none / unknown / auth-required
+	AuthResult   string `json:"authResult" yaml:"authResult"` // 认证结果: success / failed / failed-all-creds / untested
+	Reachable    bool   `json:"-" yaml:"-"`
 }
 
 func discoverCmd() *cobra.Command {
@@ -91,11 +92,20 @@ func discoverCmd() *cobra.Command {
 			var devices []discovery.ONVIFDevice
 			var err error
 
+			// --json 为旧参数, 等同于 --output json
+			if jsonOutput {
+				outputFormat = outputJSON
+			}
+
+			ctx := cmd.Context()
+
 			dd := discovery.NewDeviceDiscovery()
+			dd.Log = statusOut()
+			dd.Recorder = traceRecorder
 
 			switch mode {
 			case "broadcast":
-				fmt.Println("=== 广播发现模式 ===")
+				statusf("=== 广播发现模式 ===\n")
 				devices, err = dd.DiscoverByBroadcast(interfaceName)
 				if err != nil {
 					return fmt.Errorf("广播发现失败: %w", err)
@@ -105,8 +115,8 @@ func discoverCmd() *cobra.Command {
 				if ipAddress == "" {
 					return fmt.Errorf("必须指定 IP 地址 (--ip)")
 				}
-				fmt.Printf("=== 扫描单个 IP: %s ===\n", ipAddress)
-				devices, err = dd.DiscoverByIPRange(ipAddress, ipAddress, ports, time.Duration(timeout)*time.Second)
+				statusf("=== 扫描单个 IP: %s ===\n", ipAddress)
+				devices, err = dd.DiscoverByIPRange(ctx, ipAddress, ipAddress, ports, time.Duration(timeout)*time.Second)
 				if err != nil {
 					return fmt.Errorf("IP 扫描失败: %w", err)
 				}
@@ -126,8 +136,8 @@ func discoverCmd() *cobra.Command {
 					return fmt.Errorf("必须指定 IP 范围 (--start --end 或 --subnet)")
 				}
 
-				fmt.Printf("=== 扫描网段: %s - %s ===\n", startIP, endIP)
-				devices, err = dd.DiscoverByIPRange(startIP, endIP, ports, time.Duration(timeout)*time.Second)
+				statusf("=== 扫描网段: %s - %s ===\n", startIP, endIP)
+				devices, err = dd.DiscoverByIPRange(ctx, startIP, endIP, ports, time.Duration(timeout)*time.Second)
 				if err != nil {
 					return fmt.Errorf("网段扫描失败: %w", err)
 				}
@@ -137,19 +147,24 @@ func discoverCmd() *cobra.Command {
 			}
 
 			if len(devices) == 0 {
-				fmt.Println("\n未发现任何 ONVIF 设备")
-				return nil
+				statusf("\n未发现任何 ONVIF 设备\n")
+				if !machineOutput() {
+					return nil
+				}
 			}
 
 			// 获取设备详细信息
-			fmt.Printf("\n正在获取设备详细信息...\n")
-			detailedDevices := getDeviceDetails(devices, creds, verbose)
+			detailedDevices := []DiscoveredDevice{}
+			if len(devices) > 0 {
+				statusf("\n正在获取设备详细信息...\n")
+				if detailedDevices, err = getDeviceDetails(ctx, devices, creds, verbose); err != nil {
+					return err
+				}
+			}
 
 			// 显示结果
-			if jsonOutput {
-				printDevicesJSON(detailedDevices)
-			} else {
-				printDevicesTable(detailedDevices)
+			if err := render(detailedDevices, func() { printDevicesTable(detailedDevices) }); err != nil {
+				return err
 			}
 
 			// 保存到文件
@@ -157,7 +172,7 @@ func discoverCmd() *cobra.Command {
 				if err := saveDevicesToFile(detailedDevices, saveFile); err != nil {
 					return fmt.Errorf("保存设备列表失败: %w", err)
 				}
-				fmt.Printf("\n✓ 设备列表已保存到: %s\n", saveFile)
+				statusf("\n✓ 设备列表已保存到: %s\n", saveFile)
 			}
 
 			return nil
@@ -175,6 +190,7 @@ func discoverCmd() *cobra.Command {
 	cmd.Flags().StringArrayVarP(&credentials, "cred", "c", []string{}, "认证凭据 username:password (可多次指定)")
 	cmd.Flags().StringVarP(&saveFile, "save", "o", "", "保存设备列表到文件")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "以 JSON 格式输出")
+	cmd.Flags().MarkDeprecated("json", "请使用 --output json")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "显示详细的认证过程")
 
 	return cmd
@@ -219,13 +235,17 @@ func parseCIDR(cidr string) (string, string, error) {
 	return startIP.String(), endIP.String(), nil
 }
 
-// getDeviceDetails 获取设备详细信息
-func getDeviceDetails(devices []discovery.ONVIFDevice, creds []discovery.Credential, verbose bool) []DiscoveredDevice {
+// getDeviceDetails 获取设备详细信息, ctx 取消时返回 ctx.Err()
+func getDeviceDetails(ctx context.Context, devices []discovery.ONVIFDevice, creds []discovery.Credential, verbose bool) ([]DiscoveredDevice, error) {
 	var result []DiscoveredDevice
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	dim := discovery.NewDeviceInfoManager(creds)
+	dim.Log = statusOut()
+	dim.Recorder = traceRecorder
+	// 无法确定 known_hosts 位置时退回为严格的 CA 校验
+	dim.TLS, _ = tlsOptions("")
 
 	for _, device := range devices {
 		wg.Add(1)
@@ -246,7 +266,7 @@ func getDeviceDetails(devices []discovery.ONVIFDevice, creds []discovery.Credent
 			//}
 
 			// 使用增强的认证方法
-			info, err := dim.GetDeviceInfoEnhanced(dev.XAddr, verbose)
+			info, err := dim.GetDeviceInfoEnhanced(ctx, dev.XAddr, verbose)
 			if err != nil {
 				discovered.AuthType = "unknown"
 				discovered.AuthResult = "failed"
@@ -291,7 +311,10 @@ func getDeviceDetails(devices []discovery.ONVIFDevice, creds []discovery.Credent
 	}
 
 	wg.Wait()
-	return result
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // printDevicesTable 表格形式打印设备列表（居中对齐格式）
@@ -382,7 +405,7 @@ func formatAuthResult(result, authType string) string {
 	case "success":
 		// 成功时显示使用的认证方式
 		switch authType {
-		case "wsse":
+		case "ws-security":
 			return "✓ 成功(WS-Security)"
 		case "digest":
 			return "✓ 成功(Digest)"
@@ -404,29 +427,6 @@ func formatAuthResult(result, authType string) string {
 	}
 }
 
-// printDevicesJSON JSON 格式打印设备列表
-func printDevicesJSON(devices []DiscoveredDevice) {
-	fmt.Println("[")
-	for i, device := range devices {
-		fmt.Printf("  {\n")
-		fmt.Printf("    \"ip\": \"%s\",\n", device.IP)
-		fmt.Printf("    \"port\": %d,\n", device.Port)
-		fmt.Printf("    \"xaddr\": \"%s\",\n", device.XAddr)
-		fmt.Printf("    \"manufacturer\": \"%s\",\n", device.Manufacturer)
-		fmt.Printf("    \"model\": \"%s\",\n", device.Model)
-		fmt.Printf("    \"firmware\": \"%s\",\n", device.FirmwareVer)
-		fmt.Printf("    \"serial\": \"%s\",\n", device.SerialNumber)
-		fmt.Printf("    \"authType\": \"%s\",\n", device.AuthType)
-		fmt.Printf("    \"authResult\": \"%s\"\n", device.AuthResult)
-		if i < len(devices)-1 {
-			fmt.Printf("  },\n")
-		} else {
-			fmt.Printf("  }\n")
-		}
-	}
-	fmt.Println("]")
-}
-
 // saveDevicesToFile 保存设备列表到文件
 func saveDevicesToFile(devices []DiscoveredDevice, filename string) error {
 	file, err := os.Create(filename)
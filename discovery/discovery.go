@@ -1,19 +1,22 @@
 package discovery
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net"
 	"net/http"
 	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/net/ipv4"
+
+	"onvifctl/onvif"
+	"onvifctl/soap"
 )
 
 const bufSize = 8192
@@ -28,6 +31,9 @@ type ONVIFDevice struct {
 
 // DeviceDiscovery 设备发现器
 type DeviceDiscovery struct {
+	Log      io.Writer     // 进度信息输出目标, 默认 os.Stdout
+	Recorder soap.Recorder // 记录探测设备的 HTTP 往返, 为 nil 时不记录
+
 	devices []ONVIFDevice
 }
 
@@ -40,15 +46,19 @@ type IPRange struct {
 // NewDeviceDiscovery 创建设备发现器
 func NewDeviceDiscovery() *DeviceDiscovery {
 	return &DeviceDiscovery{
+		Log:     os.Stdout,
 		devices: make([]ONVIFDevice, 0),
 	}
 }
 
 // DiscoverByBroadcast 通过广播发现设备
 func (dd *DeviceDiscovery) DiscoverByBroadcast(interfaceName string) ([]ONVIFDevice, error) {
-	fmt.Println("开始广播发现ONVIF设备...")
+	fmt.Fprintln(dd.Log, "开始广播发现ONVIF设备...")
 
-	msg := dd.buildProbeMessage()
+	msg, err := dd.buildProbeMessage()
+	if err != nil {
+		return nil, err
+	}
 	responses := dd.sendUDPMulticast(msg, interfaceName)
 
 	// 使用 IP:Port 作为唯一标识去重
@@ -73,13 +83,14 @@ func (dd *DeviceDiscovery) DiscoverByBroadcast(interfaceName string) ([]ONVIFDev
 		dd.devices = append(dd.devices, device)
 	}
 
-	fmt.Printf("广播发现完成,找到 %d 个设备\n", len(dd.devices))
+	fmt.Fprintf(dd.Log, "广播发现完成,找到 %d 个设备\n", len(dd.devices))
 	return dd.devices, nil
 }
 
 // DiscoverByIPRange 通过IP范围扫描发现设备
-func (dd *DeviceDiscovery) DiscoverByIPRange(startIP, endIP string, ports []int, timeout time.Duration) ([]ONVIFDevice, error) {
-	fmt.Printf("开始扫描IP范围: %s - %s\n", startIP, endIP)
+// ctx 取消时不再发起新的探测, 进行中的请求随之中止, 返回 ctx.Err()
+func (dd *DeviceDiscovery) DiscoverByIPRange(ctx context.Context, startIP, endIP string, ports []int, timeout time.Duration) ([]ONVIFDevice, error) {
+	fmt.Fprintf(dd.Log, "开始扫描IP范围: %s - %s\n", startIP, endIP)
 
 	if len(ports) == 0 {
 		ports = []int{80, 8080, 8000, 8899, 9000, 554}
@@ -94,7 +105,7 @@ func (dd *DeviceDiscovery) DiscoverByIPRange(startIP, endIP string, ports []int,
 		return nil, err
 	}
 
-	fmt.Printf("需要扫描 %d 个IP地址, %d 个端口\n", len(ips), len(ports))
+	fmt.Fprintf(dd.Log, "需要扫描 %d 个IP地址, %d 个端口\n", len(ips), len(ports))
 
 	// 使用 IP:Port 作为唯一标识去重
 	deviceMap := make(map[string]ONVIFDevice)
@@ -133,13 +144,17 @@ func (dd *DeviceDiscovery) DiscoverByIPRange(startIP, endIP string, ports []int,
 						progressMu.Lock()
 						completedTasks++
 						if completedTasks%500 == 0 || completedTasks == totalTasks {
-							fmt.Printf("\r扫描进度: %d/%d (%.1f%%)  ", completedTasks, totalTasks, float64(completedTasks)*100/float64(totalTasks))
+							fmt.Fprintf(dd.Log, "\r扫描进度: %d/%d (%.1f%%)  ", completedTasks, totalTasks, float64(completedTasks)*100/float64(totalTasks))
 						}
 						progressMu.Unlock()
 					}()
 
+					if ctx.Err() != nil {
+						return
+					}
+
 					xaddr := fmt.Sprintf("http://%s:%d%s", ip, port, path)
-					if dd.probeDevice(xaddr, timeout) {
+					if dd.probeDevice(ctx, xaddr, timeout) {
 						device := ONVIFDevice{
 							XAddr: xaddr,
 							IP:    ip,
@@ -174,7 +189,10 @@ func (dd *DeviceDiscovery) DiscoverByIPRange(startIP, endIP string, ports []int,
 	}
 
 	// 转换为切片并显示
-	fmt.Println() // 换行,避免覆盖进度条
+	fmt.Fprintln(dd.Log) // 换行,避免覆盖进度条
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	for _, device := range deviceMap {
 		dd.devices = append(dd.devices, device)
 		//fmt.Printf("✓ 发现设备: %s:%d%s\n", device.IP, device.Port, device.Path)
@@ -185,7 +203,7 @@ func (dd *DeviceDiscovery) DiscoverByIPRange(startIP, endIP string, ports []int,
 }
 
 // DiscoverMixed 混合模式发现
-func (dd *DeviceDiscovery) DiscoverMixed(interfaceName string, ipRanges []IPRange, ports []int, timeout time.Duration) ([]ONVIFDevice, error) {
+func (dd *DeviceDiscovery) DiscoverMixed(ctx context.Context, interfaceName string, ipRanges []IPRange, ports []int, timeout time.Duration) ([]ONVIFDevice, error) {
 	//fmt.Println("=== 混合发现模式 ===")
 
 	// 使用 IP:Port 作为唯一标识去重
@@ -207,7 +225,10 @@ func (dd *DeviceDiscovery) DiscoverMixed(interfaceName string, ipRanges []IPRang
 	if len(ipRanges) > 0 {
 		//fmt.Println("\n[阶段2] IP范围扫描...")
 		for _, ipRange := range ipRanges {
-			rangeDevices, err := dd.DiscoverByIPRange(ipRange.StartIP, ipRange.EndIP, ports, timeout)
+			rangeDevices, err := dd.DiscoverByIPRange(ctx, ipRange.StartIP, ipRange.EndIP, ports, timeout)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			if err != nil {
 				//fmt.Printf("IP扫描失败: %v\n", err)
 				continue
@@ -240,8 +261,12 @@ func (dd *DeviceDiscovery) GetDevices() []ONVIFDevice {
 
 // 内部方法
 
-func (dd *DeviceDiscovery) buildProbeMessage() string {
-	uuid := fmt.Sprintf("%d-%d", time.Now().UnixNano(), time.Now().Unix())
+// 构建 WS-Discovery Probe 消息, 每条消息使用新的随机 MessageID
+func (dd *DeviceDiscovery) buildProbeMessage() (string, error) {
+	uuid, err := soap.NewUUID()
+	if err != nil {
+		return "", fmt.Errorf("生成 MessageID 失败: %w", err)
+	}
 
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing">
@@ -258,7 +283,7 @@ func (dd *DeviceDiscovery) buildProbeMessage() string {
 			<d:Types>dn:NetworkVideoTransmitter</d:Types>
 		</Probe>
 	</s:Body>
-</s:Envelope>`, uuid)
+</s:Envelope>`, uuid), nil
 }
 
 func (dd *DeviceDiscovery) sendUDPMulticast(msg string, interfaceName string) []string {
@@ -334,65 +359,54 @@ func (dd *DeviceDiscovery) sendMulticastOnInterface(msg string, iface *net.Inter
 	return result
 }
 
+// WS-Discovery 探测响应 (可能包含多个 ProbeMatch)
+type probeMatches struct {
+	ProbeMatch []struct {
+		XAddrs string `xml:"XAddrs"`
+	} `xml:"ProbeMatch"`
+}
+
+// parseProbeResponse 从探测响应中提取全部设备服务地址
 func (dd *DeviceDiscovery) parseProbeResponse(response string) []string {
 	var xaddrs []string
 
-	re := regexp.MustCompile(`<.*?XAddrs.*?>(.*?)</.*?XAddrs.*?>`)
-	matches := re.FindStringSubmatch(response)
+	var matches probeMatches
+	if err := soap.DecodeBody([]byte(response), &matches); err != nil {
+		return nil
+	}
 
-	if len(matches) > 1 {
-		text := matches[1]
-		urls := strings.Split(text, " ")
-		for _, url := range urls {
-			url = strings.TrimSpace(url)
-			if url != "" {
-				xaddrs = append(xaddrs, url)
-			}
-		}
+	for _, match := range matches.ProbeMatch {
+		xaddrs = append(xaddrs, strings.Fields(match.XAddrs)...)
 	}
 
 	return xaddrs
 }
 
-func (dd *DeviceDiscovery) probeDevice(xaddr string, timeout time.Duration) bool {
-	client := &http.Client{Timeout: timeout}
-
-	soap := `<?xml version="1.0" encoding="UTF-8"?>
-<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl">
-	<s:Body>
-		<tds:GetDeviceInformation/>
-	</s:Body>
-</s:Envelope>`
+// probeDevice 发送未认证的 GetDeviceInformation, 判断地址是否为 ONVIF 设备
+func (dd *DeviceDiscovery) probeDevice(ctx context.Context, xaddr string, timeout time.Duration) bool {
+	client := &soap.Client{HTTPClient: &http.Client{Timeout: timeout}, Recorder: dd.Recorder}
 
-	req, err := http.NewRequest("POST", xaddr, strings.NewReader(soap))
+	statusCode, body, err := client.Send(ctx, xaddr, &onvif.GetDeviceInformation{}, soap.AuthNone)
 	if err != nil {
 		return false
 	}
 
-	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
 	// 401 表示需要认证,也是ONVIF设备
-	if resp.StatusCode == http.StatusUnauthorized {
+	if statusCode == http.StatusUnauthorized {
 		return true
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false
+	// 返回 SOAP Fault (如 NotAuthorized) 同样说明是 SOAP 服务
+	if statusCode != http.StatusOK {
+		return soap.ParseFault(statusCode, body) != nil
 	}
 
-	bodyStr := string(body)
+	var info onvif.DeviceInformation
+	if err := soap.DecodeBody(body, &info); err != nil {
+		return false
+	}
 
-	return strings.Contains(bodyStr, "Envelope") &&
-		(strings.Contains(bodyStr, "onvif") ||
-			strings.Contains(bodyStr, "GetDeviceInformationResponse") ||
-			strings.Contains(bodyStr, "Manufacturer"))
+	return info.Manufacturer != "" || info.Model != ""
 }
 
 func (dd *DeviceDiscovery) generateIPRange(startIP, endIP string) ([]string, error) {
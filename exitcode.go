@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"onvifctl/onvif"
+)
+
+// 错误类别对应的退出码
+const (
+	exitGeneral      = 1   // 其他错误
+	exitAuth         = 3   // 认证失败 (用户名/密码错误或无权限)
+	exitNotSupported = 4   // 设备不支持该操作或服务
+	exitInvalidArgs  = 5   // 参数被设备拒绝
+	exitDeviceFault  = 6   // 设备内部错误或其他 SOAP Fault
+	exitTLS          = 7   // 设备证书未通过校验
+	exitCanceled     = 130 // 用户中断 (Ctrl-C)
+)
+
+// 根据错误类型给出退出码和面向用户的说明 (说明可能为空)
+func classifyError(err error) (int, string) {
+	var fault *onvif.SOAPFault
+	if errors.As(err, &fault) {
+		switch {
+		case fault.HasCode("MessageExpired"):
+			return exitAuth, "设备认为请求已过期, 可能是设备时钟偏差过大, 可先执行 time sync 或检查 NTP 设置"
+		case fault.IsAuth():
+			return exitAuth, "设备拒绝了认证信息, 请检查用户名、密码和认证模式 (-a), 以及该账户的权限"
+		case fault.IsNotSupported():
+			return exitNotSupported, "设备不支持该操作, 可能是固件未实现或设备缺少相应功能"
+		case fault.IsInvalidArgs():
+			return exitInvalidArgs, "设备拒绝了请求参数, 请检查参数取值范围或 Profile/预置位等标识是否存在"
+		default:
+			return exitDeviceFault, "设备返回了错误, 可使用 --debug 查看完整响应"
+		}
+	}
+
+	var httpErr *onvif.HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return exitAuth, "设备拒绝了认证信息, 请检查用户名、密码和认证模式 (-a), 或尝试 -a auto"
+		case http.StatusNotFound:
+			return exitNotSupported, "设备上不存在该服务地址"
+		}
+		return exitGeneral, ""
+	}
+
+	var certErr *onvif.CertificateError
+	if errors.As(err, &certErr) {
+		if certErr.Expected != "" {
+			return exitTLS, "设备证书与记录的指纹不符, 可能遭到中间人攻击; 若设备证书确已更换, 请删除 known_hosts 中该地址所在的行或更新 tls_fingerprint"
+		}
+		return exitTLS, "可用 --ca-file 指定签发设备证书的 CA, 用 --tls-fingerprint 或配置中的 tls_fingerprint 固定证书, 或用 --insecure 跳过校验 (不安全)"
+	}
+
+	var unsupported *onvif.UnsupportedServiceError
+	if errors.As(err, &unsupported) {
+		return exitNotSupported, ""
+	}
+
+	return exitGeneral, ""
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"onvifctl/onvif"
+	"onvifctl/soap"
+)
+
+// 构造带子码链的 SOAP 1.2 Fault 响应并解析
+func parseTestFault(t *testing.T, statusCode int, code string, subcodes ...string) error {
+	t.Helper()
+
+	inner := ""
+	for i := len(subcodes) - 1; i >= 0; i-- {
+		inner = fmt.Sprintf("<env:Subcode><env:Value>%s</env:Value>%s</env:Subcode>", subcodes[i], inner)
+	}
+	body := fmt.Sprintf(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">`+
+		`<env:Body><env:Fault><env:Code><env:Value>%s</env:Value>%s</env:Code>`+
+		`<env:Reason><env:Text xml:lang="en">test</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`, code, inner)
+
+	err := soap.ResponseError(statusCode, []byte(body))
+	if _, ok := err.(*soap.Fault); !ok {
+		t.Fatalf("响应未解析为 SOAP Fault: %v", err)
+	}
+	return err
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  func(t *testing.T) error
+		want int
+	}{
+		{"NotAuthorized", func(t *testing.T) error {
+			return parseTestFault(t, 400, "env:Sender", "ter:NotAuthorized")
+		}, exitAuth},
+		{"MessageExpired (时钟偏差)", func(t *testing.T) error {
+			return parseTestFault(t, 400, "env:Sender", "wsse:MessageExpired")
+		}, exitAuth},
+		{"ActionNotSupported", func(t *testing.T) error {
+			return parseTestFault(t, 500, "env:Receiver", "ter:ActionNotSupported", "ter:NoSuchService")
+		}, exitNotSupported},
+		{"InvalidArgVal 嵌套两层", func(t *testing.T) error {
+			return parseTestFault(t, 400, "env:Sender", "ter:InvalidArgVal", "ter:NoProfile")
+		}, exitInvalidArgs},
+		{"其他 Fault", func(t *testing.T) error {
+			return parseTestFault(t, 500, "env:Receiver", "ter:Action", "ter:TooManyPresets")
+		}, exitDeviceFault},
+		{"HTTP 401", func(t *testing.T) error {
+			return &onvif.HTTPError{StatusCode: http.StatusUnauthorized}
+		}, exitAuth},
+		{"HTTP 404", func(t *testing.T) error {
+			return &onvif.HTTPError{StatusCode: http.StatusNotFound}
+		}, exitNotSupported},
+		{"HTTP 502", func(t *testing.T) error {
+			return &onvif.HTTPError{StatusCode: http.StatusBadGateway}
+		}, exitGeneral},
+		{"证书未通过校验", func(t *testing.T) error {
+			return &onvif.CertificateError{Addr: "192.0.2.1:443", Err: errors.New("unknown authority")}
+		}, exitTLS},
+		{"缺少服务", func(t *testing.T) error {
+			return &onvif.UnsupportedServiceError{Namespace: onvif.NamespacePTZ}
+		}, exitNotSupported},
+		{"其他错误", func(t *testing.T) error {
+			return errors.New("连接被拒绝")
+		}, exitGeneral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 命令返回的错误都经过 fmt.Errorf 包装
+			err := fmt.Errorf("执行失败: %w", tt.err(t))
+
+			got, hint := classifyError(err)
+			if got != tt.want {
+				t.Errorf("退出码 = %d, want %d (说明: %s)", got, tt.want, hint)
+			}
+			var fault *soap.Fault
+			if errors.As(err, &fault) && hint == "" {
+				t.Error("SOAP Fault 应给出说明")
+			}
+		})
+	}
+}
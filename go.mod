@@ -1,10 +1,12 @@
 module onvifctl
 
-go 1.19
+// crypto/pbkdf2 (加密配置文件) 需要 Go 1.24
+go 1.24.0
 
 require (
 	github.com/spf13/cobra v1.10.1
 	golang.org/x/net v0.48.0
+	golang.org/x/term v0.38.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
+)
+
+// imaging 命令及其子命令共用的视频源参数
+var imagingSourceToken string
+
+// 按 --source 选择视频源, 未指定时使用第一个视频源
+func imagingSource(ctx context.Context, client *onvif.Client) (string, error) {
+	if imagingSourceToken != "" {
+		return imagingSourceToken, nil
+	}
+
+	sources, err := client.GetVideoSources(ctx)
+	if err != nil {
+		return "", fmt.Errorf("获取视频源失败: %w", err)
+	}
+	if len(sources) == 0 {
+		return "", fmt.Errorf("设备没有视频源")
+	}
+
+	return sources[0].Token, nil
+}
+
+// 获取图像设置的取值范围, 失败时给出警告并返回 nil
+func imagingOptions(ctx context.Context, client *onvif.Client, source, warning string) (*onvif.ImagingOptions, error) {
+	options, err := client.GetImagingOptions(ctx, source)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  获取图像设置选项失败, %s: %v\n", warning, err)
+		return nil, nil
+	}
+	return options, nil
+}
+
+// 检查并规范化模式参数 (转为大写)
+func imagingMode(flag, value string, allowed ...string) (string, error) {
+	mode := strings.ToUpper(value)
+	for _, a := range allowed {
+		if mode == a {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("--%s 必须是 %s 之一", flag, strings.ToLower(strings.Join(allowed, ", ")))
+}
+
+func imagingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "imaging",
+		Short: "图像设置 (亮度、对比度、曝光、红外滤光片、宽动态、对焦等)",
+		Long:  "通过 ONVIF Imaging 服务查看和修改视频源的图像设置, 修改前按设备声明的取值范围和模式校验",
+	}
+
+	cmd.PersistentFlags().StringVar(&imagingSourceToken, "source", "", "视频源 Token (默认第一个视频源)")
+
+	cmd.AddCommand(imagingGetCmd())
+	cmd.AddCommand(imagingSetCmd())
+	cmd.AddCommand(imagingFocusCmd())
+
+	return cmd
+}
+
+func imagingGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get",
+		Short: "查看图像设置及设备支持的取值范围",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			settings, err := client.GetImagingSettings(ctx, source)
+			if err != nil {
+				return fmt.Errorf("获取图像设置失败: %w", err)
+			}
+
+			options, err := imagingOptions(ctx, client, source, "不显示取值范围")
+			if err != nil {
+				return err
+			}
+
+			out := newImagingOutput(source, settings, options)
+			return render(out, func() { printImaging(out) })
+		},
+	}
+}
+
+func imagingSetCmd() *cobra.Command {
+	var (
+		brightness, saturation, contrast, sharpness float64
+		exposure, irCut                             string
+		wdr, backlight                              string
+		wdrLevel, backlightLevel                    float64
+		persist                                     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "修改图像设置",
+		Long: `修改视频源的图像设置, 只发送指定的项, 其余设置保持不变
+发送前按 GetOptions 返回的取值范围和模式校验, 设备不支持的项会报错`,
+		Example: `  # 调整亮度和对比度
+  onvifctl imaging set --brightness 60 --contrast 55 --device lobby
+
+  # 夜间强制切换到黑白 (红外滤光片移开)
+  onvifctl imaging set --ir-cut off --device lobby
+
+  # 开启宽动态并设置强度
+  onvifctl imaging set --wdr on --wdr-level 70 --device lobby`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+
+			var changes onvif.ImagingSettings
+			levels := []struct {
+				flag  string
+				value *float64
+				dst   **float64
+			}{
+				{"brightness", &brightness, &changes.Brightness},
+				{"saturation", &saturation, &changes.ColorSaturation},
+				{"contrast", &contrast, &changes.Contrast},
+				{"sharpness", &sharpness, &changes.Sharpness},
+			}
+			for _, l := range levels {
+				if flags.Changed(l.flag) {
+					*l.dst = l.value
+				}
+			}
+
+			var err error
+			if flags.Changed("ir-cut") {
+				if changes.IrCutFilter, err = imagingMode("ir-cut", irCut, "ON", "OFF", "AUTO"); err != nil {
+					return err
+				}
+			}
+			if flags.Changed("exposure") {
+				if exposure, err = imagingMode("exposure", exposure, "AUTO", "MANUAL"); err != nil {
+					return err
+				}
+			}
+			if flags.Changed("wdr") {
+				if wdr, err = imagingMode("wdr", wdr, "ON", "OFF"); err != nil {
+					return err
+				}
+			}
+			if flags.Changed("backlight") {
+				if backlight, err = imagingMode("backlight", backlight, "ON", "OFF"); err != nil {
+					return err
+				}
+			}
+
+			changed := false
+			for _, name := range []string{"brightness", "saturation", "contrast", "sharpness", "exposure", "ir-cut", "wdr", "wdr-level", "backlight", "backlight-level"} {
+				changed = changed || flags.Changed(name)
+			}
+			if !changed {
+				return fmt.Errorf("必须至少指定一项设置 (如 --brightness, --ir-cut, --wdr)")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			current, err := client.GetImagingSettings(ctx, source)
+			if err != nil {
+				return fmt.Errorf("获取图像设置失败: %w", err)
+			}
+
+			// 曝光的其它参数 (时间、增益、光圈限制) 与模式相关, 按当前值一起发送
+			if flags.Changed("exposure") {
+				var e onvif.Exposure
+				if current.Exposure != nil {
+					e = *current.Exposure
+				}
+				e.Mode = exposure
+				changes.Exposure = &e
+			}
+
+			// 只指定强度时保持当前模式
+			if flags.Changed("wdr") || flags.Changed("wdr-level") {
+				w := onvif.WideDynamicRange{Mode: "ON"}
+				if current.WideDynamicRange != nil {
+					w.Mode = current.WideDynamicRange.Mode
+				}
+				if flags.Changed("wdr") {
+					w.Mode = wdr
+				}
+				if flags.Changed("wdr-level") {
+					w.Level = &wdrLevel
+				}
+				changes.WideDynamicRange = &w
+			}
+			if flags.Changed("backlight") || flags.Changed("backlight-level") {
+				b := onvif.BacklightCompensation{Mode: "ON"}
+				if current.BacklightCompensation != nil {
+					b.Mode = current.BacklightCompensation.Mode
+				}
+				if flags.Changed("backlight") {
+					b.Mode = backlight
+				}
+				if flags.Changed("backlight-level") {
+					b.Level = &backlightLevel
+				}
+				changes.BacklightCompensation = &b
+			}
+
+			// 无法获取选项时只给出警告, 由设备自行校验
+			options, err := imagingOptions(ctx, client, source, "跳过取值范围校验")
+			if err != nil {
+				return err
+			}
+			if options != nil {
+				if err := options.Validate(changes); err != nil {
+					return err
+				}
+			}
+
+			if err := client.SetImagingSettings(ctx, source, changes, persist); err != nil {
+				return fmt.Errorf("设置图像失败: %w", err)
+			}
+
+			settings, err := client.GetImagingSettings(ctx, source)
+			if err != nil {
+				return fmt.Errorf("获取图像设置失败: %w", err)
+			}
+
+			out := newImagingOutput(source, settings, options)
+			return render(out, func() {
+				fmt.Println("✓ 图像设置已更新")
+				printImaging(out)
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&brightness, "brightness", 0, "亮度")
+	cmd.Flags().Float64Var(&saturation, "saturation", 0, "饱和度")
+	cmd.Flags().Float64Var(&contrast, "contrast", 0, "对比度")
+	cmd.Flags().Float64Var(&sharpness, "sharpness", 0, "锐度")
+	cmd.Flags().StringVar(&exposure, "exposure", "", "曝光模式: auto, manual")
+	cmd.Flags().StringVar(&irCut, "ir-cut", "", "红外滤光片: on (彩色), off (黑白), auto")
+	cmd.Flags().StringVar(&wdr, "wdr", "", "宽动态: on, off")
+	cmd.Flags().Float64Var(&wdrLevel, "wdr-level", 0, "宽动态强度")
+	cmd.Flags().StringVar(&backlight, "backlight", "", "背光补偿: on, off")
+	cmd.Flags().Float64Var(&backlightLevel, "backlight-level", 0, "背光补偿强度")
+	cmd.Flags().BoolVar(&persist, "persist", true, "设备重启后保留设置")
+
+	return cmd
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
+)
+
+// 按设备声明的对焦移动方式和范围检查
+// 无法获取移动选项时只给出警告, 由设备自行校验
+func validateFocusMove(ctx context.Context, client *onvif.Client, source string, move onvif.FocusMove) error {
+	options, err := client.GetMoveOptions(ctx, source)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  获取对焦移动选项失败, 跳过范围校验: %v\n", err)
+		return nil
+	}
+
+	return options.Validate(move)
+}
+
+// 等待对焦停止, 超过 timeout 返回错误
+// settle 为未观察到 MOVING 时至少等待的时间, 见 onvif.FocusWaitOptions
+func waitFocus(ctx context.Context, client *onvif.Client, source string, timeout, settle time.Duration) (*onvif.ImagingStatus, error) {
+	statusf("等待对焦停止...\n")
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	status, err := client.WaitFocusIdle(waitCtx, source, onvif.FocusWaitOptions{Settle: settle})
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("等待对焦停止超时 (%s)", timeout)
+		}
+		return nil, fmt.Errorf("获取对焦状态失败: %w", err)
+	}
+
+	return status, nil
+}
+
+// 打印等待结束后的对焦位置
+func printFocusWaitResult(status *onvif.ImagingStatus) {
+	if fs := status.FocusStatus; fs != nil {
+		fmt.Printf("✓ 对焦已停止: 位置 %g\n", fs.Position)
+	} else {
+		fmt.Println("✓ 对焦已停止")
+	}
+}
+
+// 设置自动对焦模式, 其余对焦参数 (默认速度、远近限制) 按当前值一起发送
+func setAutoFocusMode(ctx context.Context, client *onvif.Client, source, mode string) error {
+	current, err := client.GetImagingSettings(ctx, source)
+	if err != nil {
+		return fmt.Errorf("获取图像设置失败: %w", err)
+	}
+
+	var focus onvif.FocusConfiguration
+	if current.Focus != nil {
+		focus = *current.Focus
+	}
+	focus.AutoFocusMode = mode
+	changes := onvif.ImagingSettings{Focus: &focus}
+
+	options, err := imagingOptions(ctx, client, source, "跳过自动对焦模式校验")
+	if err != nil {
+		return err
+	}
+	if options != nil {
+		if err := options.Validate(changes); err != nil {
+			return err
+		}
+	}
+
+	// 对焦模式是临时操作, 不强制保存
+	if err := client.SetImagingSettings(ctx, source, changes, false); err != nil {
+		return fmt.Errorf("设置对焦模式失败: %w", err)
+	}
+	return nil
+}
+
+func imagingFocusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "focus",
+		Short: "对焦控制 (连续/绝对/相对移动、停止、自动对焦)",
+		Long: `通过 Imaging 服务控制电动镜头对焦, 适用于变焦枪机安装后远程调焦
+对焦方向: 正值向远处, 负值向近处; 位置和速度的取值范围因设备而异, 用 imaging focus options 查看
+部分设备在自动对焦模式下拒绝手动对焦, 需要先执行 imaging focus manual`,
+	}
+
+	cmd.AddCommand(imagingFocusStatusCmd())
+	cmd.AddCommand(imagingFocusOptionsCmd())
+	cmd.AddCommand(imagingFocusMoveCmd())
+	cmd.AddCommand(imagingFocusAbsoluteCmd())
+	cmd.AddCommand(imagingFocusRelativeCmd())
+	cmd.AddCommand(imagingFocusStopCmd())
+	cmd.AddCommand(imagingFocusAutoCmd())
+	cmd.AddCommand(imagingFocusManualCmd())
+
+	return cmd
+}
+
+func imagingFocusStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "查看对焦位置、移动状态和自动对焦模式",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			status, err := client.GetImagingStatus(ctx, source)
+			if err != nil {
+				return fmt.Errorf("获取对焦状态失败: %w", err)
+			}
+
+			// 自动对焦模式来自图像设置, 获取失败时不影响状态输出
+			var mode string
+			if settings, err := client.GetImagingSettings(ctx, source); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Fprintf(os.Stderr, "⚠️  获取图像设置失败, 不显示自动对焦模式: %v\n", err)
+			} else if settings.Focus != nil {
+				mode = settings.Focus.AutoFocusMode
+			}
+
+			out := newFocusStatusOutput(source, status, mode)
+			return render(out, func() { printFocusStatus(out) })
+		},
+	}
+}
+
+func imagingFocusOptionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "options",
+		Short: "查看支持的对焦移动方式及取值范围",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			moveOptions, err := client.GetMoveOptions(ctx, source)
+			if err != nil {
+				return fmt.Errorf("获取对焦移动选项失败: %w", err)
+			}
+
+			options, err := imagingOptions(ctx, client, source, "不显示自动对焦模式")
+			if err != nil {
+				return err
+			}
+
+			out := newFocusOptionsOutput(source, moveOptions, options)
+			return render(out, func() { printFocusOptions(out) })
+		},
+	}
+}
+
+func imagingFocusMoveCmd() *cobra.Command {
+	var (
+		speed    float64
+		duration time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "move",
+		Short: "连续对焦, 持续 --duration 后停止",
+		Example: `  # 向远处对焦 1 秒
+  onvifctl imaging focus move --speed 0.5 --device lobby
+
+  # 向近处慢速对焦, 由 imaging focus stop 停止
+  onvifctl imaging focus move --speed -0.2 --duration 0 --device lobby`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if duration < 0 {
+				return fmt.Errorf("--duration 不能为负数")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			move := onvif.FocusMove{Continuous: &onvif.ContinuousFocus{Speed: speed}}
+			if err := validateFocusMove(ctx, client, source, move); err != nil {
+				return err
+			}
+
+			if err := client.FocusMove(ctx, source, move); err != nil {
+				return fmt.Errorf("对焦移动失败: %w", err)
+			}
+
+			result := focusActionOutput{Action: "move", SourceToken: source, Speed: &speed}
+			if duration > 0 {
+				statusf("对焦中, %s 后停止...\n", duration)
+				sleepContext(ctx, duration)
+
+				// 被中断时也要停止对焦, 使用新的 ctx
+				stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := client.FocusStop(stopCtx, source); err != nil {
+					return fmt.Errorf("停止对焦失败: %w", err)
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				result.Duration = duration.String()
+			}
+
+			return render(result, func() {
+				if duration > 0 {
+					fmt.Printf("✓ 已对焦 %s (速度 %g)\n", duration, speed)
+				} else {
+					fmt.Printf("✓ 连续对焦命令已发送 (速度 %g), 使用 imaging focus stop 停止\n", speed)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 0, "对焦速度, 正值向远处, 负值向近处 (必填)")
+	cmd.Flags().DurationVar(&duration, "duration", time.Second, "持续时间, 之后发送 Stop; 0 表示不自动停止")
+	cmd.MarkFlagRequired("speed")
+
+	return cmd
+}
+
+// 绝对/相对对焦命令共用的参数和流程
+func imagingFocusStepCmd(absolute bool) *cobra.Command {
+	var (
+		value       float64
+		speed       float64
+		wait        bool
+		waitTimeout time.Duration
+	)
+
+	use, short, flag, usage := "relative", "按距离相对对焦", "distance", "对焦距离, 正值向远处, 负值向近处 (必填)"
+	example := `  onvifctl imaging focus relative --distance -0.05 --wait --device lobby`
+	if absolute {
+		use, short, flag, usage = "absolute", "对焦到指定位置", "position", "对焦位置 (必填)"
+		example = `  onvifctl imaging focus absolute --position 0.42 --wait --device lobby`
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   short,
+		Example: example,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			var speedPtr *float64
+			if cmd.Flags().Changed("speed") {
+				speedPtr = &speed
+			}
+
+			result := focusActionOutput{Action: use, SourceToken: source, Speed: speedPtr}
+			var move onvif.FocusMove
+			if absolute {
+				move.Absolute = &onvif.AbsoluteFocus{Position: value, Speed: speedPtr}
+				result.Position = &value
+			} else {
+				move.Relative = &onvif.RelativeFocus{Distance: value, Speed: speedPtr}
+				result.Distance = &value
+			}
+
+			if err := validateFocusMove(ctx, client, source, move); err != nil {
+				return err
+			}
+
+			if err := client.FocusMove(ctx, source, move); err != nil {
+				return fmt.Errorf("对焦移动失败: %w", err)
+			}
+
+			var status *onvif.ImagingStatus
+			if wait {
+				if status, err = waitFocus(ctx, client, source, waitTimeout, 0); err != nil {
+					return err
+				}
+				statusOut := newFocusStatusOutput(source, status, "")
+				result.Status = &statusOut
+			}
+
+			return render(result, func() {
+				if absolute {
+					fmt.Printf("✓ 正在对焦到位置 %g\n", value)
+				} else {
+					fmt.Printf("✓ 正在相对对焦 %g\n", value)
+				}
+				if status != nil {
+					printFocusWaitResult(status)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&value, flag, 0, usage)
+	cmd.Flags().Float64Var(&speed, "speed", 0, "对焦速度 (默认使用设备默认速度)")
+	cmd.Flags().BoolVar(&wait, "wait", false, "等待对焦停止再返回")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "--wait 的最长等待时间")
+	cmd.MarkFlagRequired(flag)
+
+	return cmd
+}
+
+func imagingFocusAbsoluteCmd() *cobra.Command {
+	return imagingFocusStepCmd(true)
+}
+
+func imagingFocusRelativeCmd() *cobra.Command {
+	return imagingFocusStepCmd(false)
+}
+
+func imagingFocusStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "停止对焦移动",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			if err := client.FocusStop(ctx, source); err != nil {
+				return fmt.Errorf("停止对焦失败: %w", err)
+			}
+
+			result := focusActionOutput{Action: "stop", SourceToken: source}
+			return render(result, func() { fmt.Println("✓ 对焦已停止") })
+		},
+	}
+}
+
+func imagingFocusAutoCmd() *cobra.Command {
+	var (
+		once        bool
+		waitTimeout time.Duration
+		startDelay  time.Duration
+		settle      time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "auto",
+		Short: "切换到自动对焦, --once 时对焦完成后切回手动 (一键对焦)",
+		Long: `将自动对焦模式设为 AUTO
+--once 时等待对焦完成后切回 MANUAL, 相当于一键对焦: 镜头对好焦后不再随画面变化重新对焦
+
+部分设备在自动对焦搜索时仍报告 IDLE 或 UNKNOWN, 因此查询到 MOVING 之前
+至少等待 --settle 指定的时间才认为对焦完成`,
+		Example: `  # 安装后一键对焦
+  onvifctl imaging focus auto --once --device lobby`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if startDelay < 0 || settle < 0 {
+				return fmt.Errorf("--start-delay 和 --settle 不能为负数")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			if err := setAutoFocusMode(ctx, client, source, "AUTO"); err != nil {
+				return err
+			}
+
+			result := focusActionOutput{Action: "auto", SourceToken: source}
+			var status *onvif.ImagingStatus
+			if once {
+				// 设备切换模式后可能要稍后才开始对焦并报告 MOVING
+				sleepContext(ctx, startDelay)
+
+				status, err = waitFocus(ctx, client, source, waitTimeout, settle)
+				if err == nil {
+					err = setAutoFocusMode(ctx, client, source, "MANUAL")
+				}
+				if err != nil {
+					return err
+				}
+
+				result.Action = "autoonce"
+				statusOut := newFocusStatusOutput(source, status, "MANUAL")
+				result.Status = &statusOut
+			}
+
+			return render(result, func() {
+				if once {
+					fmt.Println("✓ 一键对焦完成, 已切回手动对焦")
+					printFocusWaitResult(status)
+				} else {
+					fmt.Println("✓ 已切换到自动对焦")
+				}
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&once, "once", false, "对焦完成后切回手动对焦 (一键对焦)")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "--once 等待对焦完成的最长时间")
+	cmd.Flags().DurationVar(&startDelay, "start-delay", time.Second, "--once 切换到自动对焦后开始查询对焦状态前的等待时间")
+	cmd.Flags().DurationVar(&settle, "settle", 3*time.Second, "--once 未查询到 MOVING 时至少等待的时间")
+
+	return cmd
+}
+
+func imagingFocusManualCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "manual",
+		Short: "切换到手动对焦",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			source, err := imagingSource(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			if err := setAutoFocusMode(ctx, client, source, "MANUAL"); err != nil {
+				return err
+			}
+
+			result := focusActionOutput{Action: "manual", SourceToken: source}
+			return render(result, func() { fmt.Println("✓ 已切换到手动对焦") })
+		},
+	}
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
 )
 
 var (
@@ -15,6 +22,19 @@ var (
 	debug    bool
 	authMode string
 	useHTTPS bool
+
+	deviceName string
+	traceFile  string
+
+	caFile         string
+	clientCert     string
+	clientKey      string
+	insecure       bool
+	tlsFingerprint string
+
+	connectTimeout  time.Duration
+	responseTimeout time.Duration
+	requestTimeout  time.Duration
 )
 
 func main() {
@@ -23,32 +43,141 @@ func main() {
 		Short:   "ONVIF Command Line Tool",
 		Long:    "ONVIF 协议命令行工具，用于管理和控制支持 ONVIF 的网络摄像头设备",
 		Version: "1.0.0",
+		// 错误由 main 统一输出 (附带说明和退出码)
+		SilenceErrors: true,
+		// 参数解析通过后再出错时不打印用法
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutputFormat(cmd); err != nil {
+				return err
+			}
+			return openTrace(cmd.Root().Version)
+		},
 	}
 
 	// 全局 flags
 	rootCmd.PersistentFlags().StringVarP(&host, "host", "H", "", "设备 IP 地址或主机名")
 	rootCmd.PersistentFlags().IntVarP(&port, "port", "P", 80, "设备端口号")
 	rootCmd.PersistentFlags().StringVarP(&username, "user", "u", "admin", "ONVIF 登录用户名")
-	rootCmd.PersistentFlags().StringVarP(&password, "pass", "w", "", "ONVIF 登录密码")
+	rootCmd.PersistentFlags().StringVarP(&password, "pass", "w", "", "ONVIF 登录密码 (会出现在进程列表和历史记录中, 建议使用 --password-stdin 或 ONVIFCTL_PASSWORD)")
+	rootCmd.PersistentFlags().BoolVar(&passwordStdin, "password-stdin", false, "从标准输入读取密码 (终端下提示输入且不回显)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "启用调试日志")
-	rootCmd.PersistentFlags().StringVarP(&authMode, "auth", "a", "ws-security", "认证模式: ws-security 或 digest")
-	rootCmd.PersistentFlags().BoolVarP(&useHTTPS, "https", "s", false, "使用 HTTPS 协议")
+	rootCmd.PersistentFlags().StringVarP(&authMode, "auth", "a", "ws-security", "认证模式: ws-security, digest, basic, none 或 auto (自动协商)")
+	rootCmd.PersistentFlags().BoolVarP(&useHTTPS, "https", "s", false, "使用 HTTPS 协议 (未指定端口时使用 443)")
+	rootCmd.PersistentFlags().StringVar(&caFile, "ca-file", "", "用于校验设备证书的 CA 证书 (PEM)")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "客户端证书 (PEM), 用于双向 TLS 认证")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "客户端私钥 (PEM)")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "跳过设备证书校验 (不安全)")
+	rootCmd.PersistentFlags().StringVar(&tlsFingerprint, "tls-fingerprint", "", "只接受该 SHA-256 指纹的设备证书")
+	rootCmd.PersistentFlags().DurationVar(&connectTimeout, "connect-timeout", onvif.DefaultTimeouts.Connect, "连接超时 (含 TLS 握手)")
+	rootCmd.PersistentFlags().DurationVar(&responseTimeout, "response-timeout", onvif.DefaultTimeouts.ResponseHeader, "等待响应头超时")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", onvif.DefaultTimeouts.Overall, "单个请求总超时")
+	rootCmd.PersistentFlags().StringVar(&deviceName, "device", "", "使用配置文件中的设备上下文 (见 ctx 命令)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace", "", "将全部 HTTP 往返 (已脱敏) 写入文件, 扩展名为 .har 时为 HAR 格式, 否则为 JSON Lines")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputTable, "输出格式: table, json, yaml, csv")
 
 	// 添加子命令
 	rootCmd.AddCommand(infoCmd())
 	rootCmd.AddCommand(streamCmd())
 	rootCmd.AddCommand(ptzCmd())
+	rootCmd.AddCommand(imagingCmd())
 	rootCmd.AddCommand(snapshotCmd())
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(discoverCmd()) // 新的增强版 discover 命令
 	rootCmd.AddCommand(timeCmd())
 	rootCmd.AddCommand(eventsCmd())
 	rootCmd.AddCommand(batchCmd())
+	rootCmd.AddCommand(ctxCmd())
+
+	// Ctrl-C / SIGTERM 取消正在进行的请求
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
+	if traceErr := closeTrace(); traceErr != nil {
+		fmt.Fprintf(os.Stderr, "警告: 写入 trace 文件失败: %v\n", traceErr)
+	}
 
-	if err := rootCmd.Execute(); err != nil {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "已取消")
+			os.Exit(exitCanceled)
+		}
+
+		code, hint := classifyError(err)
 		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
-		os.Exit(1)
+		if hint != "" {
+			fmt.Fprintf(os.Stderr, "说明: %s\n", hint)
+		}
+
+		var fault *onvif.SOAPFault
+		if debug && errors.As(err, &fault) && fault.Detail != "" {
+			fmt.Fprintf(os.Stderr, "详情: %s\n", fault.Detail)
+		}
+		os.Exit(code)
+	}
+}
+
+// 根据全局参数生成超时配置
+func timeoutsFromFlags() onvif.Timeouts {
+	return onvif.Timeouts{
+		Connect:        connectTimeout,
+		ResponseHeader: responseTimeout,
+		Overall:        requestTimeout,
+	}
+}
+
+// 根据设备上下文和全局参数创建客户端
+func newClientFromFlags(cmd *cobra.Command) (*onvif.Client, error) {
+	dev, err := deviceFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newClientFromDevice(dev)
+	if err != nil {
+		return nil, fmt.Errorf("连接设备失败: %w", err)
 	}
+
+	return client, nil
+}
+
+// 按索引选择媒体配置, 同时返回全部配置
+func selectProfile(ctx context.Context, client *onvif.Client, index int) (onvif.Profile, []onvif.Profile, error) {
+	profiles, err := client.GetProfiles(ctx)
+	if err != nil {
+		return onvif.Profile{}, nil, fmt.Errorf("获取 profiles 失败: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		return onvif.Profile{}, nil, fmt.Errorf("设备没有可用的 profile")
+	}
+
+	if index < 0 || index >= len(profiles) {
+		return onvif.Profile{}, nil, fmt.Errorf("profile 索引 %d 超出范围 (0-%d)", index, len(profiles)-1)
+	}
+
+	return profiles[index], profiles, nil
+}
+
+// 抓取指定配置的图像并保存到文件, 返回所用配置和图像大小
+func saveSnapshot(ctx context.Context, client *onvif.Client, output string, profileIndex int) (onvif.Profile, int, error) {
+	profile, _, err := selectProfile(ctx, client, profileIndex)
+	if err != nil {
+		return onvif.Profile{}, 0, err
+	}
+
+	data, err := client.GetSnapshot(ctx, profile.Token)
+	if err != nil {
+		return onvif.Profile{}, 0, err
+	}
+
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return onvif.Profile{}, 0, fmt.Errorf("保存图像失败: %w", err)
+	}
+
+	return profile, len(data), nil
 }
 
 func infoCmd() *cobra.Command {
@@ -57,23 +186,23 @@ func infoCmd() *cobra.Command {
 		Short: "获取设备信息",
 		Long:  "获取设备信息（厂商、型号、时间、能力等）",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-			if port < 1 || port > 65535 {
-				return fmt.Errorf("端口号必须在 1-65535 之间")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
-			if authMode != "ws-security" && authMode != "digest" {
-				return fmt.Errorf("认证模式必须是 ws-security 或 digest")
+
+			info, err := client.GetDeviceInformation(cmd.Context())
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			// 时间获取失败时只显示基本信息
+			dt, err := client.GetSystemDateAndTime(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				dt = nil
 			}
-			client.AuthMode = authMode
 
-			return client.GetDeviceInfo()
+			return render(newDeviceInfoOutput(info, dt), func() { printDeviceInfo(info, dt) })
 		},
 	}
 
@@ -88,92 +217,26 @@ func streamCmd() *cobra.Command {
 		Short: "获取 RTSP 流地址",
 		Long:  "获取设备的 RTSP 视频流地址",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-			if port < 1 || port > 65535 {
-				return fmt.Errorf("端口号必须在 1-65535 之间")
-			}
-			if authMode != "ws-security" && authMode != "digest" {
-				return fmt.Errorf("认证模式必须是 ws-security 或 digest")
-			}
-
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			client, err := newClientFromFlags(cmd)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
-
-			return client.GetStreamURI(profile)
-		},
-	}
-
-	cmd.Flags().IntVarP(&profile, "profile", "r", 0, "配置文件索引（0 表示主码流）")
-
-	return cmd
-}
-
-func ptzCmd() *cobra.Command {
-	var (
-		panSpeed  float64
-		tiltSpeed float64
-		zoomSpeed float64
-		timeout   int
-		preset    int
-		action    string
-	)
 
-	cmd := &cobra.Command{
-		Use:   "ptz",
-		Short: "PTZ 云台控制",
-		Long:  "控制摄像头云台移动、缩放、预置位等",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-			if port < 1 || port > 65535 {
-				return fmt.Errorf("端口号必须在 1-65535 之间")
-			}
-			if authMode != "ws-security" && authMode != "digest" {
-				return fmt.Errorf("认证模式必须是 ws-security 或 digest")
+			selected, profiles, err := selectProfile(cmd.Context(), client, profile)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			uri, err := client.GetStreamURI(cmd.Context(), selected.Token)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
-			}
-			client.AuthMode = authMode
-
-			switch action {
-			case "move":
-				return client.PTZMove(panSpeed, tiltSpeed, zoomSpeed, timeout)
-			case "stop":
-				return client.PTZStop()
-			case "goto":
-				if preset == 0 {
-					return fmt.Errorf("必须指定预置位编号 (--preset)")
-				}
-				return client.PTZGotoPreset(preset)
-			case "setpreset":
-				if preset == 0 {
-					return fmt.Errorf("必须指定预置位编号 (--preset)")
-				}
-				return client.PTZSetPreset(preset)
-			case "list":
-				return client.PTZListPresets()
-			default:
-				return fmt.Errorf("未知的操作: %s (支持: move, stop, goto, setpreset, list)", action)
+				return err
 			}
+
+			return render(newStreamOutput(profile, selected, uri, profiles), func() { printStreamURI(selected, uri, profiles) })
 		},
 	}
 
-	cmd.Flags().StringVar(&action, "action", "", "操作类型: move, stop, goto, setpreset, list (必填)")
-	cmd.Flags().Float64Var(&panSpeed, "pan", 0, "水平速度 (-1.0 到 1.0, 负值向左)")
-	cmd.Flags().Float64Var(&tiltSpeed, "tilt", 0, "垂直速度 (-1.0 到 1.0, 负值向下)")
-	cmd.Flags().Float64Var(&zoomSpeed, "zoom", 0, "缩放速度 (-1.0 到 1.0, 负值缩小)")
-	cmd.Flags().IntVar(&timeout, "timeout", 1, "移动持续时间（秒）")
-	cmd.Flags().IntVar(&preset, "preset", 0, "预置位编号")
-	cmd.MarkFlagRequired("action")
+	cmd.Flags().IntVarP(&profile, "profile", "r", 0, "配置文件索引（0 表示主码流）")
 
 	return cmd
 }
@@ -185,31 +248,36 @@ func snapshotCmd() *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "snapshot",
-		Short: "抓取图像",
-		Long:  "从摄像头抓取当前画面并保存为 JPEG 图像",
+		Use:         "snapshot",
+		Short:       "抓取图像",
+		Long:        "从摄像头抓取当前画面并保存为 JPEG 图像",
+		Annotations: map[string]string{renamedOutputFlag: "--file"},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-			if port < 1 || port > 65535 {
-				return fmt.Errorf("端口号必须在 1-65535 之间")
-			}
-			if authMode != "ws-security" && authMode != "digest" {
-				return fmt.Errorf("认证模式必须是 ws-security 或 digest")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			selected, size, err := saveSnapshot(cmd.Context(), client, output, profile)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
-			return client.GetSnapshot(output, profile)
+			result := snapshotOutput{
+				File:         output,
+				ProfileName:  selected.Name,
+				ProfileToken: selected.Token,
+				Size:         size,
+			}
+			return render(result, func() {
+				fmt.Printf("✓ 图像已保存到: %s\n", output)
+				fmt.Printf("  配置: %s\n", selected.Name)
+				fmt.Printf("  大小: %d 字节\n", size)
+			})
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "snapshot.jpg", "输出文件路径")
+	cmd.Flags().StringVarP(&output, "file", "o", "snapshot.jpg", "输出文件路径")
 	cmd.Flags().IntVarP(&profile, "profile", "r", 0, "配置文件索引")
 
 	return cmd
@@ -227,17 +295,17 @@ func configCmd() *cobra.Command {
 		Use:   "get-video",
 		Short: "获取视频编码配置",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			configs, err := client.GetVideoEncoderConfigurations(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
-			return client.GetVideoEncoderConfiguration()
+			return render(newVideoConfigOutputs(configs), func() { printVideoConfigs(configs) })
 		},
 	}
 
@@ -246,22 +314,49 @@ func configCmd() *cobra.Command {
 		Use:   "set-video",
 		Short: "设置视频编码配置",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			client, err := newClientFromFlags(cmd)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
 			width, _ := cmd.Flags().GetInt("width")
 			height, _ := cmd.Flags().GetInt("height")
 			fps, _ := cmd.Flags().GetInt("fps")
 			bitrate, _ := cmd.Flags().GetInt("bitrate")
 
-			return client.SetVideoEncoderConfiguration(width, height, fps, bitrate)
+			// 先获取当前配置, 修改第一个配置
+			configs, err := client.GetVideoEncoderConfigurations(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(configs) == 0 {
+				return fmt.Errorf("没有找到视频编码配置")
+			}
+
+			config := configs[0]
+			if width > 0 {
+				config.Resolution.Width = width
+			}
+			if height > 0 {
+				config.Resolution.Height = height
+			}
+			if fps > 0 {
+				config.RateControl.FrameRateLimit = fps
+			}
+			if bitrate > 0 {
+				config.RateControl.BitrateLimit = bitrate
+			}
+
+			if err := client.SetVideoEncoderConfiguration(cmd.Context(), config, true); err != nil {
+				return fmt.Errorf("设置配置失败: %w", err)
+			}
+
+			return render(newVideoConfigOutput(config), func() {
+				fmt.Println("✓ 视频编码配置已更新")
+				fmt.Printf("  分辨率: %dx%d\n", config.Resolution.Width, config.Resolution.Height)
+				fmt.Printf("  帧率:   %d fps\n", config.RateControl.FrameRateLimit)
+				fmt.Printf("  比特率: %d kbps\n", config.RateControl.BitrateLimit)
+			})
 		},
 	}
 
@@ -275,17 +370,17 @@ func configCmd() *cobra.Command {
 		Use:   "get-network",
 		Short: "获取网络配置",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			interfaces, err := client.GetNetworkInterfaces(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
-			return client.GetNetworkConfiguration()
+			return render(newNetworkInterfaceOutputs(interfaces), func() { printNetworkInterfaces(interfaces) })
 		},
 	}
 
@@ -308,17 +403,17 @@ func timeCmd() *cobra.Command {
 		Use:   "get",
 		Short: "获取设备时间",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			dt, err := client.GetSystemDateAndTime(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
-			return client.GetSystemTime()
+			return render(newSystemTimeOutput(dt), func() { printSystemTime(dt) })
 		},
 	}
 
@@ -327,17 +422,20 @@ func timeCmd() *cobra.Command {
 		Use:   "sync",
 		Short: "同步设备时间到系统时间",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
-			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+			now := time.Now().UTC()
+			if err := client.SetSystemDateAndTime(cmd.Context(), now); err != nil {
+				return fmt.Errorf("同步时间失败: %w", err)
 			}
-			client.AuthMode = authMode
 
-			return client.SyncSystemTime()
+			return render(timeSyncOutput{Time: formatTime(now)}, func() {
+				fmt.Println("✓ 时间同步成功")
+				fmt.Printf("  设备时间已设置为: %s\n", now.Format("2006-01-02 15:04:05 UTC"))
+			})
 		},
 	}
 
@@ -346,22 +444,24 @@ func timeCmd() *cobra.Command {
 		Use:   "set-ntp",
 		Short: "设置 NTP 服务器",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-
 			ntpServer, _ := cmd.Flags().GetString("server")
 			if ntpServer == "" {
 				return fmt.Errorf("必须指定 NTP 服务器地址 (--server)")
 			}
 
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			client, err := newClientFromFlags(cmd)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
+			}
+
+			if err := client.SetNTP(cmd.Context(), ntpServer); err != nil {
+				return fmt.Errorf("设置 NTP 失败: %w", err)
 			}
-			client.AuthMode = authMode
 
-			return client.SetNTP(ntpServer)
+			return render(ntpOutput{NTPServer: ntpServer}, func() {
+				fmt.Println("✓ NTP 服务器设置成功")
+				fmt.Printf("  NTP 服务器: %s\n", ntpServer)
+			})
 		},
 	}
 
@@ -385,17 +485,12 @@ func eventsCmd() *cobra.Command {
 		Short: "事件订阅",
 		Long:  "订阅和监听设备事件（移动侦测、报警等）",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if host == "" {
-				return fmt.Errorf("必须指定设备地址 (-H/--host)")
-			}
-
-			client, err := NewONVIFClient(host, port, username, password, debug, useHTTPS)
+			client, err := newClientFromFlags(cmd)
 			if err != nil {
-				return fmt.Errorf("连接设备失败: %w", err)
+				return err
 			}
-			client.AuthMode = authMode
 
-			return client.SubscribeEvents(duration, filter)
+			return subscribeEvents(cmd.Context(), client, duration, filter)
 		},
 	}
 
@@ -427,12 +522,18 @@ func batchCmd() *cobra.Command {
 				return fmt.Errorf("加载配置文件失败: %w", err)
 			}
 
-			fmt.Printf("✓ 成功加载 %d 个设备配置\n", len(config.Devices))
+			devices := make([]batchDeviceOutput, len(config.Devices))
 			for i, device := range config.Devices {
-				fmt.Printf("  [%d] %s - %s:%d\n", i+1, device.Name, device.Host, device.Port)
+				devices[i] = batchDeviceOutput{
+					Name:     device.Name,
+					Host:     device.Host,
+					Port:     device.Port,
+					UseHTTPS: device.UseHTTPS,
+					Auth:     device.Auth,
+				}
 			}
 
-			return nil
+			return render(devices, func() { printBatchDevices(devices) })
 		},
 	}
 
@@ -444,9 +545,14 @@ func batchCmd() *cobra.Command {
 		Short: "导出设备配置到文件",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configFile, _ := cmd.Flags().GetString("file")
+			encrypt, _ := cmd.Flags().GetBool("encrypt")
+			plaintext, _ := cmd.Flags().GetBool("plaintext")
 			if configFile == "" {
 				return fmt.Errorf("必须指定配置文件 (--file)")
 			}
+			if encrypt && plaintext {
+				return fmt.Errorf("--encrypt 与 --plaintext 不能同时使用")
+			}
 
 			// 创建示例配置
 			config := &BatchConfig{
@@ -470,18 +576,102 @@ func batchCmd() *cobra.Command {
 				},
 			}
 
-			if err := SaveBatchConfig(config, configFile); err != nil {
+			// 默认不写入明文密码: 加密保存, 或显式要求明文, 否则去掉密码
+			var key []byte
+			switch {
+			case encrypt:
+				var err error
+				if key, err = masterKey(true); err != nil {
+					return err
+				}
+			case !plaintext:
+				config = config.withoutPasswords()
+			}
+
+			if err := SaveBatchConfig(config, configFile, key); err != nil {
 				return fmt.Errorf("导出配置失败: %w", err)
 			}
 
-			fmt.Printf("✓ 配置已导出到: %s\n", configFile)
-			fmt.Println("  请编辑该文件以添加你的设备信息")
+			statusf("✓ 配置已导出到: %s\n", configFile)
+			switch {
+			case encrypt:
+				statusf("  已使用主密钥加密, 可用 batch decrypt 解密编辑\n")
+			case !plaintext:
+				statusf("  未写入密码: 请为设备填写 password_file 或 password_cmd, 或使用 --encrypt 加密保存密码\n")
+			}
+			statusf("  请编辑该文件以添加你的设备信息\n")
 
 			return nil
 		},
 	}
 
 	exportCmd.Flags().String("file", "devices.yaml", "配置文件路径")
+	exportCmd.Flags().Bool("encrypt", false, "使用主密钥加密配置文件")
+	exportCmd.Flags().Bool("plaintext", false, "以明文写入密码")
+
+	// 子命令: 加密配置文件
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "使用主密钥加密配置文件 (原地替换)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configFile, _ := cmd.Flags().GetString("file")
+
+			data, err := os.ReadFile(configFile)
+			if err != nil {
+				return fmt.Errorf("读取配置文件失败: %w", err)
+			}
+			if _, ok := parseEncryptedConfig(data); ok {
+				return fmt.Errorf("配置文件已加密: %s", configFile)
+			}
+
+			key, err := masterKey(true)
+			if err != nil {
+				return err
+			}
+
+			if err := writeConfigFile(configFile, data, key); err != nil {
+				return err
+			}
+
+			statusf("✓ 已加密: %s\n", configFile)
+
+			return nil
+		},
+	}
+
+	encryptCmd.Flags().String("file", "devices.yaml", "配置文件路径")
+
+	// 子命令: 解密配置文件
+	decryptCmd := &cobra.Command{
+		Use:   "decrypt",
+		Short: "解密配置文件 (原地替换为明文)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configFile, _ := cmd.Flags().GetString("file")
+
+			data, err := os.ReadFile(configFile)
+			if err != nil {
+				return fmt.Errorf("读取配置文件失败: %w", err)
+			}
+			if _, ok := parseEncryptedConfig(data); !ok {
+				return fmt.Errorf("配置文件未加密: %s", configFile)
+			}
+
+			plain, _, err := readConfigFile(configFile)
+			if err != nil {
+				return err
+			}
+
+			if err := writeConfigFile(configFile, plain, nil); err != nil {
+				return err
+			}
+
+			statusf("✓ 已解密: %s\n", configFile)
+
+			return nil
+		},
+	}
+
+	decryptCmd.Flags().String("file", "devices.yaml", "配置文件路径")
 
 	// 子命令: 批量获取信息
 	infoAllCmd := &cobra.Command{
@@ -498,7 +688,8 @@ func batchCmd() *cobra.Command {
 				return fmt.Errorf("加载配置文件失败: %w", err)
 			}
 
-			return BatchGetInfo(config)
+			results := BatchGetInfo(cmd.Context(), config)
+			return render(results, func() { printBatchInfo(results) })
 		},
 	}
 
@@ -506,11 +697,12 @@ func batchCmd() *cobra.Command {
 
 	// 子命令: 批量抓图
 	snapshotAllCmd := &cobra.Command{
-		Use:   "snapshot",
-		Short: "批量抓取图像",
+		Use:         "snapshot",
+		Short:       "批量抓取图像",
+		Annotations: map[string]string{renamedOutputFlag: "--dir"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configFile, _ := cmd.Flags().GetString("file")
-			outputDir, _ := cmd.Flags().GetString("output")
+			outputDir, _ := cmd.Flags().GetString("dir")
 			if configFile == "" {
 				return fmt.Errorf("必须指定配置文件 (--file)")
 			}
@@ -520,12 +712,17 @@ func batchCmd() *cobra.Command {
 				return fmt.Errorf("加载配置文件失败: %w", err)
 			}
 
-			return BatchSnapshot(config, outputDir)
+			results, err := BatchSnapshot(cmd.Context(), config, outputDir)
+			if err != nil {
+				return err
+			}
+
+			return render(results, func() { printBatchSnapshot(results, outputDir) })
 		},
 	}
 
 	snapshotAllCmd.Flags().String("file", "devices.yaml", "配置文件路径")
-	snapshotAllCmd.Flags().String("output", "snapshots", "输出目录")
+	snapshotAllCmd.Flags().String("dir", "snapshots", "输出目录")
 
 	// 子命令: 批量同步时间
 	syncAllCmd := &cobra.Command{
@@ -542,7 +739,8 @@ func batchCmd() *cobra.Command {
 				return fmt.Errorf("加载配置文件失败: %w", err)
 			}
 
-			return BatchSyncTime(config)
+			results := BatchSyncTime(cmd.Context(), config)
+			return render(results, func() { printBatchSyncTime(results) })
 		},
 	}
 
@@ -550,6 +748,8 @@ func batchCmd() *cobra.Command {
 
 	cmd.AddCommand(importCmd)
 	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(encryptCmd)
+	cmd.AddCommand(decryptCmd)
 	cmd.AddCommand(infoAllCmd)
 	cmd.AddCommand(snapshotAllCmd)
 	cmd.AddCommand(syncAllCmd)
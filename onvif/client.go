@@ -0,0 +1,345 @@
+// Package onvif 实现 ONVIF 设备的 SOAP 客户端 (设备、媒体、PTZ、事件服务)
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"onvifctl/soap"
+)
+
+// Client ONVIF 设备客户端, 可在多个 goroutine 中并发使用
+type Client struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Debug    bool
+	UseHTTPS bool
+	XAddr    string // 设备服务地址
+	AuthMode string // "ws-security"、"digest"、"basic"、"none" 或 "auto"
+	soap     *soap.Client
+	debugOut io.Writer
+
+	authMu sync.Mutex // 保护自动协商过程
+
+	clockMu       sync.Mutex
+	clockOffset   time.Duration // 设备时钟偏差 (设备时间减本地时间)
+	clockMeasured bool
+
+	servicesMu     sync.Mutex
+	services       map[string]ServiceEndpoint // 按命名空间缓存的服务地址
+	advertisedPort string                     // 设备服务通告的端口 (用于 NAT 改写)
+
+	profilesMu sync.Mutex
+	profiles   []Profile // 缓存的媒体配置列表
+}
+
+// Config 客户端配置
+type Config struct {
+	Host     string
+	Port     int    // 为 0 时按协议使用 80 或 443
+	XAddr    string // 设备服务地址, 设置后 Host/Port/UseHTTPS 从中解析 (用于路径非默认的设备)
+	Username string
+	Password string
+	UseHTTPS bool
+	AuthMode string     // 为空时使用 ws-security
+	Timeouts Timeouts   // 为零值时使用 DefaultTimeouts
+	TLS      TLSOptions // HTTPS 证书校验, 零值时按系统 CA 校验
+
+	Debug       bool      // 输出 SOAP 请求/响应等调试信息 (已脱敏)
+	DebugOutput io.Writer // 调试信息输出目标, 默认 os.Stderr
+
+	Recorder soap.Recorder // 记录每次 HTTP 往返 (已脱敏), 用于导出 trace 文件
+}
+
+// AuthModes 支持的认证模式
+var AuthModes = []string{"ws-security", "digest", "basic", "none", "auto"}
+
+// 已协商成功的认证方式缓存 (host:port -> 认证模式)
+var negotiatedAuth sync.Map
+
+// IsValidAuthMode 检查认证模式是否合法
+func IsValidAuthMode(mode string) bool {
+	for _, m := range AuthModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Timeouts 超时配置
+type Timeouts struct {
+	Connect        time.Duration // 建立 TCP 连接 (含 TLS 握手) 的超时
+	ResponseHeader time.Duration // 请求发出后等待响应头的超时
+	Overall        time.Duration // 单个请求的总超时 (含读取响应体)
+}
+
+// DefaultTimeouts 默认超时配置
+var DefaultTimeouts = Timeouts{
+	Connect:        5 * time.Second,
+	ResponseHeader: 15 * time.Second,
+	Overall:        30 * time.Second,
+}
+
+// NewClient 创建 ONVIF 客户端 (不会发起网络请求)
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.XAddr != "" {
+		u, err := url.Parse(cfg.XAddr)
+		if err != nil || u.Hostname() == "" {
+			return nil, fmt.Errorf("无效的设备服务地址: %s", cfg.XAddr)
+		}
+		cfg.Host = u.Hostname()
+		cfg.UseHTTPS = u.Scheme == "https"
+		cfg.Port = 0
+		if p := u.Port(); p != "" {
+			cfg.Port, _ = strconv.Atoi(p)
+		}
+	}
+
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("必须指定设备地址")
+	}
+
+	// 确定协议和端口
+	protocol := "http"
+	if cfg.UseHTTPS {
+		protocol = "https"
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 80
+		if cfg.UseHTTPS {
+			port = 443
+		}
+	}
+	if port < 1 || port > 65535 {
+		return nil, fmt.Errorf("端口号必须在 1-65535 之间")
+	}
+
+	authMode := cfg.AuthMode
+	if authMode == "" {
+		authMode = "ws-security" // 默认使用 WS-Security
+	}
+	if !IsValidAuthMode(authMode) {
+		return nil, fmt.Errorf("认证模式必须是 %s 之一", strings.Join(AuthModes, ", "))
+	}
+
+	timeouts := cfg.Timeouts
+	if timeouts == (Timeouts{}) {
+		timeouts = DefaultTimeouts
+	}
+
+	debugOut := cfg.DebugOutput
+	if debugOut == nil {
+		debugOut = os.Stderr
+	}
+
+	// 构建带端口的地址
+	hostWithPort := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	// 所有请求 (SOAP 与抓图下载) 共用同一个 Transport
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeouts.Connect,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   timeouts.Connect,
+		ResponseHeaderTimeout: timeouts.ResponseHeader,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	if err := configureTLS(transport, cfg.TLS); err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{
+		Transport: transport,
+		Timeout:   timeouts.Overall,
+	}
+
+	client := &Client{
+		Host:     cfg.Host,
+		Port:     port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		Debug:    cfg.Debug,
+		UseHTTPS: cfg.UseHTTPS,
+		XAddr:    fmt.Sprintf("%s://%s/onvif/device_service", protocol, hostWithPort),
+		AuthMode: authMode,
+		debugOut: debugOut,
+	}
+	if cfg.XAddr != "" {
+		client.XAddr = cfg.XAddr
+	}
+
+	// Created 使用设备时间, 避免设备时钟偏差导致认证失败
+	client.soap = &soap.Client{
+		HTTPClient: httpClient,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		Now:        client.deviceNow,
+		Debugf:     client.debugf,
+		Recorder:   cfg.Recorder,
+	}
+
+	client.debugf("连接到设备: %s\n", hostWithPort)
+	client.debugf("协议: %s\n", protocol)
+	client.debugf("设备服务地址: %s\n", client.XAddr)
+	client.debugf("认证模式: %s\n", client.AuthMode)
+	client.debugf("超时设置: 连接 %s, 响应头 %s, 总计 %s\n", timeouts.Connect, timeouts.ResponseHeader, timeouts.Overall)
+	if cfg.UseHTTPS && cfg.TLS.Insecure {
+		client.debugf("⚠️  HTTPS 已启用 (跳过证书验证)\n")
+	}
+
+	return client, nil
+}
+
+// 输出调试信息 (仅 Debug 模式), 地址中的凭据会被替换
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.Debug {
+		fmt.Fprint(c.debugOut, soap.RedactText(fmt.Sprintf(format, args...)))
+	}
+}
+
+// 发送 SOAP 请求
+func (c *Client) sendRequest(ctx context.Context, url string, request interface{}) ([]byte, error) {
+	if err := c.negotiateAuth(ctx); err != nil {
+		return nil, err
+	}
+
+	statusCode, body, err := c.send(ctx, url, request, c.AuthMode)
+	if err != nil {
+		return nil, err
+	}
+
+	// 时间相关的认证失败: 重新测量设备时钟后重试一次
+	if statusCode != http.StatusOK && c.AuthMode == "ws-security" &&
+		c.refreshClockOffset(ctx, soap.ResponseError(statusCode, body)) {
+		c.debugf("认证失败, 已按设备时钟重新生成认证信息并重试\n")
+
+		statusCode, body, err = c.send(ctx, url, request, c.AuthMode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, soap.ResponseError(statusCode, body)
+	}
+
+	return body, nil
+}
+
+// 发送 SOAP 请求并将响应 Body 中的第一个元素解码到 response (为 nil 时忽略响应内容)
+func (c *Client) call(ctx context.Context, url string, request, response interface{}) error {
+	data, err := c.sendRequest(ctx, url, request)
+	if err != nil {
+		return err
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return soap.DecodeBody(data, response)
+}
+
+// XSDDuration 将时长格式化为 xs:duration (如 PT1.5S)
+func XSDDuration(d time.Duration) string {
+	return "PT" + strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+}
+
+// 匹配不含年月的 xs:duration (如 PT1H2M3.5S、P1DT2H)
+var xsdDurationRe = regexp.MustCompile(`^(-)?P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseXSDDuration 解析 xs:duration (不支持年和月)
+func ParseXSDDuration(s string) (time.Duration, error) {
+	m := xsdDurationRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("无效的时长: %s", s)
+	}
+
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[i+2], 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长: %s", s)
+		}
+		d += time.Duration(v * float64(unit))
+	}
+
+	if m[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// 使用指定认证模式发送 SOAP 请求,返回状态码和响应体
+func (c *Client) send(ctx context.Context, url string, request interface{}, mode string) (int, []byte, error) {
+	// WS-Security 令牌的 Created 依赖设备时钟偏差, 首次请求前先测量
+	if mode == soap.AuthWSSecurity {
+		c.ensureClockOffset(ctx)
+	}
+
+	return c.soap.Send(ctx, url, request, mode)
+}
+
+// 自动协商认证方式 (仅 auto 模式)
+// 依次尝试各认证方式调用 GetDeviceInformation, 成功的方式按主机缓存供后续请求使用
+func (c *Client) negotiateAuth(ctx context.Context) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.AuthMode != "auto" {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", c.Host, c.Port)
+	if mode, ok := negotiatedAuth.Load(key); ok {
+		c.AuthMode = mode.(string)
+		return nil
+	}
+
+	candidates := []string{"ws-security", "digest", "basic", "none"}
+	if c.Username == "" && c.Password == "" {
+		candidates = []string{"none", "ws-security", "digest", "basic"}
+	}
+
+	for _, mode := range candidates {
+		c.debugf("尝试认证方式: %s\n", mode)
+
+		statusCode, body, err := c.send(ctx, c.XAddr, &GetDeviceInformation{}, mode)
+		if err != nil {
+			return fmt.Errorf("认证协商失败: %w", err)
+		}
+
+		if statusCode == http.StatusOK && bytes.Contains(body, []byte("GetDeviceInformationResponse")) {
+			c.debugf("✓ 自动选择认证方式: %s\n", mode)
+			negotiatedAuth.Store(key, mode)
+			c.AuthMode = mode
+			return nil
+		}
+	}
+
+	return fmt.Errorf("认证协商失败: 设备拒绝了所有认证方式 (ws-security, digest, basic, none)")
+}
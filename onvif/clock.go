@@ -0,0 +1,131 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"onvifctl/soap"
+)
+
+// 已测量的设备时钟偏差缓存 (host:port -> 设备时间减本地时间)
+var clockOffsets sync.Map
+
+// 偏差变化小于该值时认为重试无意义
+const clockRetryThreshold = 2 * time.Second
+
+// 返回按设备时钟校正后的当前时间 (用于 WS-Security Created)
+func (c *Client) deviceNow() time.Time {
+	c.clockMu.Lock()
+	offset := c.clockOffset
+	c.clockMu.Unlock()
+
+	return time.Now().Add(offset).UTC()
+}
+
+// 确保已测量设备时钟偏差, 每个主机只测量一次
+// 测量失败时按零偏差处理, 不影响正常请求
+func (c *Client) ensureClockOffset(ctx context.Context) {
+	c.clockMu.Lock()
+	defer c.clockMu.Unlock()
+
+	if c.clockMeasured {
+		return
+	}
+	c.clockMeasured = true
+
+	if offset, ok := clockOffsets.Load(c.clockKey()); ok {
+		c.clockOffset = offset.(time.Duration)
+		return
+	}
+
+	offset, err := c.measureClockOffset(ctx)
+	if err != nil {
+		c.debugf("获取设备时间失败, 按本地时间生成认证信息: %v\n", err)
+		return
+	}
+
+	c.clockOffset = offset
+	clockOffsets.Store(c.clockKey(), offset)
+}
+
+// 认证失败后重新测量时钟偏差, 返回是否值得重试
+// 仅在设备明确报告消息过期, 或偏差与之前相比明显变化时重试
+func (c *Client) refreshClockOffset(ctx context.Context, reqErr error) bool {
+	if !IsAuthError(reqErr) {
+		return false
+	}
+
+	c.clockMu.Lock()
+	defer c.clockMu.Unlock()
+
+	offset, err := c.measureClockOffset(ctx)
+	if err != nil {
+		return false
+	}
+
+	changed := (offset - c.clockOffset).Abs() >= clockRetryThreshold
+	c.clockOffset = offset
+	c.clockMeasured = true
+	clockOffsets.Store(c.clockKey(), offset)
+
+	var fault *SOAPFault
+	expired := errors.As(reqErr, &fault) && fault.HasCode("MessageExpired")
+
+	return changed || expired
+}
+
+// 设置时钟偏差 (设备时间被同步后调用)
+func (c *Client) setClockOffset(offset time.Duration) {
+	c.clockMu.Lock()
+	defer c.clockMu.Unlock()
+
+	c.clockOffset = offset
+	c.clockMeasured = true
+	clockOffsets.Store(c.clockKey(), offset)
+}
+
+// 通过未认证的 GetSystemDateAndTime 测量设备时钟偏差
+func (c *Client) measureClockOffset(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	statusCode, body, err := c.send(ctx, c.XAddr, &GetSystemDateAndTime{}, "none")
+	if err != nil {
+		return 0, err
+	}
+	if statusCode != http.StatusOK {
+		return 0, soap.ResponseError(statusCode, body)
+	}
+
+	rtt := time.Since(start)
+
+	var timeResp GetSystemDateAndTimeResponse
+	if err := soap.DecodeBody(body, &timeResp); err != nil {
+		return 0, fmt.Errorf("解析时间失败: %w", err)
+	}
+
+	deviceTime := timeResp.SystemDateAndTime.UTC()
+	if deviceTime.IsZero() {
+		return 0, fmt.Errorf("设备未返回 UTC 时间")
+	}
+
+	// 设备时间只精确到秒, 取该秒的中点; 本地时间取往返的中点
+	offset := deviceTime.Add(500 * time.Millisecond).Sub(start.Add(rtt / 2))
+
+	// 1 秒以内的偏差在精度范围内, 忽略
+	if offset.Abs() < time.Second {
+		offset = 0
+	}
+
+	c.debugf("设备时钟偏差: %s\n", offset.Round(time.Second))
+
+	return offset, nil
+}
+
+// 时钟偏差缓存的键
+func (c *Client) clockKey() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
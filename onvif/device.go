@@ -0,0 +1,82 @@
+package onvif
+
+import (
+	"context"
+	"time"
+)
+
+// GetDeviceInformation 获取设备基本信息 (厂商、型号、固件版本等)
+func (c *Client) GetDeviceInformation(ctx context.Context) (*DeviceInformation, error) {
+	var info DeviceInformation
+	if err := c.call(ctx, c.XAddr, &GetDeviceInformation{}, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetSystemDateAndTime 获取设备时间设置
+func (c *Client) GetSystemDateAndTime(ctx context.Context) (*SystemDateAndTime, error) {
+	var resp GetSystemDateAndTimeResponse
+	if err := c.call(ctx, c.XAddr, &GetSystemDateAndTime{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.SystemDateAndTime, nil
+}
+
+// SetSystemDateAndTime 将设备设置为手动时间模式并写入指定的 UTC 时间
+func (c *Client) SetSystemDateAndTime(ctx context.Context, t time.Time) error {
+	t = t.UTC()
+
+	setTimeReq := SetSystemDateAndTime{
+		DateTimeType:    "Manual",
+		DaylightSavings: false,
+		UTCDateTime: &UTCDateTime{
+			Time: Time{
+				Hour:   t.Hour(),
+				Minute: t.Minute(),
+				Second: t.Second(),
+			},
+			Date: Date{
+				Year:  t.Year(),
+				Month: int(t.Month()),
+				Day:   t.Day(),
+			},
+		},
+	}
+
+	if err := c.call(ctx, c.XAddr, &setTimeReq, nil); err != nil {
+		return err
+	}
+
+	// 设备时间已与本地一致, 后续认证不再需要偏差校正
+	c.setClockOffset(t.Sub(time.Now()).Round(time.Second))
+
+	return nil
+}
+
+// SetNTP 设置设备的 NTP 服务器 (IPv4 地址)
+func (c *Client) SetNTP(ctx context.Context, ntpServer string) error {
+	setNTPReq := SetNTP{
+		FromDHCP: false,
+		NTPManual: []NetworkHost{
+			{
+				Type:        "IPv4",
+				IPv4Address: ntpServer,
+			},
+		},
+	}
+
+	return c.call(ctx, c.XAddr, &setNTPReq, nil)
+}
+
+// GetNetworkInterfaces 获取设备网络接口配置
+func (c *Client) GetNetworkInterfaces(ctx context.Context) ([]NetworkInterface, error) {
+	var resp GetNetworkInterfacesResponse
+	if err := c.call(ctx, c.XAddr, &GetNetworkInterfaces{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.NetworkInterfaces, nil
+}
@@ -0,0 +1,27 @@
+package onvif
+
+import (
+	"fmt"
+
+	"onvifctl/soap"
+)
+
+// SOAPFault 设备返回的 SOAP Fault, 可通过 errors.As 从包装后的错误中取出
+type SOAPFault = soap.Fault
+
+// HTTPError 没有 SOAP Fault 的非 200 响应
+type HTTPError = soap.HTTPError
+
+// UnsupportedServiceError 设备未提供所需的 ONVIF 服务
+type UnsupportedServiceError struct {
+	Namespace string
+}
+
+func (e *UnsupportedServiceError) Error() string {
+	return fmt.Sprintf("设备不支持 %s 服务", serviceNames[e.Namespace])
+}
+
+// IsAuthError 判断错误是否由认证失败引起 (SOAP Fault 或 HTTP 401/403)
+func IsAuthError(err error) bool {
+	return soap.IsAuthError(err)
+}
@@ -0,0 +1,80 @@
+package onvif
+
+import (
+	"context"
+	"time"
+)
+
+// Subscription 事件服务的 PullPoint 订阅
+type Subscription struct {
+	Address         string // 订阅地址 (已按 NAT 规则改写)
+	CurrentTime     string
+	TerminationTime string
+
+	client *Client
+}
+
+// CreatePullPointSubscription 创建 PullPoint 订阅, filter 为主题过滤表达式 (留空订阅全部事件)
+func (c *Client) CreatePullPointSubscription(ctx context.Context, filter string, termination time.Duration) (*Subscription, error) {
+	eventAddr, err := c.serviceAddr(ctx, NamespaceEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribeReq := CreatePullPointSubscription{
+		InitialTerminationTime: XSDDuration(termination),
+	}
+
+	if filter != "" {
+		subscribeReq.Filter = &Filter{
+			TopicExpression: filter,
+		}
+	}
+
+	var resp CreatePullPointSubscriptionResponse
+	if err := c.call(ctx, eventAddr, &subscribeReq, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Subscription{
+		Address:         c.rewriteXAddr(resp.SubscriptionReference.Address, c.advertisedDevicePort()),
+		CurrentTime:     resp.CurrentTime,
+		TerminationTime: resp.TerminationTime,
+		client:          c,
+	}, nil
+}
+
+// PullMessages 拉取事件消息, 设备最多等待 timeout 后返回
+func (s *Subscription) PullMessages(ctx context.Context, timeout time.Duration, limit int) ([]NotificationMessage, error) {
+	pullReq := PullMessages{
+		Timeout:      XSDDuration(timeout),
+		MessageLimit: limit,
+	}
+
+	var resp PullMessagesResponse
+	if err := s.client.call(ctx, s.Address, &pullReq, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.NotificationMessage, nil
+}
+
+// Renew 续订, 将终止时间延长 termination
+func (s *Subscription) Renew(ctx context.Context, termination time.Duration) error {
+	renewReq := Renew{
+		TerminationTime: XSDDuration(termination),
+	}
+
+	var resp RenewResponse
+	if err := s.client.call(ctx, s.Address, &renewReq, &resp); err != nil {
+		return err
+	}
+
+	s.TerminationTime = resp.TerminationTime
+	return nil
+}
+
+// Unsubscribe 取消订阅
+func (s *Subscription) Unsubscribe(ctx context.Context) error {
+	return s.client.call(ctx, s.Address, &Unsubscribe{}, nil)
+}
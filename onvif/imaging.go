@@ -0,0 +1,284 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetImagingSettings 获取视频源的图像设置
+func (c *Client) GetImagingSettings(ctx context.Context, sourceToken string) (*ImagingSettings, error) {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetImagingSettingsResponse
+	if err := c.call(ctx, imagingAddr, &GetImagingSettings{VideoSourceToken: sourceToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.ImagingSettings, nil
+}
+
+// SetImagingSettings 写入视频源的图像设置 (只修改 settings 中设置了的项)
+// persist 为 true 时设备重启后仍保留
+func (c *Client) SetImagingSettings(ctx context.Context, sourceToken string, settings ImagingSettings, persist bool) error {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return err
+	}
+
+	setReq := SetImagingSettings{
+		VideoSourceToken: sourceToken,
+		ImagingSettings:  settings,
+		ForcePersistence: persist,
+	}
+
+	return c.call(ctx, imagingAddr, &setReq, nil)
+}
+
+// GetImagingOptions 获取视频源图像设置的取值范围和支持的模式 (Imaging 服务的 GetOptions)
+func (c *Client) GetImagingOptions(ctx context.Context, sourceToken string) (*ImagingOptions, error) {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetImagingOptionsResponse
+	if err := c.call(ctx, imagingAddr, &GetImagingOptions{VideoSourceToken: sourceToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.ImagingOptions, nil
+}
+
+// Validate 检查图像设置是否在设备声明的范围内且使用支持的模式
+// 设备未声明取值范围或模式的项视为不支持
+func (o *ImagingOptions) Validate(s ImagingSettings) error {
+	levels := []struct {
+		name  string
+		value *float64
+		r     *FloatRange
+	}{
+		{"亮度", s.Brightness, o.Brightness},
+		{"饱和度", s.ColorSaturation, o.ColorSaturation},
+		{"对比度", s.Contrast, o.Contrast},
+		{"锐度", s.Sharpness, o.Sharpness},
+	}
+	for _, l := range levels {
+		if err := validateLevel(l.name, l.value, l.r); err != nil {
+			return err
+		}
+	}
+
+	if s.IrCutFilter != "" {
+		if err := validateMode("红外滤光片", s.IrCutFilter, o.IrCutFilterModes); err != nil {
+			return err
+		}
+	}
+
+	if blc := s.BacklightCompensation; blc != nil {
+		if o.BacklightCompensation == nil {
+			return fmt.Errorf("设备不支持设置背光补偿")
+		}
+		if err := validateMode("背光补偿", blc.Mode, o.BacklightCompensation.Mode); err != nil {
+			return err
+		}
+		if err := validateLevel("背光补偿强度", blc.Level, o.BacklightCompensation.Level); err != nil {
+			return err
+		}
+	}
+
+	if wdr := s.WideDynamicRange; wdr != nil {
+		if o.WideDynamicRange == nil {
+			return fmt.Errorf("设备不支持设置宽动态")
+		}
+		if err := validateMode("宽动态", wdr.Mode, o.WideDynamicRange.Mode); err != nil {
+			return err
+		}
+		if err := validateLevel("宽动态强度", wdr.Level, o.WideDynamicRange.Level); err != nil {
+			return err
+		}
+	}
+
+	if e := s.Exposure; e != nil {
+		if o.Exposure == nil {
+			return fmt.Errorf("设备不支持设置曝光")
+		}
+		if err := validateMode("曝光模式", e.Mode, o.Exposure.Mode); err != nil {
+			return err
+		}
+	}
+
+	if f := s.Focus; f != nil {
+		if o.Focus == nil {
+			return fmt.Errorf("设备不支持设置对焦")
+		}
+		if err := validateMode("自动对焦模式", f.AutoFocusMode, o.Focus.AutoFocusModes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 检查数值是否在范围内, 范围为 nil 表示设备不支持该项
+func validateLevel(name string, v *float64, r *FloatRange) error {
+	if v == nil {
+		return nil
+	}
+	if r == nil {
+		return fmt.Errorf("设备不支持设置%s", name)
+	}
+	if !r.Contains(*v) {
+		return rangeError(name, *v, *r)
+	}
+	return nil
+}
+
+// 检查模式是否在设备支持的模式中
+func validateMode(name, mode string, modes []string) error {
+	if len(modes) == 0 {
+		return fmt.Errorf("设备不支持设置%s", name)
+	}
+	for _, m := range modes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s不支持 %s (支持: %s)", name, mode, strings.Join(modes, ", "))
+}
+
+// FocusMove 移动对焦 (绝对、相对或连续)
+func (c *Client) FocusMove(ctx context.Context, sourceToken string, move FocusMove) error {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return err
+	}
+
+	moveReq := ImagingMove{
+		VideoSourceToken: sourceToken,
+		Focus:            move,
+	}
+
+	return c.call(ctx, imagingAddr, &moveReq, nil)
+}
+
+// FocusStop 停止对焦移动
+func (c *Client) FocusStop(ctx context.Context, sourceToken string) error {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return err
+	}
+
+	return c.call(ctx, imagingAddr, &ImagingStop{VideoSourceToken: sourceToken}, nil)
+}
+
+// GetImagingStatus 获取视频源的对焦位置和移动状态 (Imaging 服务的 GetStatus)
+func (c *Client) GetImagingStatus(ctx context.Context, sourceToken string) (*ImagingStatus, error) {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetImagingStatusResponse
+	if err := c.call(ctx, imagingAddr, &GetImagingStatus{VideoSourceToken: sourceToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Status, nil
+}
+
+// GetMoveOptions 获取视频源支持的对焦移动方式及取值范围
+func (c *Client) GetMoveOptions(ctx context.Context, sourceToken string) (*MoveOptions, error) {
+	imagingAddr, err := c.serviceAddr(ctx, NamespaceImaging)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetMoveOptionsResponse
+	if err := c.call(ctx, imagingAddr, &GetMoveOptions{VideoSourceToken: sourceToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.MoveOptions, nil
+}
+
+// Validate 检查对焦移动是否为设备支持的方式且在范围内
+func (o *MoveOptions) Validate(move FocusMove) error {
+	switch {
+	case move.Absolute != nil:
+		if o.Absolute == nil {
+			return fmt.Errorf("设备不支持绝对对焦")
+		}
+		if !o.Absolute.Position.Contains(move.Absolute.Position) {
+			return rangeError("对焦位置", move.Absolute.Position, o.Absolute.Position)
+		}
+		return validateLevel("对焦速度", move.Absolute.Speed, o.Absolute.Speed)
+	case move.Relative != nil:
+		if o.Relative == nil {
+			return fmt.Errorf("设备不支持相对对焦")
+		}
+		if !o.Relative.Distance.Contains(move.Relative.Distance) {
+			return rangeError("对焦距离", move.Relative.Distance, o.Relative.Distance)
+		}
+		return validateLevel("对焦速度", move.Relative.Speed, o.Relative.Speed)
+	case move.Continuous != nil:
+		if o.Continuous == nil {
+			return fmt.Errorf("设备不支持连续对焦")
+		}
+		if !o.Continuous.Speed.Contains(move.Continuous.Speed) {
+			return rangeError("对焦速度", move.Continuous.Speed, o.Continuous.Speed)
+		}
+	}
+
+	return nil
+}
+
+// Moving 判断对焦是否仍在移动
+func (s *ImagingStatus) Moving() bool {
+	return s.FocusStatus != nil && strings.EqualFold(s.FocusStatus.MoveStatus, "MOVING")
+}
+
+// FocusWaitOptions WaitFocusIdle 的选项
+type FocusWaitOptions struct {
+	// 未见到 MOVING 时至少等待的时间 (从开始查询算起), 见到 MOVING 后停止即认为完成
+	// 部分设备在自动对焦搜索时仍报告 IDLE 或 UNKNOWN; 为 0 时不限制
+	Settle   time.Duration
+	Interval time.Duration // 查询间隔, 为 0 时使用 250ms
+}
+
+// WaitFocusIdle 轮询 GetStatus 直到对焦停止移动, 返回最后一次查询的状态
+// 超时或取消由 ctx 控制
+func (c *Client) WaitFocusIdle(ctx context.Context, sourceToken string, opts FocusWaitOptions) (*ImagingStatus, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	settled := time.Now().Add(opts.Settle)
+	idle, seenMoving := 0, false
+	for {
+		status, err := c.GetImagingStatus(ctx, sourceToken)
+		if err != nil {
+			return nil, err
+		}
+
+		if status.Moving() {
+			idle, seenMoving = 0, true
+		} else if idle++; idle >= idlePolls && (seenMoving || !time.Now().Before(settled)) {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
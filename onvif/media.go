@@ -0,0 +1,161 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// GetProfiles 获取媒体配置 (Profile) 列表, 结果在客户端生命周期内缓存
+// 返回缓存的副本, 调用方排序或修改列表不影响之后的调用
+func (c *Client) GetProfiles(ctx context.Context) ([]Profile, error) {
+	c.profilesMu.Lock()
+	defer c.profilesMu.Unlock()
+
+	if c.profiles != nil {
+		return slices.Clone(c.profiles), nil
+	}
+
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetProfilesResponse
+	if err := c.call(ctx, mediaAddr, &GetProfiles{}, &resp); err != nil {
+		return nil, err
+	}
+
+	c.profiles = resp.Profiles
+	if c.profiles == nil {
+		c.profiles = []Profile{}
+	}
+
+	return slices.Clone(c.profiles), nil
+}
+
+// GetStreamURI 获取指定 Profile 的 RTSP 单播流地址
+func (c *Client) GetStreamURI(ctx context.Context, profileToken string) (*StreamURI, error) {
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	streamReq := GetStreamUri{
+		StreamSetup: StreamSetup{
+			Stream: "RTP-Unicast",
+			Transport: Transport{
+				Protocol: "RTSP",
+			},
+		},
+		ProfileToken: profileToken,
+	}
+
+	var resp GetStreamUriResponse
+	if err := c.call(ctx, mediaAddr, &streamReq, &resp); err != nil {
+		return nil, err
+	}
+
+	return &StreamURI{
+		ProfileToken: profileToken,
+		Protocol:     streamReq.StreamSetup.Transport.Protocol,
+		URI:          resp.MediaUri.Uri,
+	}, nil
+}
+
+// GetSnapshotURI 获取指定 Profile 的抓图地址 (已按 NAT 规则改写)
+func (c *Client) GetSnapshotURI(ctx context.Context, profileToken string) (string, error) {
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return "", err
+	}
+
+	var resp GetSnapshotUriResponse
+	if err := c.call(ctx, mediaAddr, &GetSnapshotUri{ProfileToken: profileToken}, &resp); err != nil {
+		return "", err
+	}
+
+	// 设备位于 NAT 之后时抓图地址通常是内网地址, 按服务地址同样的规则改写
+	return c.rewriteXAddr(resp.MediaUri.Uri, c.advertisedDevicePort()), nil
+}
+
+// DownloadSnapshot 下载抓图地址对应的图像数据
+func (c *Client) DownloadSnapshot(ctx context.Context, snapshotURL string) ([]byte, error) {
+	// 抓图地址通常由 HTTP Digest/Basic 保护, 与 SOAP 认证方式无关
+	downloadMode := "digest"
+	if c.AuthMode == "basic" || c.AuthMode == "none" {
+		downloadMode = c.AuthMode
+	}
+
+	statusCode, data, err := c.soap.Do(ctx, "GET", snapshotURL, "", nil, downloadMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: statusCode}
+	}
+
+	return data, nil
+}
+
+// GetSnapshot 获取指定 Profile 的抓图地址并下载图像
+func (c *Client) GetSnapshot(ctx context.Context, profileToken string) ([]byte, error) {
+	snapshotURL, err := c.GetSnapshotURI(ctx, profileToken)
+	if err != nil {
+		return nil, fmt.Errorf("获取抓图 URI 失败: %w", err)
+	}
+
+	data, err := c.DownloadSnapshot(ctx, snapshotURL)
+	if err != nil {
+		return nil, fmt.Errorf("下载图像失败: %w", err)
+	}
+
+	return data, nil
+}
+
+// GetVideoSources 获取视频源列表
+func (c *Client) GetVideoSources(ctx context.Context) ([]VideoSource, error) {
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetVideoSourcesResponse
+	if err := c.call(ctx, mediaAddr, &GetVideoSources{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.VideoSources, nil
+}
+
+// GetVideoEncoderConfigurations 获取全部视频编码配置
+func (c *Client) GetVideoEncoderConfigurations(ctx context.Context) ([]VideoEncoderConfiguration, error) {
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetVideoEncoderConfigurationsResponse
+	if err := c.call(ctx, mediaAddr, &GetVideoEncoderConfigurations{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Configurations, nil
+}
+
+// SetVideoEncoderConfiguration 写入视频编码配置, persist 为 true 时设备重启后仍保留
+func (c *Client) SetVideoEncoderConfiguration(ctx context.Context, config VideoEncoderConfiguration, persist bool) error {
+	mediaAddr, err := c.serviceAddr(ctx, NamespaceMedia)
+	if err != nil {
+		return err
+	}
+
+	setReq := SetVideoEncoderConfiguration{
+		Configuration:    config,
+		ForcePersistence: persist,
+	}
+
+	return c.call(ctx, mediaAddr, &setReq, nil)
+}
@@ -0,0 +1,935 @@
+package onvif
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// 设备信息请求/响应
+type GetDeviceInformation struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetDeviceInformation"`
+}
+
+// DeviceInformation 设备基本信息 (GetDeviceInformationResponse)
+type DeviceInformation struct {
+	Manufacturer    string `xml:"Manufacturer"`
+	Model           string `xml:"Model"`
+	FirmwareVersion string `xml:"FirmwareVersion"`
+	SerialNumber    string `xml:"SerialNumber"`
+	HardwareId      string `xml:"HardwareId"`
+}
+
+// 系统时间
+type GetSystemDateAndTime struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetSystemDateAndTime"`
+}
+
+type GetSystemDateAndTimeResponse struct {
+	SystemDateAndTime SystemDateAndTime `xml:"SystemDateAndTime"`
+}
+
+// SystemDateAndTime 设备时间设置
+type SystemDateAndTime struct {
+	DateTimeType string      `xml:"DateTimeType"`
+	UTCDateTime  UTCDateTime `xml:"UTCDateTime"`
+}
+
+// UTC 返回设备的 UTC 时间, 设备未返回 UTC 时间时为零值
+func (dt SystemDateAndTime) UTC() time.Time {
+	if dt.UTCDateTime.Date.Year == 0 {
+		return time.Time{}
+	}
+
+	return time.Date(
+		dt.UTCDateTime.Date.Year,
+		time.Month(dt.UTCDateTime.Date.Month),
+		dt.UTCDateTime.Date.Day,
+		dt.UTCDateTime.Time.Hour,
+		dt.UTCDateTime.Time.Minute,
+		dt.UTCDateTime.Time.Second,
+		0, time.UTC,
+	)
+}
+
+type UTCDateTime struct {
+	Time Time `xml:"Time"`
+	Date Date `xml:"Date"`
+}
+
+type Time struct {
+	Hour   int `xml:"Hour"`
+	Minute int `xml:"Minute"`
+	Second int `xml:"Second"`
+}
+
+type Date struct {
+	Year  int `xml:"Year"`
+	Month int `xml:"Month"`
+	Day   int `xml:"Day"`
+}
+
+// Media 配置
+type GetProfiles struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetProfiles"`
+}
+
+type GetProfilesResponse struct {
+	Profiles []Profile `xml:"Profiles"`
+}
+
+type Profile struct {
+	Token            string            `xml:"token,attr"`
+	Name             string            `xml:"Name"`
+	PTZConfiguration *PTZConfiguration `xml:"PTZConfiguration"` // 未绑定 PTZ 配置时为 nil
+}
+
+// PTZConfiguration PTZ 配置 (Profile 绑定的或 GetConfigurations 返回的)
+type PTZConfiguration struct {
+	Token     string `xml:"token,attr"`
+	Name      string `xml:"Name"`
+	UseCount  int    `xml:"UseCount"`
+	NodeToken string `xml:"NodeToken"`
+
+	// 各类移动未指定坐标空间时使用的默认坐标空间
+	DefaultAbsolutePanTiltPositionSpace    string `xml:"DefaultAbsolutePantTiltPositionSpace"` // 标准中的元素名拼写如此
+	DefaultAbsoluteZoomPositionSpace       string `xml:"DefaultAbsoluteZoomPositionSpace"`
+	DefaultRelativePanTiltTranslationSpace string `xml:"DefaultRelativePanTiltTranslationSpace"`
+	DefaultRelativeZoomTranslationSpace    string `xml:"DefaultRelativeZoomTranslationSpace"`
+	DefaultContinuousPanTiltVelocitySpace  string `xml:"DefaultContinuousPanTiltVelocitySpace"`
+	DefaultContinuousZoomVelocitySpace     string `xml:"DefaultContinuousZoomVelocitySpace"`
+
+	DefaultPTZSpeed   *PTZVector     `xml:"DefaultPTZSpeed"`
+	DefaultPTZTimeout string         `xml:"DefaultPTZTimeout"` // 连续移动的默认超时 (xs:duration)
+	PanTiltLimits     *PanTiltLimits `xml:"PanTiltLimits"`     // 水平/垂直限位, 未设置时为 nil
+	ZoomLimits        *ZoomLimits    `xml:"ZoomLimits"`        // 缩放限位, 未设置时为 nil
+}
+
+type PanTiltLimits struct {
+	Range Space2DDescription `xml:"Range"`
+}
+
+type ZoomLimits struct {
+	Range Space1DDescription `xml:"Range"`
+}
+
+// 流 URI
+type GetStreamUri struct {
+	XMLName      xml.Name    `xml:"http://www.onvif.org/ver10/media/wsdl GetStreamUri"`
+	StreamSetup  StreamSetup `xml:"StreamSetup"`
+	ProfileToken string      `xml:"ProfileToken"`
+}
+
+type StreamSetup struct {
+	Stream    string    `xml:"Stream"`
+	Transport Transport `xml:"Transport"`
+}
+
+type Transport struct {
+	Protocol string `xml:"Protocol"`
+}
+
+type GetStreamUriResponse struct {
+	MediaUri MediaUri `xml:"MediaUri"`
+}
+
+type MediaUri struct {
+	Uri                 string `xml:"Uri"`
+	InvalidAfterConnect bool   `xml:"InvalidAfterConnect"`
+	InvalidAfterReboot  bool   `xml:"InvalidAfterReboot"`
+	Timeout             string `xml:"Timeout"`
+}
+
+// StreamURI 流地址查询结果
+type StreamURI struct {
+	ProfileToken string
+	Protocol     string // RTSP、UDP、HTTP
+	URI          string
+}
+
+// PTZ 相关结构
+type ContinuousMove struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl ContinuousMove"`
+	ProfileToken string   `xml:"ProfileToken"`
+	Velocity     PTZSpeed `xml:"Velocity"`
+	Timeout      string   `xml:"Timeout,omitempty"`
+}
+
+type PTZSpeed struct {
+	PanTilt PanTilt `xml:"PanTilt"`
+	Zoom    Zoom    `xml:"Zoom"`
+}
+
+type PanTilt struct {
+	X     float64 `xml:"x,attr"`
+	Y     float64 `xml:"y,attr"`
+	Space string  `xml:"space,attr,omitempty"`
+}
+
+type Zoom struct {
+	X     float64 `xml:"x,attr"`
+	Space string  `xml:"space,attr,omitempty"`
+}
+
+type Stop struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl Stop"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PanTilt      bool     `xml:"PanTilt,omitempty"`
+	Zoom         bool     `xml:"Zoom,omitempty"`
+}
+
+type GotoPreset struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GotoPreset"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PresetToken  string   `xml:"PresetToken"`
+}
+
+type SetPreset struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl SetPreset"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PresetName   string   `xml:"PresetName,omitempty"`
+	PresetToken  string   `xml:"PresetToken,omitempty"` // 设置时覆盖该预置位, 为空时新建
+}
+
+type SetPresetResponse struct {
+	PresetToken string `xml:"PresetToken"`
+}
+
+type RemovePreset struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl RemovePreset"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PresetToken  string   `xml:"PresetToken"`
+}
+
+type GetPresets struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetPresets"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+type GetPresetsResponse struct {
+	Presets []PTZPreset `xml:"Preset"`
+}
+
+type PTZPreset struct {
+	Token    string       `xml:"token,attr"`
+	Name     string       `xml:"Name"`
+	Position *PTZPosition `xml:"PTZPosition"` // 设备未返回位置时为 nil
+}
+
+type PTZPosition struct {
+	PanTilt PanTilt `xml:"PanTilt"`
+	Zoom    Zoom    `xml:"Zoom"`
+}
+
+type GetStatus struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetStatus"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+type GetStatusResponse struct {
+	PTZStatus PTZStatus `xml:"PTZStatus"`
+}
+
+// PTZStatus 云台当前位置和移动状态
+type PTZStatus struct {
+	Position   *PTZPosition  `xml:"Position"` // 设备未返回位置时为 nil
+	MoveStatus PTZMoveStatus `xml:"MoveStatus"`
+	Error      string        `xml:"Error"`
+	UtcTime    string        `xml:"UtcTime"`
+}
+
+// PTZMoveStatus 各轴的移动状态: IDLE、MOVING 或 UNKNOWN
+type PTZMoveStatus struct {
+	PanTilt string `xml:"PanTilt"`
+	Zoom    string `xml:"Zoom"`
+}
+
+// PTZVector 绝对/相对移动的目标位置或位移, 以及移动速度
+// 未设置的轴不会出现在请求中 (设备保持该轴不动或使用默认速度)
+type PTZVector struct {
+	PanTilt *PanTilt `xml:"PanTilt,omitempty"`
+	Zoom    *Zoom    `xml:"Zoom,omitempty"`
+}
+
+type AbsoluteMove struct {
+	XMLName      xml.Name   `xml:"http://www.onvif.org/ver20/ptz/wsdl AbsoluteMove"`
+	ProfileToken string     `xml:"ProfileToken"`
+	Position     PTZVector  `xml:"Position"`
+	Speed        *PTZVector `xml:"Speed,omitempty"`
+}
+
+type RelativeMove struct {
+	XMLName      xml.Name   `xml:"http://www.onvif.org/ver20/ptz/wsdl RelativeMove"`
+	ProfileToken string     `xml:"ProfileToken"`
+	Translation  PTZVector  `xml:"Translation"`
+	Speed        *PTZVector `xml:"Speed,omitempty"`
+}
+
+type GetConfigurationOptions struct {
+	XMLName            xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetConfigurationOptions"`
+	ConfigurationToken string   `xml:"ConfigurationToken"`
+}
+
+type GetConfigurationOptionsResponse struct {
+	PTZConfigurationOptions PTZConfigurationOptions `xml:"PTZConfigurationOptions"`
+}
+
+// PTZConfigurationOptions PTZ 配置支持的坐标空间及取值范围
+type PTZConfigurationOptions struct {
+	Spaces     PTZSpaces     `xml:"Spaces"`
+	PTZTimeout DurationRange `xml:"PTZTimeout"`
+}
+
+// PTZSpaces 设备支持的各类坐标空间
+type PTZSpaces struct {
+	AbsolutePanTiltPositionSpace    []Space2DDescription `xml:"AbsolutePanTiltPositionSpace"`
+	AbsoluteZoomPositionSpace       []Space1DDescription `xml:"AbsoluteZoomPositionSpace"`
+	RelativePanTiltTranslationSpace []Space2DDescription `xml:"RelativePanTiltTranslationSpace"`
+	RelativeZoomTranslationSpace    []Space1DDescription `xml:"RelativeZoomTranslationSpace"`
+	ContinuousPanTiltVelocitySpace  []Space2DDescription `xml:"ContinuousPanTiltVelocitySpace"`
+	ContinuousZoomVelocitySpace     []Space1DDescription `xml:"ContinuousZoomVelocitySpace"`
+	PanTiltSpeedSpace               []Space1DDescription `xml:"PanTiltSpeedSpace"`
+	ZoomSpeedSpace                  []Space1DDescription `xml:"ZoomSpeedSpace"`
+}
+
+// Space2DDescription 二维坐标空间 (水平/垂直)
+type Space2DDescription struct {
+	URI    string     `xml:"URI"`
+	XRange FloatRange `xml:"XRange"`
+	YRange FloatRange `xml:"YRange"`
+}
+
+// Space1DDescription 一维坐标空间 (缩放或速度)
+type Space1DDescription struct {
+	URI    string     `xml:"URI"`
+	XRange FloatRange `xml:"XRange"`
+}
+
+type FloatRange struct {
+	Min float64 `xml:"Min"`
+	Max float64 `xml:"Max"`
+}
+
+// Contains 判断取值是否在范围内
+func (r FloatRange) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+type DurationRange struct {
+	Min string `xml:"Min"`
+	Max string `xml:"Max"`
+}
+
+type GotoHomePosition struct {
+	XMLName      xml.Name   `xml:"http://www.onvif.org/ver20/ptz/wsdl GotoHomePosition"`
+	ProfileToken string     `xml:"ProfileToken"`
+	Speed        *PTZVector `xml:"Speed,omitempty"`
+}
+
+type SetHomePosition struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl SetHomePosition"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+// 预置位巡航 (ONVIF PTZ 2.x)
+type GetPresetTours struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetPresetTours"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+type GetPresetToursResponse struct {
+	PresetTours []PresetTour `xml:"PresetTour"`
+}
+
+// PresetTour 预置位巡航: 依次转到各巡航点并停留
+type PresetTour struct {
+	Token             string                         `xml:"token,attr,omitempty"`
+	Name              string                         `xml:"Name,omitempty"`
+	Status            PTZPresetTourStatus            `xml:"Status"`
+	AutoStart         bool                           `xml:"AutoStart"`
+	StartingCondition PTZPresetTourStartingCondition `xml:"StartingCondition"`
+	TourSpots         []PTZPresetTourSpot            `xml:"TourSpot"`
+}
+
+// PTZPresetTourStatus 巡航状态: Idle、Touring、Paused 或 Extended
+type PTZPresetTourStatus struct {
+	State string `xml:"State"`
+}
+
+// PTZPresetTourStartingCondition 巡航的循环方式
+type PTZPresetTourStartingCondition struct {
+	RandomPresetOrder bool   `xml:"RandomPresetOrder,attr,omitempty"`
+	RecurringTime     int    `xml:"RecurringTime,omitempty"`     // 循环次数
+	RecurringDuration string `xml:"RecurringDuration,omitempty"` // 循环总时长 (xs:duration)
+	Direction         string `xml:"Direction,omitempty"`         // Forward 或 Backward
+}
+
+// PTZPresetTourSpot 巡航点: 目标预置位、移动速度和停留时间
+type PTZPresetTourSpot struct {
+	PresetDetail PTZPresetTourPresetDetail `xml:"PresetDetail"`
+	Speed        *PTZVector                `xml:"Speed,omitempty"`
+	StayTime     string                    `xml:"StayTime,omitempty"` // xs:duration
+}
+
+// PTZPresetTourPresetDetail 巡航点的目标, 三者只设置其一
+type PTZPresetTourPresetDetail struct {
+	PresetToken string     `xml:"PresetToken,omitempty"`
+	Home        bool       `xml:"Home,omitempty"`
+	PTZPosition *PTZVector `xml:"PTZPosition,omitempty"`
+}
+
+type CreatePresetTour struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl CreatePresetTour"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+type CreatePresetTourResponse struct {
+	PresetTourToken string `xml:"PresetTourToken"`
+}
+
+type ModifyPresetTour struct {
+	XMLName      xml.Name   `xml:"http://www.onvif.org/ver20/ptz/wsdl ModifyPresetTour"`
+	ProfileToken string     `xml:"ProfileToken"`
+	PresetTour   PresetTour `xml:"PresetTour"`
+}
+
+type OperatePresetTour struct {
+	XMLName         xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl OperatePresetTour"`
+	ProfileToken    string   `xml:"ProfileToken"`
+	PresetTourToken string   `xml:"PresetTourToken"`
+	Operation       string   `xml:"Operation"` // Start、Stop 或 Pause
+}
+
+type RemovePresetTour struct {
+	XMLName         xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl RemovePresetTour"`
+	ProfileToken    string   `xml:"ProfileToken"`
+	PresetTourToken string   `xml:"PresetTourToken"`
+}
+
+type GetNodes struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetNodes"`
+}
+
+type GetNodesResponse struct {
+	PTZNodes []PTZNode `xml:"PTZNode"`
+}
+
+type GetNode struct {
+	XMLName   xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetNode"`
+	NodeToken string   `xml:"NodeToken"`
+}
+
+type GetNodeResponse struct {
+	PTZNode PTZNode `xml:"PTZNode"`
+}
+
+// PTZNode 云台节点: 一个物理云台支持的坐标空间、预置位数量、辅助命令等能力
+type PTZNode struct {
+	Token                  string            `xml:"token,attr"`
+	FixedHomePosition      *bool             `xml:"FixedHomePosition,attr"` // 为 true 时原点不可修改, 设备未声明时为 nil
+	GeoMove                bool              `xml:"GeoMove,attr"`
+	Name                   string            `xml:"Name"`
+	SupportedPTZSpaces     PTZSpaces         `xml:"SupportedPTZSpaces"`
+	MaximumNumberOfPresets int               `xml:"MaximumNumberOfPresets"`
+	HomeSupported          bool              `xml:"HomeSupported"`
+	AuxiliaryCommands      []string          `xml:"AuxiliaryCommands"` // 如 tt:Wiper|On、tt:IRLamp|Auto
+	Extension              *PTZNodeExtension `xml:"Extension"`
+}
+
+type PTZNodeExtension struct {
+	SupportedPresetTour *PTZPresetTourSupported `xml:"SupportedPresetTour"`
+}
+
+// PTZPresetTourSupported 节点支持的预置位巡航数量和操作
+type PTZPresetTourSupported struct {
+	MaximumNumberOfPresetTours int      `xml:"MaximumNumberOfPresetTours"`
+	PTZPresetTourOperation     []string `xml:"PTZPresetTourOperation"`
+}
+
+type GetConfigurations struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetConfigurations"`
+}
+
+type GetConfigurationsResponse struct {
+	PTZConfigurations []PTZConfiguration `xml:"PTZConfiguration"`
+}
+
+type SendAuxiliaryCommand struct {
+	XMLName       xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl SendAuxiliaryCommand"`
+	ProfileToken  string   `xml:"ProfileToken"`
+	AuxiliaryData string   `xml:"AuxiliaryData"`
+}
+
+type SendAuxiliaryCommandResponse struct {
+	AuxiliaryResponse string `xml:"AuxiliaryResponse"`
+}
+
+// 抓图相关
+type GetSnapshotUri struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetSnapshotUri"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+type GetSnapshotUriResponse struct {
+	MediaUri MediaUri `xml:"MediaUri"`
+}
+
+// 视频编码配置
+type GetVideoEncoderConfigurations struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetVideoEncoderConfigurations"`
+}
+
+type GetVideoEncoderConfigurationsResponse struct {
+	Configurations []VideoEncoderConfiguration `xml:"Configurations"`
+}
+
+type VideoEncoderConfiguration struct {
+	Token       string      `xml:"token,attr"`
+	Name        string      `xml:"Name"`
+	Encoding    string      `xml:"Encoding"`
+	Resolution  Resolution  `xml:"Resolution"`
+	Quality     float64     `xml:"Quality"`
+	RateControl RateControl `xml:"RateControl"`
+}
+
+type Resolution struct {
+	Width  int `xml:"Width"`
+	Height int `xml:"Height"`
+}
+
+type RateControl struct {
+	FrameRateLimit   int `xml:"FrameRateLimit"`
+	EncodingInterval int `xml:"EncodingInterval"`
+	BitrateLimit     int `xml:"BitrateLimit"`
+}
+
+type SetVideoEncoderConfiguration struct {
+	XMLName          xml.Name                  `xml:"http://www.onvif.org/ver10/media/wsdl SetVideoEncoderConfiguration"`
+	Configuration    VideoEncoderConfiguration `xml:"Configuration"`
+	ForcePersistence bool                      `xml:"ForcePersistence"`
+}
+
+// 视频源
+type GetVideoSources struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetVideoSources"`
+}
+
+type GetVideoSourcesResponse struct {
+	VideoSources []VideoSource `xml:"VideoSources"`
+}
+
+// VideoSource 视频源 (图像传感器), 图像设置按视频源 Token 读写
+type VideoSource struct {
+	Token      string     `xml:"token,attr"`
+	Framerate  float64    `xml:"Framerate"`
+	Resolution Resolution `xml:"Resolution"`
+}
+
+// 图像设置 (Imaging 服务)
+type GetImagingSettings struct {
+	XMLName          xml.Name `xml:"http://www.onvif.org/ver20/imaging/wsdl GetImagingSettings"`
+	VideoSourceToken string   `xml:"VideoSourceToken"`
+}
+
+type GetImagingSettingsResponse struct {
+	ImagingSettings ImagingSettings `xml:"ImagingSettings"`
+}
+
+type SetImagingSettings struct {
+	XMLName          xml.Name        `xml:"http://www.onvif.org/ver20/imaging/wsdl SetImagingSettings"`
+	VideoSourceToken string          `xml:"VideoSourceToken"`
+	ImagingSettings  ImagingSettings `xml:"ImagingSettings"`
+	ForcePersistence bool            `xml:"ForcePersistence"`
+}
+
+// ImagingSettings 图像设置, 未设置的项不会出现在请求中 (设备保持不变)
+// 字段顺序与标准 ImagingSettings20 的元素顺序一致
+type ImagingSettings struct {
+	BacklightCompensation *BacklightCompensation `xml:"BacklightCompensation,omitempty"`
+	Brightness            *float64               `xml:"Brightness,omitempty"`
+	ColorSaturation       *float64               `xml:"ColorSaturation,omitempty"`
+	Contrast              *float64               `xml:"Contrast,omitempty"`
+	Exposure              *Exposure              `xml:"Exposure,omitempty"`
+	Focus                 *FocusConfiguration    `xml:"Focus,omitempty"`
+	IrCutFilter           string                 `xml:"IrCutFilter,omitempty"` // ON、OFF 或 AUTO
+	Sharpness             *float64               `xml:"Sharpness,omitempty"`
+	WideDynamicRange      *WideDynamicRange      `xml:"WideDynamicRange,omitempty"`
+	WhiteBalance          *WhiteBalance          `xml:"WhiteBalance,omitempty"`
+}
+
+// BacklightCompensation 背光补偿, Mode 为 ON 或 OFF
+type BacklightCompensation struct {
+	Mode  string   `xml:"Mode"`
+	Level *float64 `xml:"Level,omitempty"`
+}
+
+// WideDynamicRange 宽动态, Mode 为 ON 或 OFF
+type WideDynamicRange struct {
+	Mode  string   `xml:"Mode"`
+	Level *float64 `xml:"Level,omitempty"`
+}
+
+// Exposure 曝光, Mode 为 AUTO 或 MANUAL; AUTO 时使用 Min/Max 限制, MANUAL 时使用 ExposureTime/Gain/Iris
+type Exposure struct {
+	Mode            string          `xml:"Mode"`
+	Priority        string          `xml:"Priority,omitempty"`
+	Window          *ExposureWindow `xml:"Window,omitempty"`
+	MinExposureTime *float64        `xml:"MinExposureTime,omitempty"`
+	MaxExposureTime *float64        `xml:"MaxExposureTime,omitempty"`
+	MinGain         *float64        `xml:"MinGain,omitempty"`
+	MaxGain         *float64        `xml:"MaxGain,omitempty"`
+	MinIris         *float64        `xml:"MinIris,omitempty"`
+	MaxIris         *float64        `xml:"MaxIris,omitempty"`
+	ExposureTime    *float64        `xml:"ExposureTime,omitempty"`
+	Gain            *float64        `xml:"Gain,omitempty"`
+	Iris            *float64        `xml:"Iris,omitempty"`
+}
+
+// ExposureWindow 测光区域
+type ExposureWindow struct {
+	Bottom *float64 `xml:"bottom,attr,omitempty"`
+	Top    *float64 `xml:"top,attr,omitempty"`
+	Right  *float64 `xml:"right,attr,omitempty"`
+	Left   *float64 `xml:"left,attr,omitempty"`
+}
+
+// FocusConfiguration 对焦设置, AutoFocusMode 为 AUTO 或 MANUAL
+type FocusConfiguration struct {
+	AutoFocusMode string   `xml:"AutoFocusMode"`
+	DefaultSpeed  *float64 `xml:"DefaultSpeed,omitempty"`
+	NearLimit     *float64 `xml:"NearLimit,omitempty"`
+	FarLimit      *float64 `xml:"FarLimit,omitempty"`
+}
+
+// WhiteBalance 白平衡, Mode 为 AUTO 或 MANUAL
+type WhiteBalance struct {
+	Mode   string   `xml:"Mode"`
+	CrGain *float64 `xml:"CrGain,omitempty"`
+	CbGain *float64 `xml:"CbGain,omitempty"`
+}
+
+type GetImagingOptions struct {
+	XMLName          xml.Name `xml:"http://www.onvif.org/ver20/imaging/wsdl GetOptions"`
+	VideoSourceToken string   `xml:"VideoSourceToken"`
+}
+
+type GetImagingOptionsResponse struct {
+	ImagingOptions ImagingOptions `xml:"ImagingOptions"`
+}
+
+// ImagingOptions 视频源支持的图像设置取值范围和模式, 设备不支持的项为 nil
+type ImagingOptions struct {
+	BacklightCompensation *BacklightCompensationOptions `xml:"BacklightCompensation"`
+	Brightness            *FloatRange                   `xml:"Brightness"`
+	ColorSaturation       *FloatRange                   `xml:"ColorSaturation"`
+	Contrast              *FloatRange                   `xml:"Contrast"`
+	Exposure              *ExposureOptions              `xml:"Exposure"`
+	Focus                 *FocusOptions                 `xml:"Focus"`
+	IrCutFilterModes      []string                      `xml:"IrCutFilterModes"`
+	Sharpness             *FloatRange                   `xml:"Sharpness"`
+	WideDynamicRange      *WideDynamicRangeOptions      `xml:"WideDynamicRange"`
+	WhiteBalance          *WhiteBalanceOptions          `xml:"WhiteBalance"`
+}
+
+type BacklightCompensationOptions struct {
+	Mode  []string    `xml:"Mode"`
+	Level *FloatRange `xml:"Level"`
+}
+
+type WideDynamicRangeOptions struct {
+	Mode  []string    `xml:"Mode"`
+	Level *FloatRange `xml:"Level"`
+}
+
+type ExposureOptions struct {
+	Mode            []string    `xml:"Mode"`
+	Priority        []string    `xml:"Priority"`
+	MinExposureTime *FloatRange `xml:"MinExposureTime"`
+	MaxExposureTime *FloatRange `xml:"MaxExposureTime"`
+	MinGain         *FloatRange `xml:"MinGain"`
+	MaxGain         *FloatRange `xml:"MaxGain"`
+	MinIris         *FloatRange `xml:"MinIris"`
+	MaxIris         *FloatRange `xml:"MaxIris"`
+	ExposureTime    *FloatRange `xml:"ExposureTime"`
+	Gain            *FloatRange `xml:"Gain"`
+	Iris            *FloatRange `xml:"Iris"`
+}
+
+type FocusOptions struct {
+	AutoFocusModes []string    `xml:"AutoFocusModes"`
+	DefaultSpeed   *FloatRange `xml:"DefaultSpeed"`
+	NearLimit      *FloatRange `xml:"NearLimit"`
+	FarLimit       *FloatRange `xml:"FarLimit"`
+}
+
+type WhiteBalanceOptions struct {
+	Mode   []string    `xml:"Mode"`
+	YrGain *FloatRange `xml:"YrGain"`
+	YbGain *FloatRange `xml:"YbGain"`
+}
+
+// 对焦控制 (Imaging 服务)
+type ImagingMove struct {
+	XMLName          xml.Name  `xml:"http://www.onvif.org/ver20/imaging/wsdl Move"`
+	VideoSourceToken string    `xml:"VideoSourceToken"`
+	Focus            FocusMove `xml:"Focus"`
+}
+
+// FocusMove 对焦移动, 只能设置其中一种
+type FocusMove struct {
+	Absolute   *AbsoluteFocus   `xml:"Absolute,omitempty"`
+	Relative   *RelativeFocus   `xml:"Relative,omitempty"`
+	Continuous *ContinuousFocus `xml:"Continuous,omitempty"`
+}
+
+// AbsoluteFocus 移动到指定对焦位置
+type AbsoluteFocus struct {
+	Position float64  `xml:"Position"`
+	Speed    *float64 `xml:"Speed,omitempty"`
+}
+
+// RelativeFocus 按距离移动对焦, 正值向远处, 负值向近处
+type RelativeFocus struct {
+	Distance float64  `xml:"Distance"`
+	Speed    *float64 `xml:"Speed,omitempty"`
+}
+
+// ContinuousFocus 以指定速度持续移动对焦直到 Stop, 正值向远处, 负值向近处
+type ContinuousFocus struct {
+	Speed float64 `xml:"Speed"`
+}
+
+type ImagingStop struct {
+	XMLName          xml.Name `xml:"http://www.onvif.org/ver20/imaging/wsdl Stop"`
+	VideoSourceToken string   `xml:"VideoSourceToken"`
+}
+
+type GetImagingStatus struct {
+	XMLName          xml.Name `xml:"http://www.onvif.org/ver20/imaging/wsdl GetStatus"`
+	VideoSourceToken string   `xml:"VideoSourceToken"`
+}
+
+type GetImagingStatusResponse struct {
+	Status ImagingStatus `xml:"Status"`
+}
+
+// ImagingStatus 视频源的对焦状态
+type ImagingStatus struct {
+	FocusStatus *FocusStatus `xml:"FocusStatus20"` // 设备未返回对焦状态时为 nil
+}
+
+// FocusStatus 对焦位置和移动状态, MoveStatus 为 IDLE、MOVING 或 UNKNOWN
+type FocusStatus struct {
+	Position   float64 `xml:"Position"`
+	MoveStatus string  `xml:"MoveStatus"`
+	Error      string  `xml:"Error"`
+}
+
+type GetMoveOptions struct {
+	XMLName          xml.Name `xml:"http://www.onvif.org/ver20/imaging/wsdl GetMoveOptions"`
+	VideoSourceToken string   `xml:"VideoSourceToken"`
+}
+
+type GetMoveOptionsResponse struct {
+	MoveOptions MoveOptions `xml:"MoveOptions"`
+}
+
+// MoveOptions 视频源支持的对焦移动方式及取值范围, 不支持的方式为 nil
+type MoveOptions struct {
+	Absolute   *AbsoluteFocusOptions   `xml:"Absolute"`
+	Relative   *RelativeFocusOptions   `xml:"Relative"`
+	Continuous *ContinuousFocusOptions `xml:"Continuous"`
+}
+
+type AbsoluteFocusOptions struct {
+	Position FloatRange  `xml:"Position"`
+	Speed    *FloatRange `xml:"Speed"`
+}
+
+type RelativeFocusOptions struct {
+	Distance FloatRange  `xml:"Distance"`
+	Speed    *FloatRange `xml:"Speed"`
+}
+
+type ContinuousFocusOptions struct {
+	Speed FloatRange `xml:"Speed"`
+}
+
+// 网络配置
+type GetNetworkInterfaces struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetNetworkInterfaces"`
+}
+
+type GetNetworkInterfacesResponse struct {
+	NetworkInterfaces []NetworkInterface `xml:"NetworkInterfaces"`
+}
+
+type NetworkInterface struct {
+	Token   string               `xml:"token,attr"`
+	Enabled bool                 `xml:"Enabled"`
+	Info    NetworkInterfaceInfo `xml:"Info"`
+	IPv4    IPv4Configuration    `xml:"IPv4"`
+}
+
+type NetworkInterfaceInfo struct {
+	Name      string `xml:"Name"`
+	HwAddress string `xml:"HwAddress"`
+	MTU       int    `xml:"MTU"`
+}
+
+type IPv4Configuration struct {
+	Enabled  bool                  `xml:"Enabled"`
+	DHCP     bool                  `xml:"Config>DHCP"`
+	Manual   []PrefixedIPv4Address `xml:"Config>Manual"`
+	FromDHCP *PrefixedIPv4Address  `xml:"Config>FromDHCP"` // DHCP 分配的地址
+}
+
+type PrefixedIPv4Address struct {
+	Address      string `xml:"Address"`
+	PrefixLength int    `xml:"PrefixLength"`
+}
+
+// 服务地址
+type GetServices struct {
+	XMLName           xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetServices"`
+	IncludeCapability bool     `xml:"IncludeCapability"`
+}
+
+type GetServicesResponse struct {
+	Services []Service `xml:"Service"`
+}
+
+type Service struct {
+	Namespace string       `xml:"Namespace"`
+	XAddr     string       `xml:"XAddr"`
+	Version   OnvifVersion `xml:"Version"`
+}
+
+type OnvifVersion struct {
+	Major int `xml:"Major"`
+	Minor int `xml:"Minor"`
+}
+
+// 设备能力 (GetServices 不可用时的回退方案)
+type GetCapabilities struct {
+	XMLName  xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetCapabilities"`
+	Category string   `xml:"Category"`
+}
+
+type GetCapabilitiesResponse struct {
+	Capabilities Capabilities `xml:"Capabilities"`
+}
+
+type Capabilities struct {
+	Device    ServiceCapability     `xml:"Device"`
+	Media     ServiceCapability     `xml:"Media"`
+	Events    ServiceCapability     `xml:"Events"`
+	PTZ       ServiceCapability     `xml:"PTZ"`
+	Imaging   ServiceCapability     `xml:"Imaging"`
+	Extension CapabilitiesExtension `xml:"Extension"`
+}
+
+type CapabilitiesExtension struct {
+	DeviceIO ServiceCapability `xml:"DeviceIO"`
+	Media2   ServiceCapability `xml:"Media2"`
+}
+
+type ServiceCapability struct {
+	XAddr string `xml:"XAddr"`
+}
+
+// 时间同步相关结构
+type SetSystemDateAndTime struct {
+	XMLName         xml.Name     `xml:"http://www.onvif.org/ver10/device/wsdl SetSystemDateAndTime"`
+	DateTimeType    string       `xml:"DateTimeType"`
+	DaylightSavings bool         `xml:"DaylightSavings"`
+	UTCDateTime     *UTCDateTime `xml:"UTCDateTime,omitempty"`
+}
+
+type SetNTP struct {
+	XMLName   xml.Name      `xml:"http://www.onvif.org/ver10/device/wsdl SetNTP"`
+	FromDHCP  bool          `xml:"FromDHCP"`
+	NTPManual []NetworkHost `xml:"NTPManual"`
+}
+
+type NetworkHost struct {
+	Type        string `xml:"Type"`
+	IPv4Address string `xml:"IPv4Address,omitempty"`
+	DNSname     string `xml:"DNSname,omitempty"`
+}
+
+// 事件订阅相关结构
+type CreatePullPointSubscription struct {
+	XMLName                xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl CreatePullPointSubscription"`
+	Filter                 *Filter  `xml:"Filter,omitempty"`
+	InitialTerminationTime string   `xml:"InitialTerminationTime,omitempty"`
+}
+
+type Filter struct {
+	TopicExpression string `xml:"TopicExpression,omitempty"`
+}
+
+type CreatePullPointSubscriptionResponse struct {
+	SubscriptionReference SubscriptionReference `xml:"SubscriptionReference"`
+	CurrentTime           string                `xml:"CurrentTime"`
+	TerminationTime       string                `xml:"TerminationTime"`
+}
+
+type SubscriptionReference struct {
+	Address string `xml:"Address"`
+}
+
+type PullMessages struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl PullMessages"`
+	Timeout      string   `xml:"Timeout"`
+	MessageLimit int      `xml:"MessageLimit"`
+}
+
+type PullMessagesResponse struct {
+	CurrentTime         string                `xml:"CurrentTime"`
+	TerminationTime     string                `xml:"TerminationTime"`
+	NotificationMessage []NotificationMessage `xml:"NotificationMessage"`
+}
+
+type NotificationMessage struct {
+	Topic   Topic   `xml:"Topic"`
+	Message Message `xml:"Message>Message"` // wsnt:Message 内嵌的 tt:Message
+}
+
+type Topic struct {
+	Dialect string `xml:"Dialect,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type Message struct {
+	UtcTime           string      `xml:"UtcTime,attr"`
+	PropertyOperation string      `xml:"PropertyOperation,attr"` // Initialized / Changed / Deleted
+	Source            EventSource `xml:"Source"`
+	Data              EventData   `xml:"Data"`
+}
+
+type EventSource struct {
+	SimpleItem []SimpleItem `xml:"SimpleItem"`
+}
+
+type EventData struct {
+	SimpleItem []SimpleItem `xml:"SimpleItem"`
+}
+
+type SimpleItem struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+}
+
+type Renew struct {
+	XMLName         xml.Name `xml:"http://docs.oasis-open.org/wsn/b-2 Renew"`
+	TerminationTime string   `xml:"TerminationTime"`
+}
+
+type RenewResponse struct {
+	TerminationTime string `xml:"TerminationTime"`
+	CurrentTime     string `xml:"CurrentTime"`
+}
+
+type Unsubscribe struct {
+	XMLName xml.Name `xml:"http://docs.oasis-open.org/wsn/b-2 Unsubscribe"`
+}
@@ -0,0 +1,679 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PTZProfile 选择用于 PTZ 操作的媒体配置
+// 指定 token 或 name 时按其查找; 都为空时选择第一个绑定了 PTZ 配置的 Profile,
+// 设备的 Profile 均未声明 PTZ 配置时退回第一个 Profile
+func (c *Client) PTZProfile(ctx context.Context, token, name string) (Profile, error) {
+	profiles, err := c.GetProfiles(ctx)
+	if err != nil {
+		return Profile{}, fmt.Errorf("获取 profiles 失败: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		return Profile{}, fmt.Errorf("设备没有可用的 profile")
+	}
+
+	switch {
+	case token != "":
+		for _, p := range profiles {
+			if p.Token == token {
+				return p, nil
+			}
+		}
+		return Profile{}, fmt.Errorf("未找到 Token 为 %s 的 profile", token)
+	case name != "":
+		for _, p := range profiles {
+			if p.Name == name {
+				return p, nil
+			}
+		}
+		return Profile{}, fmt.Errorf("未找到名称为 %s 的 profile", name)
+	}
+
+	for _, p := range profiles {
+		if p.PTZConfiguration != nil {
+			return p, nil
+		}
+	}
+
+	c.debugf("没有 Profile 声明 PTZ 配置, 使用第一个 Profile: %s\n", profiles[0].Token)
+	return profiles[0], nil
+}
+
+// ContinuousMove 以指定速度 (-1.0 到 1.0) 持续移动, timeout 为 0 时一直移动直到 Stop
+func (c *Client) ContinuousMove(ctx context.Context, profileToken string, pan, tilt, zoom float64, timeout time.Duration) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	moveReq := ContinuousMove{
+		ProfileToken: profileToken,
+		Velocity: PTZSpeed{
+			PanTilt: PanTilt{X: pan, Y: tilt},
+			Zoom:    Zoom{X: zoom},
+		},
+	}
+
+	if timeout > 0 {
+		moveReq.Timeout = XSDDuration(timeout)
+	}
+
+	return c.call(ctx, ptzAddr, &moveReq, nil)
+}
+
+// Stop 停止水平/垂直移动和缩放
+func (c *Client) Stop(ctx context.Context, profileToken string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	stopReq := Stop{
+		ProfileToken: profileToken,
+		PanTilt:      true,
+		Zoom:         true,
+	}
+
+	return c.call(ctx, ptzAddr, &stopReq, nil)
+}
+
+// GotoPreset 转到预置位
+func (c *Client) GotoPreset(ctx context.Context, profileToken, presetToken string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	gotoReq := GotoPreset{
+		ProfileToken: profileToken,
+		PresetToken:  presetToken,
+	}
+
+	return c.call(ctx, ptzAddr, &gotoReq, nil)
+}
+
+// SetPreset 将当前位置保存为预置位, 返回设备分配的预置位 Token
+func (c *Client) SetPreset(ctx context.Context, profileToken, presetName string) (string, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return "", err
+	}
+
+	setReq := SetPreset{
+		ProfileToken: profileToken,
+		PresetName:   presetName,
+	}
+
+	var resp SetPresetResponse
+	if err := c.call(ctx, ptzAddr, &setReq, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.PresetToken, nil
+}
+
+// UpdatePreset 将当前位置保存到已有的预置位, presetName 不为空时同时修改名称
+func (c *Client) UpdatePreset(ctx context.Context, profileToken, presetToken, presetName string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	setReq := SetPreset{
+		ProfileToken: profileToken,
+		PresetName:   presetName,
+		PresetToken:  presetToken,
+	}
+
+	return c.call(ctx, ptzAddr, &setReq, nil)
+}
+
+// RemovePreset 删除预置位
+func (c *Client) RemovePreset(ctx context.Context, profileToken, presetToken string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	removeReq := RemovePreset{
+		ProfileToken: profileToken,
+		PresetToken:  presetToken,
+	}
+
+	return c.call(ctx, ptzAddr, &removeReq, nil)
+}
+
+// FindPreset 按 Token 或名称查找预置位, Token 优先
+// 未找到时 ok 为 false; 名称对应多个预置位时返回错误
+func FindPreset(presets []PTZPreset, ref string) (preset PTZPreset, ok bool, err error) {
+	for _, p := range presets {
+		if p.Token == ref {
+			return p, true, nil
+		}
+	}
+
+	var matches []PTZPreset
+	for _, p := range presets {
+		if p.Name == ref {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return PTZPreset{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	default:
+		return PTZPreset{}, false, fmt.Errorf("有 %d 个预置位名为 %s, 请使用 Token 指定", len(matches), ref)
+	}
+}
+
+// GetPresets 获取预置位列表
+func (c *Client) GetPresets(ctx context.Context, profileToken string) ([]PTZPreset, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetPresetsResponse
+	if err := c.call(ctx, ptzAddr, &GetPresets{ProfileToken: profileToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Presets, nil
+}
+
+// GotoHomePosition 转到原点位置, speed 为 nil 时使用设备默认速度
+func (c *Client) GotoHomePosition(ctx context.Context, profileToken string, speed *PTZVector) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	gotoReq := GotoHomePosition{
+		ProfileToken: profileToken,
+		Speed:        speed,
+	}
+
+	return c.call(ctx, ptzAddr, &gotoReq, nil)
+}
+
+// SetHomePosition 将当前位置设为原点位置 (原点固定的设备会返回错误)
+func (c *Client) SetHomePosition(ctx context.Context, profileToken string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	return c.call(ctx, ptzAddr, &SetHomePosition{ProfileToken: profileToken}, nil)
+}
+
+// 预置位巡航操作
+const (
+	PresetTourStart = "Start"
+	PresetTourStop  = "Stop"
+	PresetTourPause = "Pause"
+)
+
+// GetPresetTours 获取预置位巡航列表
+func (c *Client) GetPresetTours(ctx context.Context, profileToken string) ([]PresetTour, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetPresetToursResponse
+	if err := c.call(ctx, ptzAddr, &GetPresetTours{ProfileToken: profileToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.PresetTours, nil
+}
+
+// CreatePresetTour 新建空的预置位巡航, 返回设备分配的 Token, 之后用 ModifyPresetTour 设置内容
+func (c *Client) CreatePresetTour(ctx context.Context, profileToken string) (string, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return "", err
+	}
+
+	var resp CreatePresetTourResponse
+	if err := c.call(ctx, ptzAddr, &CreatePresetTour{ProfileToken: profileToken}, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.PresetTourToken, nil
+}
+
+// ModifyPresetTour 修改预置位巡航, tour.Token 指定要修改的巡航
+func (c *Client) ModifyPresetTour(ctx context.Context, profileToken string, tour PresetTour) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	modifyReq := ModifyPresetTour{
+		ProfileToken: profileToken,
+		PresetTour:   tour,
+	}
+
+	return c.call(ctx, ptzAddr, &modifyReq, nil)
+}
+
+// OperatePresetTour 开始、停止或暂停预置位巡航 (PresetTourStart/Stop/Pause)
+func (c *Client) OperatePresetTour(ctx context.Context, profileToken, tourToken, operation string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	operateReq := OperatePresetTour{
+		ProfileToken:    profileToken,
+		PresetTourToken: tourToken,
+		Operation:       operation,
+	}
+
+	return c.call(ctx, ptzAddr, &operateReq, nil)
+}
+
+// RemovePresetTour 删除预置位巡航
+func (c *Client) RemovePresetTour(ctx context.Context, profileToken, tourToken string) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	removeReq := RemovePresetTour{
+		ProfileToken:    profileToken,
+		PresetTourToken: tourToken,
+	}
+
+	return c.call(ctx, ptzAddr, &removeReq, nil)
+}
+
+// FindPresetTour 按 Token 或名称查找预置位巡航, Token 优先
+// 未找到时 ok 为 false; 名称对应多个巡航时返回错误
+func FindPresetTour(tours []PresetTour, ref string) (tour PresetTour, ok bool, err error) {
+	for _, t := range tours {
+		if t.Token == ref {
+			return t, true, nil
+		}
+	}
+
+	var matches []PresetTour
+	for _, t := range tours {
+		if t.Name == ref {
+			matches = append(matches, t)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return PresetTour{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	default:
+		return PresetTour{}, false, fmt.Errorf("有 %d 个巡航名为 %s, 请使用 Token 指定", len(matches), ref)
+	}
+}
+
+// GetNodes 获取全部云台节点
+func (c *Client) GetNodes(ctx context.Context) ([]PTZNode, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetNodesResponse
+	if err := c.call(ctx, ptzAddr, &GetNodes{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.PTZNodes, nil
+}
+
+// GetNode 获取指定的云台节点
+func (c *Client) GetNode(ctx context.Context, nodeToken string) (*PTZNode, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetNodeResponse
+	if err := c.call(ctx, ptzAddr, &GetNode{NodeToken: nodeToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.PTZNode, nil
+}
+
+// GetConfigurations 获取全部 PTZ 配置
+func (c *Client) GetConfigurations(ctx context.Context) ([]PTZConfiguration, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetConfigurationsResponse
+	if err := c.call(ctx, ptzAddr, &GetConfigurations{}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.PTZConfigurations, nil
+}
+
+// SendAuxiliaryCommand 发送辅助命令 (如 tt:Wiper|On), 返回设备的响应文本
+// 设备支持的命令见 PTZNode.AuxiliaryCommands
+func (c *Client) SendAuxiliaryCommand(ctx context.Context, profileToken, command string) (string, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return "", err
+	}
+
+	auxReq := SendAuxiliaryCommand{
+		ProfileToken:  profileToken,
+		AuxiliaryData: command,
+	}
+
+	var resp SendAuxiliaryCommandResponse
+	if err := c.call(ctx, ptzAddr, &auxReq, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.AuxiliaryResponse, nil
+}
+
+// PTZ 坐标空间 URI
+const (
+	PanTiltPositionGenericSpace    = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/PositionGenericSpace"
+	PanTiltTranslationGenericSpace = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/TranslationGenericSpace"
+	PanTiltPositionDegreesSpace    = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/SphericalPositionSpaceDegrees"
+	PanTiltTranslationDegreesSpace = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/SphericalTranslationSpaceDegrees"
+	PanTiltGenericSpeedSpace       = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/GenericSpeedSpace"
+	ZoomPositionGenericSpace       = "http://www.onvif.org/ver10/tptz/ZoomSpaces/PositionGenericSpace"
+	ZoomTranslationGenericSpace    = "http://www.onvif.org/ver10/tptz/ZoomSpaces/TranslationGenericSpace"
+	ZoomGenericSpeedSpace          = "http://www.onvif.org/ver10/tptz/ZoomSpaces/ZoomGenericSpeedSpace"
+)
+
+// AbsoluteMove 移动到指定位置, speed 为 nil 时使用设备默认速度
+func (c *Client) AbsoluteMove(ctx context.Context, profileToken string, position PTZVector, speed *PTZVector) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	moveReq := AbsoluteMove{
+		ProfileToken: profileToken,
+		Position:     position,
+		Speed:        speed,
+	}
+
+	return c.call(ctx, ptzAddr, &moveReq, nil)
+}
+
+// RelativeMove 相对当前位置移动, speed 为 nil 时使用设备默认速度
+func (c *Client) RelativeMove(ctx context.Context, profileToken string, translation PTZVector, speed *PTZVector) error {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return err
+	}
+
+	moveReq := RelativeMove{
+		ProfileToken: profileToken,
+		Translation:  translation,
+		Speed:        speed,
+	}
+
+	return c.call(ctx, ptzAddr, &moveReq, nil)
+}
+
+// GetConfigurationOptions 获取 PTZ 配置支持的坐标空间及取值范围
+func (c *Client) GetConfigurationOptions(ctx context.Context, configToken string) (*PTZConfigurationOptions, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetConfigurationOptionsResponse
+	if err := c.call(ctx, ptzAddr, &GetConfigurationOptions{ConfigurationToken: configToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.PTZConfigurationOptions, nil
+}
+
+// ValidatePosition 检查绝对移动的目标位置是否使用设备支持的坐标空间且在范围内
+// 未指定坐标空间的轴按通用坐标空间检查
+func (o *PTZConfigurationOptions) ValidatePosition(position PTZVector) error {
+	if err := validatePanTilt(position.PanTilt, o.Spaces.AbsolutePanTiltPositionSpace, PanTiltPositionGenericSpace); err != nil {
+		return err
+	}
+	return validateZoom(position.Zoom, o.Spaces.AbsoluteZoomPositionSpace, ZoomPositionGenericSpace)
+}
+
+// ValidateTranslation 检查相对移动的位移是否使用设备支持的坐标空间且在范围内
+func (o *PTZConfigurationOptions) ValidateTranslation(translation PTZVector) error {
+	if err := validatePanTilt(translation.PanTilt, o.Spaces.RelativePanTiltTranslationSpace, PanTiltTranslationGenericSpace); err != nil {
+		return err
+	}
+	return validateZoom(translation.Zoom, o.Spaces.RelativeZoomTranslationSpace, ZoomTranslationGenericSpace)
+}
+
+// ValidateSpeed 检查移动速度是否在设备支持的速度空间范围内
+func (o *PTZConfigurationOptions) ValidateSpeed(speed PTZVector) error {
+	if pt := speed.PanTilt; pt != nil {
+		space, err := findSpace1D(o.Spaces.PanTiltSpeedSpace, pt.Space, PanTiltGenericSpeedSpace)
+		if err != nil {
+			return err
+		}
+		if !space.XRange.Contains(pt.X) {
+			return rangeError("水平速度", pt.X, space.XRange)
+		}
+		if !space.XRange.Contains(pt.Y) {
+			return rangeError("垂直速度", pt.Y, space.XRange)
+		}
+	}
+
+	if z := speed.Zoom; z != nil {
+		space, err := findSpace1D(o.Spaces.ZoomSpeedSpace, z.Space, ZoomGenericSpeedSpace)
+		if err != nil {
+			return err
+		}
+		if !space.XRange.Contains(z.X) {
+			return rangeError("缩放速度", z.X, space.XRange)
+		}
+	}
+
+	return nil
+}
+
+// 检查水平/垂直坐标
+func validatePanTilt(pt *PanTilt, spaces []Space2DDescription, defaultURI string) error {
+	if pt == nil {
+		return nil
+	}
+
+	uri := pt.Space
+	if uri == "" {
+		uri = defaultURI
+	}
+
+	for _, space := range spaces {
+		if space.URI != uri {
+			continue
+		}
+		if !space.XRange.Contains(pt.X) {
+			return rangeError("水平坐标", pt.X, space.XRange)
+		}
+		if !space.YRange.Contains(pt.Y) {
+			return rangeError("垂直坐标", pt.Y, space.YRange)
+		}
+		return nil
+	}
+
+	uris := make([]string, len(spaces))
+	for i, space := range spaces {
+		uris[i] = space.URI
+	}
+	return unsupportedSpaceError(uri, uris)
+}
+
+// 检查缩放坐标
+func validateZoom(z *Zoom, spaces []Space1DDescription, defaultURI string) error {
+	if z == nil {
+		return nil
+	}
+
+	space, err := findSpace1D(spaces, z.Space, defaultURI)
+	if err != nil {
+		return err
+	}
+	if !space.XRange.Contains(z.X) {
+		return rangeError("缩放坐标", z.X, space.XRange)
+	}
+
+	return nil
+}
+
+// 按 URI 查找一维坐标空间, uri 为空时使用 defaultURI
+func findSpace1D(spaces []Space1DDescription, uri, defaultURI string) (Space1DDescription, error) {
+	if uri == "" {
+		uri = defaultURI
+	}
+
+	uris := make([]string, len(spaces))
+	for i, space := range spaces {
+		if space.URI == uri {
+			return space, nil
+		}
+		uris[i] = space.URI
+	}
+
+	return Space1DDescription{}, unsupportedSpaceError(uri, uris)
+}
+
+func unsupportedSpaceError(uri string, supported []string) error {
+	if len(supported) == 0 {
+		return fmt.Errorf("设备不支持坐标空间 %s (该类移动没有可用的坐标空间)", uri)
+	}
+	return fmt.Errorf("设备不支持坐标空间 %s (支持: %s)", uri, strings.Join(supported, ", "))
+}
+
+func rangeError(name string, v float64, r FloatRange) error {
+	return fmt.Errorf("%s %g 超出设备允许的范围 [%g, %g]", name, v, r.Min, r.Max)
+}
+
+// GetStatus 获取云台当前位置和移动状态
+func (c *Client) GetStatus(ctx context.Context, profileToken string) (*PTZStatus, error) {
+	ptzAddr, err := c.serviceAddr(ctx, NamespacePTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetStatusResponse
+	if err := c.call(ctx, ptzAddr, &GetStatus{ProfileToken: profileToken}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.PTZStatus, nil
+}
+
+// Moving 判断云台是否仍在移动 (任一轴为 MOVING)
+func (s *PTZStatus) Moving() bool {
+	return strings.EqualFold(s.MoveStatus.PanTilt, "MOVING") || strings.EqualFold(s.MoveStatus.Zoom, "MOVING")
+}
+
+// Near 判断当前位置是否在目标位置的容差范围内
+// 只比较 target 中设置的轴, 坐标空间与设备报告的不同时无法比较, 返回 false
+func (s *PTZStatus) Near(target PTZVector, tolerance float64) bool {
+	if s.Position == nil || (target.PanTilt == nil && target.Zoom == nil) {
+		return false
+	}
+
+	if pt := target.PanTilt; pt != nil {
+		pos := s.Position.PanTilt
+		if !sameSpace(pt.Space, pos.Space, PanTiltPositionGenericSpace) ||
+			math.Abs(pos.X-pt.X) > tolerance || math.Abs(pos.Y-pt.Y) > tolerance {
+			return false
+		}
+	}
+
+	if z := target.Zoom; z != nil {
+		pos := s.Position.Zoom
+		if !sameSpace(z.Space, pos.Space, ZoomPositionGenericSpace) || math.Abs(pos.X-z.X) > tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 比较坐标空间, 未指定的按通用坐标空间处理
+func sameSpace(a, b, generic string) bool {
+	if a == "" {
+		a = generic
+	}
+	if b == "" {
+		b = generic
+	}
+	return a == b
+}
+
+// 等待移动结束时连续多少次查询为静止才认为已停止
+// 设备收到移动命令后可能要稍后才报告 MOVING
+const idlePolls = 2
+
+// WaitOptions 等待云台停止的选项
+type WaitOptions struct {
+	Target    *PTZVector    // 目标位置, 静止且位于容差范围内即认为完成; 为 nil 时只按移动状态判断
+	Tolerance float64       // 目标位置容差
+	Interval  time.Duration // 查询间隔, 为 0 时使用 250ms
+}
+
+// WaitIdle 轮询 GetStatus 直到云台停止移动, 返回最后一次查询的状态
+// 超时或取消由 ctx 控制
+func (c *Client) WaitIdle(ctx context.Context, profileToken string, opts WaitOptions) (*PTZStatus, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	idle := 0
+	for {
+		status, err := c.GetStatus(ctx, profileToken)
+		if err != nil {
+			return nil, err
+		}
+
+		// 已静止且位于目标位置时无需再次确认
+		moving := status.Moving()
+		if !moving && opts.Target != nil && status.Near(*opts.Target, opts.Tolerance) {
+			return status, nil
+		}
+
+		if moving {
+			idle = 0
+		} else if idle++; idle >= idlePolls {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
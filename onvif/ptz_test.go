@@ -0,0 +1,225 @@
+package onvif
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDegreesSpace = PanTiltPositionDegreesSpace
+
+// 通用坐标空间为 [-1, 1], 缩放 [0, 1]; 另有角度空间 (水平 [-180, 180], 垂直 [-90, 0])
+func testPTZOptions() *PTZConfigurationOptions {
+	generic2D := func(uri string) Space2DDescription {
+		return Space2DDescription{URI: uri, XRange: FloatRange{Min: -1, Max: 1}, YRange: FloatRange{Min: -1, Max: 1}}
+	}
+
+	return &PTZConfigurationOptions{Spaces: PTZSpaces{
+		AbsolutePanTiltPositionSpace: []Space2DDescription{
+			generic2D(PanTiltPositionGenericSpace),
+			{URI: testDegreesSpace, XRange: FloatRange{Min: -180, Max: 180}, YRange: FloatRange{Min: -90, Max: 0}},
+		},
+		AbsoluteZoomPositionSpace:       []Space1DDescription{{URI: ZoomPositionGenericSpace, XRange: FloatRange{Min: 0, Max: 1}}},
+		RelativePanTiltTranslationSpace: []Space2DDescription{generic2D(PanTiltTranslationGenericSpace)},
+		RelativeZoomTranslationSpace:    []Space1DDescription{{URI: ZoomTranslationGenericSpace, XRange: FloatRange{Min: -1, Max: 1}}},
+		PanTiltSpeedSpace:               []Space1DDescription{{URI: PanTiltGenericSpeedSpace, XRange: FloatRange{Min: 0, Max: 1}}},
+		ZoomSpeedSpace:                  []Space1DDescription{{URI: ZoomGenericSpeedSpace, XRange: FloatRange{Min: 0, Max: 1}}},
+	}}
+}
+
+func vec(pt *PanTilt, z *Zoom) PTZVector {
+	return PTZVector{PanTilt: pt, Zoom: z}
+}
+
+// wantErr 为空表示应通过校验, 否则为错误信息中应包含的内容
+func checkValidateError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Errorf("err = %v, want nil", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("err = %v, want 包含 %q", err, wantErr)
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	noZoom := testPTZOptions()
+	noZoom.Spaces.AbsoluteZoomPositionSpace = nil
+
+	tests := []struct {
+		name     string
+		options  *PTZConfigurationOptions
+		position PTZVector
+		wantErr  string
+	}{
+		{"下限包含在内", nil, vec(&PanTilt{X: -1, Y: -1}, &Zoom{X: 0}), ""},
+		{"上限包含在内", nil, vec(&PanTilt{X: 1, Y: 1}, &Zoom{X: 1}), ""},
+		{"水平超出", nil, vec(&PanTilt{X: 1.0001, Y: 0}, nil), "水平坐标"},
+		{"垂直超出", nil, vec(&PanTilt{X: 0, Y: -1.5}, nil), "垂直坐标"},
+		{"缩放超出", nil, vec(nil, &Zoom{X: -0.1}), "缩放坐标"},
+		{"只有缩放", nil, vec(nil, &Zoom{X: 0.5}), ""},
+		{"只有水平/垂直", nil, vec(&PanTilt{X: 0.5, Y: 0.5}, nil), ""},
+		{"没有设置任何轴", nil, vec(nil, nil), ""},
+		{"显式指定通用坐标空间", nil, vec(&PanTilt{X: 0, Y: 0, Space: PanTiltPositionGenericSpace}, &Zoom{X: 0, Space: ZoomPositionGenericSpace}), ""},
+		{"角度空间", nil, vec(&PanTilt{X: 180, Y: -90, Space: testDegreesSpace}, nil), ""},
+		{"角度空间垂直超出", nil, vec(&PanTilt{X: 0, Y: 10, Space: testDegreesSpace}, nil), "垂直坐标"},
+		{"不支持的坐标空间", nil, vec(&PanTilt{X: 0, Y: 0, Space: "http://example.com/space"}, nil), "不支持坐标空间"},
+		{"相对移动的坐标空间", nil, vec(&PanTilt{X: 0, Y: 0, Space: PanTiltTranslationGenericSpace}, nil), "不支持坐标空间"},
+		{"设备不支持缩放", noZoom, vec(nil, &Zoom{X: 0.5}), "没有可用的坐标空间"},
+		{"设备不支持缩放时只移动水平/垂直", noZoom, vec(&PanTilt{X: 0, Y: 0}, nil), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if options == nil {
+				options = testPTZOptions()
+			}
+			checkValidateError(t, options.ValidatePosition(tt.position), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateTranslation(t *testing.T) {
+	tests := []struct {
+		name        string
+		translation PTZVector
+		wantErr     string
+	}{
+		{"范围边界", vec(&PanTilt{X: -1, Y: 1}, &Zoom{X: -1}), ""},
+		{"水平超出", vec(&PanTilt{X: -1.1, Y: 0}, nil), "水平坐标"},
+		{"缩放超出", vec(nil, &Zoom{X: 1.1}), "缩放坐标"},
+		{"绝对移动的坐标空间", vec(&PanTilt{X: 0, Y: 0, Space: PanTiltPositionGenericSpace}, nil), "不支持坐标空间"},
+		{"设备只支持通用空间时不接受角度", vec(&PanTilt{X: 10, Y: 0, Space: PanTiltTranslationDegreesSpace}, nil), "不支持坐标空间"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkValidateError(t, testPTZOptions().ValidateTranslation(tt.translation), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateSpeed(t *testing.T) {
+	noSpeed := testPTZOptions()
+	noSpeed.Spaces.PanTiltSpeedSpace = nil
+
+	tests := []struct {
+		name    string
+		options *PTZConfigurationOptions
+		speed   PTZVector
+		wantErr string
+	}{
+		{"范围边界", nil, vec(&PanTilt{X: 0, Y: 1}, &Zoom{X: 1}), ""},
+		{"水平速度超出", nil, vec(&PanTilt{X: 1.5, Y: 0.5}, nil), "水平速度"},
+		// 水平和垂直速度共用 PanTiltSpeedSpace 的 XRange
+		{"垂直速度超出", nil, vec(&PanTilt{X: 0.5, Y: -0.1}, nil), "垂直速度"},
+		{"缩放速度超出", nil, vec(nil, &Zoom{X: 2}), "缩放速度"},
+		{"只有缩放速度", noSpeed, vec(nil, &Zoom{X: 0.5}), ""},
+		{"设备没有水平/垂直速度空间", noSpeed, vec(&PanTilt{X: 0.5, Y: 0.5}, nil), "没有可用的坐标空间"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if options == nil {
+				options = testPTZOptions()
+			}
+			checkValidateError(t, options.ValidateSpeed(tt.speed), tt.wantErr)
+		})
+	}
+}
+
+func TestPTZStatusNear(t *testing.T) {
+	// 0.5 和 0.25 可以精确表示, 差值恰好等于容差时不受浮点误差影响
+	status := &PTZStatus{Position: &PTZPosition{
+		PanTilt: PanTilt{X: 0.5, Y: -0.5},
+		Zoom:    Zoom{X: 0.25},
+	}}
+	degrees := &PTZStatus{Position: &PTZPosition{
+		PanTilt: PanTilt{X: 0.5, Y: -0.5, Space: testDegreesSpace},
+		Zoom:    Zoom{X: 0.25},
+	}}
+
+	tests := []struct {
+		name   string
+		status *PTZStatus
+		target PTZVector
+		want   bool
+	}{
+		{"位置相同", status, vec(&PanTilt{X: 0.5, Y: -0.5}, &Zoom{X: 0.25}), true},
+		{"差值等于容差", status, vec(&PanTilt{X: 0.75, Y: -0.25}, &Zoom{X: 0.5}), true},
+		{"水平超出容差", status, vec(&PanTilt{X: 0.8, Y: -0.5}, nil), false},
+		{"垂直超出容差", status, vec(&PanTilt{X: 0.5, Y: 0}, nil), false},
+		{"缩放超出容差", status, vec(&PanTilt{X: 0.5, Y: -0.5}, &Zoom{X: 0.75}), false},
+		{"只比较缩放", status, vec(nil, &Zoom{X: 0.25}), true},
+		{"只比较水平/垂直", status, vec(&PanTilt{X: 0.5, Y: -0.5}, nil), true},
+		{"目标没有设置任何轴", status, vec(nil, nil), false},
+		{"设备未返回位置", &PTZStatus{}, vec(nil, &Zoom{X: 0.25}), false},
+		{"显式通用空间与未指定空间相同", status, vec(&PanTilt{X: 0.5, Y: -0.5, Space: PanTiltPositionGenericSpace}, nil), true},
+		{"目标为角度空间", status, vec(&PanTilt{X: 0.5, Y: -0.5, Space: testDegreesSpace}, nil), false},
+		{"设备报告角度空间", degrees, vec(&PanTilt{X: 0.5, Y: -0.5}, nil), false},
+		{"坐标空间相同", degrees, vec(&PanTilt{X: 0.5, Y: -0.5, Space: testDegreesSpace}, &Zoom{X: 0.25}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Near(tt.target, 0.25); got != tt.want {
+				t.Errorf("Near() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPreset(t *testing.T) {
+	presets := []PTZPreset{
+		{Token: "1", Name: "Door"},
+		{Token: "2", Name: "Gate"},
+		{Token: "3", Name: "Gate"},
+		{Token: "4", Name: "1"},
+		{Token: "Gate", Name: "Yard"},
+	}
+
+	tests := []struct {
+		name      string
+		ref       string
+		wantToken string // 为空表示未找到
+	}{
+		{"按 Token", "2", "2"},
+		{"按名称", "Door", "1"},
+		{"Token 优先于名称", "1", "1"},
+		{"Token 匹配时不检查重名", "Gate", "Gate"},
+		{"名称唯一", "Yard", "Gate"},
+		{"未找到", "Lobby", ""},
+		{"大小写敏感", "door", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := FindPreset(presets, tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (tt.wantToken != "") {
+				t.Fatalf("ok = %v, want token %q", ok, tt.wantToken)
+			}
+			if got.Token != tt.wantToken {
+				t.Errorf("Token = %q, want %q", got.Token, tt.wantToken)
+			}
+		})
+	}
+
+	// 名称对应多个预置位且没有同名 Token 时需要用 Token 指定
+	dup := []PTZPreset{{Token: "2", Name: "Gate"}, {Token: "3", Name: "Gate"}}
+	if _, ok, err := FindPreset(dup, "Gate"); err == nil || ok {
+		t.Errorf("重名时应返回错误, got ok=%v err=%v", ok, err)
+	} else if !strings.Contains(err.Error(), "2 个预置位") {
+		t.Errorf("err = %v, want 包含重名数量", err)
+	}
+
+	if _, ok, err := FindPreset(nil, "1"); ok || err != nil {
+		t.Errorf("空列表: got ok=%v err=%v, want 未找到", ok, err)
+	}
+}
@@ -0,0 +1,232 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"strconv"
+
+	"onvifctl/soap"
+)
+
+// ONVIF 服务命名空间 (ServiceEndpoints 返回值的键)
+const (
+	NamespaceDevice   = "http://www.onvif.org/ver10/device/wsdl"
+	NamespaceMedia    = "http://www.onvif.org/ver10/media/wsdl"
+	NamespaceMedia2   = "http://www.onvif.org/ver20/media/wsdl"
+	NamespaceEvents   = "http://www.onvif.org/ver10/events/wsdl"
+	NamespacePTZ      = "http://www.onvif.org/ver20/ptz/wsdl"
+	NamespaceImaging  = "http://www.onvif.org/ver20/imaging/wsdl"
+	NamespaceDeviceIO = "http://www.onvif.org/ver10/deviceIO/wsdl"
+)
+
+// 服务名称 (用于错误提示)
+var serviceNames = map[string]string{
+	NamespaceDevice:   "Device",
+	NamespaceMedia:    "Media",
+	NamespaceMedia2:   "Media2",
+	NamespaceEvents:   "Events",
+	NamespacePTZ:      "PTZ",
+	NamespaceImaging:  "Imaging",
+	NamespaceDeviceIO: "DeviceIO",
+}
+
+// 默认服务路径 (设备未通告服务地址时使用)
+var defaultServicePaths = map[string]string{
+	NamespaceDevice:  "/onvif/device_service",
+	NamespaceMedia:   "/onvif/media_service",
+	NamespaceEvents:  "/onvif/event_service",
+	NamespacePTZ:     "/onvif/ptz_service",
+	NamespaceImaging: "/onvif/imaging_service",
+}
+
+// ServiceEndpoint 已解析的服务地址
+type ServiceEndpoint struct {
+	Namespace string
+	XAddr     string
+	Version   OnvifVersion
+}
+
+// 获取 (必要时解析) 指定命名空间的服务地址
+// 设备已通告服务列表但不含该服务时返回错误; 解析失败时退回默认路径
+func (c *Client) serviceAddr(ctx context.Context, namespace string) (string, error) {
+	endpoints, err := c.ServiceEndpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if ep, ok := endpoints[namespace]; ok {
+		return ep.XAddr, nil
+	}
+
+	if len(endpoints) > 0 {
+		return "", &UnsupportedServiceError{Namespace: namespace}
+	}
+
+	path, ok := defaultServicePaths[namespace]
+	if !ok {
+		return "", &UnsupportedServiceError{Namespace: namespace}
+	}
+
+	return c.baseURL() + path, nil
+}
+
+// ServiceEndpoints 返回设备通告的全部服务地址 (按命名空间索引)
+// 首次调用时依次尝试 GetServices 和 GetCapabilities, 结果在客户端生命周期内缓存
+// 返回缓存的副本, 调用方修改 map 不影响之后的调用
+func (c *Client) ServiceEndpoints(ctx context.Context) (map[string]ServiceEndpoint, error) {
+	c.servicesMu.Lock()
+	defer c.servicesMu.Unlock()
+
+	if c.services != nil {
+		return maps.Clone(c.services), nil
+	}
+
+	services, err := c.getServices(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		c.debugf("GetServices 失败, 尝试 GetCapabilities: %v\n", err)
+
+		services, err = c.getCapabilities(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			c.debugf("GetCapabilities 失败, 使用默认服务路径: %v\n", err)
+			services = nil
+		}
+	}
+
+	c.services = make(map[string]ServiceEndpoint)
+
+	// 设备服务的通告端口用于判断其它服务是否经过同一端口映射
+	for _, svc := range services {
+		if svc.Namespace == NamespaceDevice {
+			if u, err := url.Parse(svc.XAddr); err == nil {
+				c.advertisedPort = portOf(u)
+			}
+		}
+	}
+
+	for _, svc := range services {
+		if svc.XAddr == "" {
+			continue
+		}
+
+		xaddr := c.rewriteXAddr(svc.XAddr, c.advertisedPort)
+		c.services[svc.Namespace] = ServiceEndpoint{
+			Namespace: svc.Namespace,
+			XAddr:     xaddr,
+			Version:   svc.Version,
+		}
+
+		c.debugf("服务 %s (v%d.%d): %s\n", svc.Namespace, svc.Version.Major, svc.Version.Minor, xaddr)
+	}
+
+	return maps.Clone(c.services), nil
+}
+
+// 设备服务通告的端口, 服务地址尚未解析时为空
+func (c *Client) advertisedDevicePort() string {
+	c.servicesMu.Lock()
+	defer c.servicesMu.Unlock()
+
+	return c.advertisedPort
+}
+
+// 调用 GetServices 获取服务列表
+func (c *Client) getServices(ctx context.Context) ([]Service, error) {
+	respData, err := c.sendRequest(ctx, c.XAddr, &GetServices{IncludeCapability: false})
+	if err != nil {
+		return nil, err
+	}
+
+	var servicesResp GetServicesResponse
+	if err := soap.DecodeBody(respData, &servicesResp); err != nil {
+		return nil, fmt.Errorf("解析服务列表失败: %w", err)
+	}
+
+	services := servicesResp.Services
+	if len(services) == 0 {
+		return nil, fmt.Errorf("设备未返回任何服务")
+	}
+
+	return services, nil
+}
+
+// 调用 GetCapabilities 获取服务地址 (ONVIF 1.x 设备)
+func (c *Client) getCapabilities(ctx context.Context) ([]Service, error) {
+	respData, err := c.sendRequest(ctx, c.XAddr, &GetCapabilities{Category: "All"})
+	if err != nil {
+		return nil, err
+	}
+
+	var capResp GetCapabilitiesResponse
+	if err := soap.DecodeBody(respData, &capResp); err != nil {
+		return nil, fmt.Errorf("解析设备能力失败: %w", err)
+	}
+
+	caps := capResp.Capabilities
+	services := []Service{
+		{Namespace: NamespaceDevice, XAddr: caps.Device.XAddr},
+		{Namespace: NamespaceMedia, XAddr: caps.Media.XAddr},
+		{Namespace: NamespaceEvents, XAddr: caps.Events.XAddr},
+		{Namespace: NamespacePTZ, XAddr: caps.PTZ.XAddr},
+		{Namespace: NamespaceImaging, XAddr: caps.Imaging.XAddr},
+		{Namespace: NamespaceMedia2, XAddr: caps.Extension.Media2.XAddr},
+		{Namespace: NamespaceDeviceIO, XAddr: caps.Extension.DeviceIO.XAddr},
+	}
+
+	if caps.Device.XAddr == "" && caps.Media.XAddr == "" {
+		return nil, fmt.Errorf("设备未返回任何服务地址")
+	}
+
+	return services, nil
+}
+
+// 将设备通告的地址改写为客户端实际访问的主机 (NAT / 端口映射场景)
+// 与设备服务通告端口相同的地址映射到客户端连接端口, 其余端口保持不变
+func (c *Client) rewriteXAddr(xaddr, advertisedPort string) string {
+	u, err := url.Parse(xaddr)
+	if err != nil || u.Host == "" || u.Hostname() == c.Host {
+		return xaddr
+	}
+
+	port := portOf(u)
+	if advertisedPort == "" || port == advertisedPort {
+		port = strconv.Itoa(c.Port)
+		u.Scheme = c.scheme()
+	}
+
+	u.Host = net.JoinHostPort(c.Host, port)
+
+	return u.String()
+}
+
+// 客户端访问设备使用的协议
+func (c *Client) scheme() string {
+	if c.UseHTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+// 客户端访问设备使用的基础地址 (协议://主机:端口)
+func (c *Client) baseURL() string {
+	return c.scheme() + "://" + net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// 获取 URL 的端口, 未显式指定时按协议取默认端口
+func portOf(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
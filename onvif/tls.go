@@ -0,0 +1,298 @@
+package onvif
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TLSOptions HTTPS 证书校验选项
+//
+// 默认按系统 CA 校验设备证书和主机名。设置 Fingerprint 时只接受该指纹的证书;
+// 未指定 CAFile 且设置了 KnownHosts 时, 未通过 CA 校验的证书按首次信任 (TOFU) 处理:
+// 首次连接记录指纹, 之后证书变化则拒绝连接
+type TLSOptions struct {
+	CAFile      string      // 额外信任的 CA 证书 (PEM), 设置后只使用该文件校验
+	ClientCert  string      // 客户端证书 (PEM), 用于双向认证
+	ClientKey   string      // 客户端私钥 (PEM)
+	Insecure    bool        // 跳过所有证书校验 (不安全)
+	Fingerprint string      // 固定的证书 SHA-256 指纹
+	KnownHosts  *KnownHosts // 首次信任的指纹记录, 为 nil 时不启用
+}
+
+// CertificateError 设备 TLS 证书未通过校验
+type CertificateError struct {
+	Addr        string // 设备地址 (host:port)
+	Fingerprint string // 设备证书的 SHA-256 指纹
+	Expected    string // 配置或 known_hosts 中记录的指纹, 证书未被信任时为空
+	Err         error  // CA 校验失败的原因
+}
+
+func (e *CertificateError) Error() string {
+	if e.Expected != "" {
+		return fmt.Sprintf("%s 的证书指纹 %s 与记录的 %s 不符", e.Addr, e.Fingerprint, e.Expected)
+	}
+	return fmt.Sprintf("%s 的证书未通过校验 (指纹 %s): %v", e.Addr, e.Fingerprint, e.Err)
+}
+
+func (e *CertificateError) Unwrap() error {
+	return e.Err
+}
+
+// CertFingerprint 计算证书的 SHA-256 指纹 (sha256:小写十六进制)
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// NormalizeFingerprint 将指纹规范化为 sha256:小写十六进制
+// 接受带或不带 sha256: 前缀、以冒号分隔的大小写十六进制 (如 openssl x509 -fingerprint 的输出)
+func NormalizeFingerprint(fp string) (string, error) {
+	s := strings.TrimSpace(fp)
+	if prefix, rest, ok := strings.Cut(s, ":"); ok && strings.EqualFold(prefix, "sha256") {
+		s = rest
+	} else if prefix, rest, ok := strings.Cut(s, "="); ok && strings.Contains(strings.ToLower(prefix), "sha256") {
+		s = rest
+	}
+	s = strings.ToLower(strings.ReplaceAll(s, ":", ""))
+
+	if b, err := hex.DecodeString(s); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("无效的证书指纹: %s (需要 SHA-256 十六进制)", fp)
+	}
+
+	return "sha256:" + s, nil
+}
+
+// KnownHosts 首次信任 (TOFU) 的证书指纹记录文件
+// 每行一条 "host:port sha256:指纹", 以 # 开头的行为注释
+type KnownHosts struct {
+	Path string
+
+	// OnAdd 首次记录某个地址的指纹后调用, 可用于提示用户
+	OnAdd func(addr, fingerprint string)
+
+	mu sync.Mutex
+}
+
+// Lookup 查找地址记录的指纹
+func (k *KnownHosts) Lookup(addr string) (string, bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.lookup(addr)
+}
+
+func (k *KnownHosts) lookup(addr string) (string, bool, error) {
+	f, err := os.Open(k.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("读取 known_hosts 失败: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == addr {
+			return fields[1], true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", false, fmt.Errorf("读取 known_hosts 失败: %w", err)
+	}
+
+	return "", false, nil
+}
+
+// 首次信任: 地址未记录时写入指纹, 已记录时返回记录的指纹
+func (k *KnownHosts) trust(addr, fingerprint string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	known, ok, err := k.lookup(addr)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return known, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.Path), 0700); err != nil {
+		return "", fmt.Errorf("创建 known_hosts 目录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(k.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", fmt.Errorf("写入 known_hosts 失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s %s\n", addr, fingerprint); err != nil {
+		return "", fmt.Errorf("写入 known_hosts 失败: %w", err)
+	}
+
+	if k.OnAdd != nil {
+		k.OnAdd(addr, fingerprint)
+	}
+
+	return fingerprint, nil
+}
+
+// 证书校验器, 按连接地址校验设备证书
+type tlsVerifier struct {
+	roots       *x509.CertPool // 为 nil 时使用系统 CA
+	fingerprint string
+	knownHosts  *KnownHosts // 为 nil 时不启用首次信任
+}
+
+// 校验设备证书: 固定指纹 > CA 校验 > 首次信任
+func (v *tlsVerifier) verify(addr, host string, cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return &CertificateError{Addr: addr, Err: errors.New("设备未提供证书")}
+	}
+
+	leaf := cs.PeerCertificates[0]
+	fp := CertFingerprint(leaf)
+
+	if v.fingerprint != "" {
+		if fp != v.fingerprint {
+			return &CertificateError{Addr: addr, Fingerprint: fp, Expected: v.fingerprint}
+		}
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, chainErr := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         v.roots,
+		Intermediates: intermediates,
+	})
+	if chainErr == nil {
+		return nil
+	}
+
+	if v.knownHosts == nil {
+		return &CertificateError{Addr: addr, Fingerprint: fp, Err: chainErr}
+	}
+
+	known, err := v.knownHosts.trust(addr, fp)
+	if err != nil {
+		return err
+	}
+	if known != fp {
+		return &CertificateError{Addr: addr, Fingerprint: fp, Expected: known}
+	}
+
+	return nil
+}
+
+// 根据校验选项配置 Transport 的 TLS
+func configureTLS(transport *http.Transport, opts TLSOptions) error {
+	base := &tls.Config{}
+
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		if opts.ClientCert == "" || opts.ClientKey == "" {
+			return fmt.Errorf("客户端证书和私钥必须同时指定")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		base.Certificates = []tls.Certificate{cert}
+	}
+
+	if opts.Insecure {
+		base.InsecureSkipVerify = true
+		transport.TLSClientConfig = base
+		return nil
+	}
+
+	verifier := &tlsVerifier{}
+
+	if opts.CAFile != "" {
+		data, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("CA 文件中没有有效的 PEM 证书: %s", opts.CAFile)
+		}
+		base.RootCAs = pool
+		verifier.roots = pool
+	} else {
+		verifier.knownHosts = opts.KnownHosts
+	}
+
+	if opts.Fingerprint != "" {
+		fp, err := NormalizeFingerprint(opts.Fingerprint)
+		if err != nil {
+			return err
+		}
+		verifier.fingerprint = fp
+	}
+
+	// 经 HTTP 代理的连接不经过 DialTLSContext, 使用严格的 CA 校验
+	transport.TLSClientConfig = base
+
+	// 直连时按连接地址校验, 以支持固定指纹和首次信任
+	dial := transport.DialContext
+	handshakeTimeout := transport.TLSHandshakeTimeout
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := base.Clone()
+		cfg.ServerName = host
+		cfg.InsecureSkipVerify = true // 由 VerifyConnection 校验
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifier.verify(addr, host, cs)
+		}
+
+		hsCtx := ctx
+		if handshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			hsCtx, cancel = context.WithTimeout(ctx, handshakeTimeout)
+			defer cancel()
+		}
+
+		conn := tls.Client(raw, cfg)
+		if err := conn.HandshakeContext(hsCtx); err != nil {
+			raw.Close()
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	return nil
+}
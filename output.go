@@ -0,0 +1,1354 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"onvifctl/onvif"
+)
+
+// 以下为 --output json/yaml/csv 的输出结构, 字段名是对外约定的 schema (见 README), 只增不改
+// 时间统一为 RFC 3339 格式的 UTC 时间
+
+// info 命令输出
+type deviceInfoOutput struct {
+	Manufacturer    string `json:"manufacturer" yaml:"manufacturer"`
+	Model           string `json:"model" yaml:"model"`
+	FirmwareVersion string `json:"firmwareVersion" yaml:"firmwareVersion"`
+	SerialNumber    string `json:"serialNumber" yaml:"serialNumber"`
+	HardwareID      string `json:"hardwareId" yaml:"hardwareId"`
+	TimeType        string `json:"timeType,omitempty" yaml:"timeType,omitempty"`
+	DeviceTime      string `json:"deviceTime,omitempty" yaml:"deviceTime,omitempty"`
+}
+
+// 媒体配置 (Profile)
+type profileOutput struct {
+	Index int    `json:"index" yaml:"index"`
+	Name  string `json:"name" yaml:"name"`
+	Token string `json:"token" yaml:"token"`
+}
+
+// stream 命令输出
+type streamOutput struct {
+	ProfileIndex int             `json:"profileIndex" yaml:"profileIndex"`
+	ProfileName  string          `json:"profileName" yaml:"profileName"`
+	ProfileToken string          `json:"profileToken" yaml:"profileToken"`
+	Protocol     string          `json:"protocol" yaml:"protocol"`
+	URI          string          `json:"uri" yaml:"uri"`
+	Profiles     []profileOutput `json:"profiles" yaml:"profiles" csv:"-"`
+}
+
+// ptz list 输出 (每个预置位一项), 设备未返回位置时不含 pan/tilt/zoom
+type presetOutput struct {
+	Token string   `json:"token" yaml:"token"`
+	Name  string   `json:"name" yaml:"name"`
+	Pan   *float64 `json:"pan,omitempty" yaml:"pan,omitempty"`
+	Tilt  *float64 `json:"tilt,omitempty" yaml:"tilt,omitempty"`
+	Zoom  *float64 `json:"zoom,omitempty" yaml:"zoom,omitempty"`
+
+	// 设备返回的坐标空间 URI, 未返回时省略
+	PanTiltSpace string `json:"panTiltSpace,omitempty" yaml:"panTiltSpace,omitempty"`
+	ZoomSpace    string `json:"zoomSpace,omitempty" yaml:"zoomSpace,omitempty"`
+}
+
+// ptz preset import 输出 (每个预置位一项), result 为 created、updated、removed 或 skipped
+type presetImportOutput struct {
+	Name   string `json:"name" yaml:"name"`
+	Token  string `json:"token,omitempty" yaml:"token,omitempty"`
+	Result string `json:"result" yaml:"result"`
+}
+
+// ptz move/absolute/relative/stop/goto/setpreset、ptz home、ptz tour start/stop/pause/remove 和 ptz aux 输出
+// move 时 pan/tilt/zoom 为速度, absolute/relative 时为坐标或位移
+type ptzActionOutput struct {
+	Action         string   `json:"action" yaml:"action"`
+	ProfileToken   string   `json:"profileToken" yaml:"profileToken"`
+	PresetToken    string   `json:"presetToken,omitempty" yaml:"presetToken,omitempty"`
+	PresetName     string   `json:"presetName,omitempty" yaml:"presetName,omitempty"`
+	TourToken      string   `json:"tourToken,omitempty" yaml:"tourToken,omitempty"`
+	TourName       string   `json:"tourName,omitempty" yaml:"tourName,omitempty"`
+	AuxCommand     string   `json:"auxCommand,omitempty" yaml:"auxCommand,omitempty"`
+	AuxResponse    string   `json:"auxResponse,omitempty" yaml:"auxResponse,omitempty"`
+	Pan            *float64 `json:"pan,omitempty" yaml:"pan,omitempty"`
+	Tilt           *float64 `json:"tilt,omitempty" yaml:"tilt,omitempty"`
+	Zoom           *float64 `json:"zoom,omitempty" yaml:"zoom,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+	Space          string   `json:"space,omitempty" yaml:"space,omitempty"`
+	Speed          *float64 `json:"speed,omitempty" yaml:"speed,omitempty"`
+	ZoomSpeed      *float64 `json:"zoomSpeed,omitempty" yaml:"zoomSpeed,omitempty"`
+
+	Status *ptzStatusOutput `json:"status,omitempty" yaml:"status,omitempty" csv:"-"` // --wait 时为移动结束后的状态
+}
+
+// ptz tour list 输出 (每个巡航一项), 时长为 Go 时长格式 (如 1m30s)
+type tourOutput struct {
+	Token             string           `json:"token" yaml:"token"`
+	Name              string           `json:"name" yaml:"name"`
+	State             string           `json:"state" yaml:"state"`
+	AutoStart         bool             `json:"autoStart" yaml:"autoStart"`
+	Direction         string           `json:"direction,omitempty" yaml:"direction,omitempty"`
+	Random            bool             `json:"random" yaml:"random"`
+	RecurringTime     int              `json:"recurringTime,omitempty" yaml:"recurringTime,omitempty"`
+	RecurringDuration string           `json:"recurringDuration,omitempty" yaml:"recurringDuration,omitempty"`
+	Spots             []tourSpotOutput `json:"spots" yaml:"spots" csv:"-"`
+}
+
+// 巡航点, 目标为预置位 (presetToken/preset)、原点 (home) 或坐标 (pan/tilt/zoom) 之一
+type tourSpotOutput struct {
+	PresetToken string   `json:"presetToken,omitempty" yaml:"presetToken,omitempty"`
+	Preset      string   `json:"preset,omitempty" yaml:"preset,omitempty"`
+	Home        bool     `json:"home,omitempty" yaml:"home,omitempty"`
+	Pan         *float64 `json:"pan,omitempty" yaml:"pan,omitempty"`
+	Tilt        *float64 `json:"tilt,omitempty" yaml:"tilt,omitempty"`
+	Zoom        *float64 `json:"zoom,omitempty" yaml:"zoom,omitempty"`
+	Dwell       string   `json:"dwell,omitempty" yaml:"dwell,omitempty"`
+	Speed       *float64 `json:"speed,omitempty" yaml:"speed,omitempty"`
+	ZoomSpeed   *float64 `json:"zoomSpeed,omitempty" yaml:"zoomSpeed,omitempty"`
+}
+
+// ptz tour apply 输出 (每个巡航一项), result 为 created、updated 或 removed
+type tourApplyOutput struct {
+	Name   string `json:"name" yaml:"name"`
+	Token  string `json:"token" yaml:"token"`
+	Result string `json:"result" yaml:"result"`
+}
+
+// ptz run 输出 (正常结束时), snapshots 为保存的抓图文件
+type ptzRunOutput struct {
+	Script       string   `json:"script" yaml:"script"`
+	ProfileToken string   `json:"profileToken" yaml:"profileToken"`
+	Loops        int      `json:"loops" yaml:"loops"`
+	Steps        int      `json:"steps" yaml:"steps"`
+	Snapshots    []string `json:"snapshots" yaml:"snapshots"`
+}
+
+// ptz calibrate 输出: 型号的视场校准表, 视场为画面宽度/高度对应的相对移动量 (通用坐标空间)
+type calibrationOutput struct {
+	Manufacturer string           `json:"manufacturer" yaml:"manufacturer"`
+	Model        string           `json:"model" yaml:"model"`
+	Calibrated   time.Time        `json:"calibrated" yaml:"calibrated"`
+	File         string           `json:"file" yaml:"file"`
+	Points       []fovPointOutput `json:"points" yaml:"points" csv:"-"`
+}
+
+type fovPointOutput struct {
+	Zoom float64 `json:"zoom" yaml:"zoom"`
+	Pan  float64 `json:"pan" yaml:"pan"`
+	Tilt float64 `json:"tilt" yaml:"tilt"`
+}
+
+// 坐标空间及取值范围, kind 为空间类别 (如 absolutePanTilt、panTiltSpeed), 一维坐标空间不含 yMin/yMax
+type ptzSpaceOutput struct {
+	Kind string   `json:"kind" yaml:"kind"`
+	URI  string   `json:"uri" yaml:"uri"`
+	XMin float64  `json:"xMin" yaml:"xMin"`
+	XMax float64  `json:"xMax" yaml:"xMax"`
+	YMin *float64 `json:"yMin,omitempty" yaml:"yMin,omitempty"`
+	YMax *float64 `json:"yMax,omitempty" yaml:"yMax,omitempty"`
+}
+
+// ptz nodes 输出 (每个云台节点一项)
+type ptzNodeOutput struct {
+	Token                string           `json:"token" yaml:"token"`
+	Name                 string           `json:"name" yaml:"name"`
+	MaxPresets           int              `json:"maxPresets" yaml:"maxPresets"`
+	HomeSupported        bool             `json:"homeSupported" yaml:"homeSupported"`
+	FixedHomePosition    *bool            `json:"fixedHomePosition,omitempty" yaml:"fixedHomePosition,omitempty"`
+	GeoMove              bool             `json:"geoMove" yaml:"geoMove"`
+	AuxiliaryCommands    []string         `json:"auxiliaryCommands" yaml:"auxiliaryCommands"`
+	MaxPresetTours       int              `json:"maxPresetTours" yaml:"maxPresetTours"`
+	PresetTourOperations []string         `json:"presetTourOperations" yaml:"presetTourOperations"`
+	Spaces               []ptzSpaceOutput `json:"spaces" yaml:"spaces" csv:"-"`
+}
+
+// ptz config 输出 (每个 PTZ 配置一项), spaces 和 timeoutMin/timeoutMax 来自 GetConfigurationOptions
+type ptzConfigOutput struct {
+	Token               string           `json:"token" yaml:"token"`
+	Name                string           `json:"name" yaml:"name"`
+	NodeToken           string           `json:"nodeToken" yaml:"nodeToken"`
+	UseCount            int              `json:"useCount" yaml:"useCount"`
+	DefaultTimeout      string           `json:"defaultTimeout,omitempty" yaml:"defaultTimeout,omitempty"`
+	DefaultPanTiltSpeed *float64         `json:"defaultPanTiltSpeed,omitempty" yaml:"defaultPanTiltSpeed,omitempty"`
+	DefaultZoomSpeed    *float64         `json:"defaultZoomSpeed,omitempty" yaml:"defaultZoomSpeed,omitempty"`
+	TimeoutMin          string           `json:"timeoutMin,omitempty" yaml:"timeoutMin,omitempty"`
+	TimeoutMax          string           `json:"timeoutMax,omitempty" yaml:"timeoutMax,omitempty"`
+	Limits              []ptzSpaceOutput `json:"limits" yaml:"limits" csv:"-"` // kind 为 panTiltLimits 或 zoomLimits
+	Spaces              []ptzSpaceOutput `json:"spaces" yaml:"spaces" csv:"-"`
+}
+
+// ptz aux (不带参数) 输出: 节点支持的辅助命令
+type ptzAuxListOutput struct {
+	NodeToken string   `json:"nodeToken" yaml:"nodeToken"`
+	NodeName  string   `json:"nodeName" yaml:"nodeName"`
+	Commands  []string `json:"commands" yaml:"commands"`
+}
+
+// ptz status 输出, 设备未返回位置时不含 pan/tilt/zoom
+type ptzStatusOutput struct {
+	ProfileToken  string   `json:"profileToken" yaml:"profileToken"`
+	Pan           *float64 `json:"pan,omitempty" yaml:"pan,omitempty"`
+	Tilt          *float64 `json:"tilt,omitempty" yaml:"tilt,omitempty"`
+	Zoom          *float64 `json:"zoom,omitempty" yaml:"zoom,omitempty"`
+	PanTiltStatus string   `json:"panTiltStatus" yaml:"panTiltStatus"`
+	ZoomStatus    string   `json:"zoomStatus" yaml:"zoomStatus"`
+	UtcTime       string   `json:"utcTime,omitempty" yaml:"utcTime,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// imaging get/set 输出, 设备未返回的项为空; options 为 GetOptions 声明的取值范围和模式 (获取失败时为空)
+type imagingOutput struct {
+	SourceToken    string   `json:"sourceToken" yaml:"sourceToken"`
+	Brightness     *float64 `json:"brightness,omitempty" yaml:"brightness,omitempty"`
+	Saturation     *float64 `json:"saturation,omitempty" yaml:"saturation,omitempty"`
+	Contrast       *float64 `json:"contrast,omitempty" yaml:"contrast,omitempty"`
+	Sharpness      *float64 `json:"sharpness,omitempty" yaml:"sharpness,omitempty"`
+	ExposureMode   string   `json:"exposureMode,omitempty" yaml:"exposureMode,omitempty"`
+	IrCutFilter    string   `json:"irCutFilter,omitempty" yaml:"irCutFilter,omitempty"`
+	WDRMode        string   `json:"wdrMode,omitempty" yaml:"wdrMode,omitempty"`
+	WDRLevel       *float64 `json:"wdrLevel,omitempty" yaml:"wdrLevel,omitempty"`
+	BacklightMode  string   `json:"backlightMode,omitempty" yaml:"backlightMode,omitempty"`
+	BacklightLevel *float64 `json:"backlightLevel,omitempty" yaml:"backlightLevel,omitempty"`
+
+	Options *imagingOptionsOutput `json:"options,omitempty" yaml:"options,omitempty" csv:"-"`
+}
+
+type imagingOptionsOutput struct {
+	Brightness       *rangeOutput `json:"brightness,omitempty" yaml:"brightness,omitempty"`
+	Saturation       *rangeOutput `json:"saturation,omitempty" yaml:"saturation,omitempty"`
+	Contrast         *rangeOutput `json:"contrast,omitempty" yaml:"contrast,omitempty"`
+	Sharpness        *rangeOutput `json:"sharpness,omitempty" yaml:"sharpness,omitempty"`
+	ExposureModes    []string     `json:"exposureModes,omitempty" yaml:"exposureModes,omitempty"`
+	IrCutFilterModes []string     `json:"irCutFilterModes,omitempty" yaml:"irCutFilterModes,omitempty"`
+	WDRModes         []string     `json:"wdrModes,omitempty" yaml:"wdrModes,omitempty"`
+	WDRLevel         *rangeOutput `json:"wdrLevel,omitempty" yaml:"wdrLevel,omitempty"`
+	BacklightModes   []string     `json:"backlightModes,omitempty" yaml:"backlightModes,omitempty"`
+	BacklightLevel   *rangeOutput `json:"backlightLevel,omitempty" yaml:"backlightLevel,omitempty"`
+}
+
+type rangeOutput struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+// imaging focus status 输出, 设备未返回对焦状态时 position 为空
+type focusStatusOutput struct {
+	SourceToken   string   `json:"sourceToken" yaml:"sourceToken"`
+	Position      *float64 `json:"position,omitempty" yaml:"position,omitempty"`
+	MoveStatus    string   `json:"moveStatus,omitempty" yaml:"moveStatus,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+	AutoFocusMode string   `json:"autoFocusMode,omitempty" yaml:"autoFocusMode,omitempty"`
+}
+
+// imaging focus options 输出, 设备不支持的移动方式为空
+type focusOptionsOutput struct {
+	SourceToken      string       `json:"sourceToken" yaml:"sourceToken"`
+	AbsolutePosition *rangeOutput `json:"absolutePosition,omitempty" yaml:"absolutePosition,omitempty"`
+	AbsoluteSpeed    *rangeOutput `json:"absoluteSpeed,omitempty" yaml:"absoluteSpeed,omitempty"`
+	RelativeDistance *rangeOutput `json:"relativeDistance,omitempty" yaml:"relativeDistance,omitempty"`
+	RelativeSpeed    *rangeOutput `json:"relativeSpeed,omitempty" yaml:"relativeSpeed,omitempty"`
+	ContinuousSpeed  *rangeOutput `json:"continuousSpeed,omitempty" yaml:"continuousSpeed,omitempty"`
+	AutoFocusModes   []string     `json:"autoFocusModes,omitempty" yaml:"autoFocusModes,omitempty"`
+}
+
+// imaging focus 操作类命令输出
+type focusActionOutput struct {
+	Action      string   `json:"action" yaml:"action"`
+	SourceToken string   `json:"sourceToken" yaml:"sourceToken"`
+	Position    *float64 `json:"position,omitempty" yaml:"position,omitempty"`
+	Distance    *float64 `json:"distance,omitempty" yaml:"distance,omitempty"`
+	Speed       *float64 `json:"speed,omitempty" yaml:"speed,omitempty"`
+	Duration    string   `json:"duration,omitempty" yaml:"duration,omitempty"`
+
+	Status *focusStatusOutput `json:"status,omitempty" yaml:"status,omitempty" csv:"-"` // --wait / --once 时为对焦停止后的状态
+}
+
+// snapshot 命令输出
+type snapshotOutput struct {
+	File         string `json:"file" yaml:"file"`
+	ProfileName  string `json:"profileName" yaml:"profileName"`
+	ProfileToken string `json:"profileToken" yaml:"profileToken"`
+	Size         int    `json:"size" yaml:"size"`
+}
+
+// config get-video / set-video 输出 (每个编码配置一项), 比特率单位 kbps
+type videoConfigOutput struct {
+	Token     string  `json:"token" yaml:"token"`
+	Name      string  `json:"name" yaml:"name"`
+	Encoding  string  `json:"encoding" yaml:"encoding"`
+	Width     int     `json:"width" yaml:"width"`
+	Height    int     `json:"height" yaml:"height"`
+	Quality   float64 `json:"quality" yaml:"quality"`
+	FrameRate int     `json:"frameRateLimit" yaml:"frameRateLimit"`
+	Bitrate   int     `json:"bitrateLimit" yaml:"bitrateLimit"`
+}
+
+// config get-network 输出 (每个网络接口一项), 地址格式为 address/prefix
+type networkInterfaceOutput struct {
+	Token       string   `json:"token" yaml:"token"`
+	Name        string   `json:"name" yaml:"name"`
+	Enabled     bool     `json:"enabled" yaml:"enabled"`
+	MACAddress  string   `json:"macAddress" yaml:"macAddress"`
+	MTU         int      `json:"mtu" yaml:"mtu"`
+	IPv4Enabled bool     `json:"ipv4Enabled" yaml:"ipv4Enabled"`
+	DHCP        bool     `json:"dhcp" yaml:"dhcp"`
+	Addresses   []string `json:"addresses" yaml:"addresses"`
+	DHCPAddress string   `json:"dhcpAddress,omitempty" yaml:"dhcpAddress,omitempty"`
+}
+
+// time get 输出, offsetSeconds 为设备时间减本机时间
+type systemTimeOutput struct {
+	TimeType      string `json:"timeType" yaml:"timeType"`
+	UTC           string `json:"utc" yaml:"utc"`
+	OffsetSeconds int64  `json:"offsetSeconds" yaml:"offsetSeconds"`
+}
+
+// time sync 输出
+type timeSyncOutput struct {
+	Time string `json:"time" yaml:"time"`
+}
+
+// time set-ntp 输出
+type ntpOutput struct {
+	NTPServer string `json:"ntpServer" yaml:"ntpServer"`
+}
+
+// events 输出 (每个事件一项)
+type eventOutput struct {
+	Time      string            `json:"time" yaml:"time"`
+	Topic     string            `json:"topic" yaml:"topic"`
+	Operation string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Source    map[string]string `json:"source" yaml:"source"`
+	Data      map[string]string `json:"data" yaml:"data"`
+}
+
+// batch import 输出 (每个设备一项)
+type batchDeviceOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	UseHTTPS bool   `json:"useHttps" yaml:"useHttps"`
+	Auth     string `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+
+// batch info 输出 (每个设备一项), 失败时 ok 为 false 且 error 为原因
+type batchInfoOutput struct {
+	Name            string `json:"name" yaml:"name"`
+	Host            string `json:"host" yaml:"host"`
+	Port            int    `json:"port" yaml:"port"`
+	OK              bool   `json:"ok" yaml:"ok"`
+	Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+	Manufacturer    string `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+	Model           string `json:"model,omitempty" yaml:"model,omitempty"`
+	FirmwareVersion string `json:"firmwareVersion,omitempty" yaml:"firmwareVersion,omitempty"`
+	SerialNumber    string `json:"serialNumber,omitempty" yaml:"serialNumber,omitempty"`
+}
+
+// batch snapshot 输出 (每个设备一项)
+type batchSnapshotOutput struct {
+	Name  string `json:"name" yaml:"name"`
+	Host  string `json:"host" yaml:"host"`
+	Port  int    `json:"port" yaml:"port"`
+	OK    bool   `json:"ok" yaml:"ok"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	File  string `json:"file,omitempty" yaml:"file,omitempty"`
+	Size  int    `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// batch sync-time 输出 (每个设备一项), time 为写入设备的时间
+type batchSyncTimeOutput struct {
+	Name  string `json:"name" yaml:"name"`
+	Host  string `json:"host" yaml:"host"`
+	Port  int    `json:"port" yaml:"port"`
+	OK    bool   `json:"ok" yaml:"ok"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	Time  string `json:"time,omitempty" yaml:"time,omitempty"`
+}
+
+// ctx list 输出 (每个上下文一项), 不包含密码
+type contextOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Current  bool   `json:"current" yaml:"current"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Username string `json:"username" yaml:"username"`
+	UseHTTPS bool   `json:"useHttps" yaml:"useHttps"`
+	Auth     string `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+
+// 格式化为 RFC 3339 UTC 时间, 零值返回空串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func newDeviceInfoOutput(info *onvif.DeviceInformation, dt *onvif.SystemDateAndTime) deviceInfoOutput {
+	out := deviceInfoOutput{
+		Manufacturer:    info.Manufacturer,
+		Model:           info.Model,
+		FirmwareVersion: info.FirmwareVersion,
+		SerialNumber:    info.SerialNumber,
+		HardwareID:      info.HardwareId,
+	}
+	if dt != nil {
+		out.TimeType = dt.DateTimeType
+		out.DeviceTime = formatTime(dt.UTC())
+	}
+	return out
+}
+
+func newStreamOutput(index int, profile onvif.Profile, uri *onvif.StreamURI, profiles []onvif.Profile) streamOutput {
+	out := streamOutput{
+		ProfileIndex: index,
+		ProfileName:  profile.Name,
+		ProfileToken: profile.Token,
+		Protocol:     uri.Protocol,
+		URI:          uri.URI,
+		Profiles:     make([]profileOutput, len(profiles)),
+	}
+	for i, p := range profiles {
+		out.Profiles[i] = profileOutput{Index: i, Name: p.Name, Token: p.Token}
+	}
+	return out
+}
+
+func newPresetOutputs(presets []onvif.PTZPreset) []presetOutput {
+	out := make([]presetOutput, len(presets))
+	for i, preset := range presets {
+		out[i] = presetOutput{Token: preset.Token, Name: preset.Name}
+		if pos := preset.Position; pos != nil {
+			pan, tilt, zoom := pos.PanTilt.X, pos.PanTilt.Y, pos.Zoom.X
+			out[i].Pan, out[i].Tilt, out[i].Zoom = &pan, &tilt, &zoom
+			out[i].PanTiltSpace, out[i].ZoomSpace = pos.PanTilt.Space, pos.Zoom.Space
+		}
+	}
+	return out
+}
+
+func newPTZStatusOutput(profileToken string, status *onvif.PTZStatus) ptzStatusOutput {
+	out := ptzStatusOutput{
+		ProfileToken:  profileToken,
+		PanTiltStatus: status.MoveStatus.PanTilt,
+		ZoomStatus:    status.MoveStatus.Zoom,
+		UtcTime:       status.UtcTime,
+		Error:         status.Error,
+	}
+	if t, err := time.Parse(time.RFC3339, status.UtcTime); err == nil {
+		out.UtcTime = formatTime(t)
+	}
+	if pos := status.Position; pos != nil {
+		pan, tilt, zoom := pos.PanTilt.X, pos.PanTilt.Y, pos.Zoom.X
+		out.Pan, out.Tilt, out.Zoom = &pan, &tilt, &zoom
+	}
+	return out
+}
+
+// 设备返回的 xs:duration 转为 Go 时长格式, 无法解析时保留原值
+func formatXSDDuration(s string) string {
+	if s == "" {
+		return ""
+	}
+	d, err := onvif.ParseXSDDuration(s)
+	if err != nil {
+		return s
+	}
+	return d.String()
+}
+
+// presets 用于显示巡航点对应的预置位名称
+func newTourOutputs(tours []onvif.PresetTour, presets []onvif.PTZPreset) []tourOutput {
+	names := make(map[string]string, len(presets))
+	for _, p := range presets {
+		names[p.Token] = p.Name
+	}
+
+	out := make([]tourOutput, len(tours))
+	for i, tour := range tours {
+		cond := tour.StartingCondition
+		out[i] = tourOutput{
+			Token:             tour.Token,
+			Name:              tour.Name,
+			State:             tour.Status.State,
+			AutoStart:         tour.AutoStart,
+			Direction:         cond.Direction,
+			Random:            cond.RandomPresetOrder,
+			RecurringTime:     cond.RecurringTime,
+			RecurringDuration: formatXSDDuration(cond.RecurringDuration),
+			Spots:             make([]tourSpotOutput, len(tour.TourSpots)),
+		}
+
+		for j, spot := range tour.TourSpots {
+			detail := spot.PresetDetail
+			so := tourSpotOutput{
+				PresetToken: detail.PresetToken,
+				Preset:      names[detail.PresetToken],
+				Home:        detail.Home,
+				Dwell:       formatXSDDuration(spot.StayTime),
+			}
+			if pos := detail.PTZPosition; pos != nil {
+				if pt := pos.PanTilt; pt != nil {
+					so.Pan, so.Tilt = &pt.X, &pt.Y
+				}
+				if z := pos.Zoom; z != nil {
+					so.Zoom = &z.X
+				}
+			}
+			if speed := spot.Speed; speed != nil {
+				if pt := speed.PanTilt; pt != nil {
+					so.Speed = &pt.X
+				}
+				if z := speed.Zoom; z != nil {
+					so.ZoomSpeed = &z.X
+				}
+			}
+			out[i].Spots[j] = so
+		}
+	}
+	return out
+}
+
+func newPTZSpace2DOutput(kind string, space onvif.Space2DDescription) ptzSpaceOutput {
+	yMin, yMax := space.YRange.Min, space.YRange.Max
+	return ptzSpaceOutput{
+		Kind: kind,
+		URI:  space.URI,
+		XMin: space.XRange.Min,
+		XMax: space.XRange.Max,
+		YMin: &yMin,
+		YMax: &yMax,
+	}
+}
+
+func newPTZSpace1DOutput(kind string, space onvif.Space1DDescription) ptzSpaceOutput {
+	return ptzSpaceOutput{Kind: kind, URI: space.URI, XMin: space.XRange.Min, XMax: space.XRange.Max}
+}
+
+func newPTZSpaceOutputs(spaces onvif.PTZSpaces) []ptzSpaceOutput {
+	out := []ptzSpaceOutput{}
+	for _, group := range []struct {
+		kind   string
+		spaces []onvif.Space2DDescription
+	}{
+		{"absolutePanTilt", spaces.AbsolutePanTiltPositionSpace},
+		{"relativePanTilt", spaces.RelativePanTiltTranslationSpace},
+		{"continuousPanTilt", spaces.ContinuousPanTiltVelocitySpace},
+	} {
+		for _, space := range group.spaces {
+			out = append(out, newPTZSpace2DOutput(group.kind, space))
+		}
+	}
+	for _, group := range []struct {
+		kind   string
+		spaces []onvif.Space1DDescription
+	}{
+		{"absoluteZoom", spaces.AbsoluteZoomPositionSpace},
+		{"relativeZoom", spaces.RelativeZoomTranslationSpace},
+		{"continuousZoom", spaces.ContinuousZoomVelocitySpace},
+		{"panTiltSpeed", spaces.PanTiltSpeedSpace},
+		{"zoomSpeed", spaces.ZoomSpeedSpace},
+	} {
+		for _, space := range group.spaces {
+			out = append(out, newPTZSpace1DOutput(group.kind, space))
+		}
+	}
+	return out
+}
+
+func newPTZNodeOutputs(nodes []onvif.PTZNode) []ptzNodeOutput {
+	out := make([]ptzNodeOutput, len(nodes))
+	for i, node := range nodes {
+		out[i] = ptzNodeOutput{
+			Token:                node.Token,
+			Name:                 node.Name,
+			MaxPresets:           node.MaximumNumberOfPresets,
+			HomeSupported:        node.HomeSupported,
+			FixedHomePosition:    node.FixedHomePosition,
+			GeoMove:              node.GeoMove,
+			AuxiliaryCommands:    node.AuxiliaryCommands,
+			PresetTourOperations: []string{},
+			Spaces:               newPTZSpaceOutputs(node.SupportedPTZSpaces),
+		}
+		if out[i].AuxiliaryCommands == nil {
+			out[i].AuxiliaryCommands = []string{}
+		}
+		if ext := node.Extension; ext != nil && ext.SupportedPresetTour != nil {
+			out[i].MaxPresetTours = ext.SupportedPresetTour.MaximumNumberOfPresetTours
+			out[i].PresetTourOperations = append(out[i].PresetTourOperations, ext.SupportedPresetTour.PTZPresetTourOperation...)
+		}
+	}
+	return out
+}
+
+// options 为 nil 时 (获取失败) 不含 spaces 和 timeoutMin/timeoutMax
+func newPTZConfigOutput(config onvif.PTZConfiguration, options *onvif.PTZConfigurationOptions) ptzConfigOutput {
+	out := ptzConfigOutput{
+		Token:          config.Token,
+		Name:           config.Name,
+		NodeToken:      config.NodeToken,
+		UseCount:       config.UseCount,
+		DefaultTimeout: formatXSDDuration(config.DefaultPTZTimeout),
+		Limits:         []ptzSpaceOutput{},
+		Spaces:         []ptzSpaceOutput{},
+	}
+
+	if speed := config.DefaultPTZSpeed; speed != nil {
+		if pt := speed.PanTilt; pt != nil {
+			out.DefaultPanTiltSpeed = &pt.X
+		}
+		if z := speed.Zoom; z != nil {
+			out.DefaultZoomSpeed = &z.X
+		}
+	}
+	if limits := config.PanTiltLimits; limits != nil {
+		out.Limits = append(out.Limits, newPTZSpace2DOutput("panTiltLimits", limits.Range))
+	}
+	if limits := config.ZoomLimits; limits != nil {
+		out.Limits = append(out.Limits, newPTZSpace1DOutput("zoomLimits", limits.Range))
+	}
+
+	if options != nil {
+		out.TimeoutMin = formatXSDDuration(options.PTZTimeout.Min)
+		out.TimeoutMax = formatXSDDuration(options.PTZTimeout.Max)
+		out.Spaces = newPTZSpaceOutputs(options.Spaces)
+	}
+
+	return out
+}
+
+func newRangeOutput(r *onvif.FloatRange) *rangeOutput {
+	if r == nil {
+		return nil
+	}
+	return &rangeOutput{Min: r.Min, Max: r.Max}
+}
+
+func newFocusStatusOutput(source string, status *onvif.ImagingStatus, mode string) focusStatusOutput {
+	out := focusStatusOutput{SourceToken: source, AutoFocusMode: mode}
+	if fs := status.FocusStatus; fs != nil {
+		position := fs.Position
+		out.Position, out.MoveStatus, out.Error = &position, fs.MoveStatus, fs.Error
+	}
+	return out
+}
+
+func newFocusOptionsOutput(source string, move *onvif.MoveOptions, options *onvif.ImagingOptions) focusOptionsOutput {
+	out := focusOptionsOutput{SourceToken: source}
+	if a := move.Absolute; a != nil {
+		out.AbsolutePosition, out.AbsoluteSpeed = newRangeOutput(&a.Position), newRangeOutput(a.Speed)
+	}
+	if r := move.Relative; r != nil {
+		out.RelativeDistance, out.RelativeSpeed = newRangeOutput(&r.Distance), newRangeOutput(r.Speed)
+	}
+	if c := move.Continuous; c != nil {
+		out.ContinuousSpeed = newRangeOutput(&c.Speed)
+	}
+	if options != nil && options.Focus != nil {
+		out.AutoFocusModes = options.Focus.AutoFocusModes
+	}
+	return out
+}
+
+func newImagingOutput(source string, settings *onvif.ImagingSettings, options *onvif.ImagingOptions) imagingOutput {
+	out := imagingOutput{
+		SourceToken: source,
+		Brightness:  settings.Brightness,
+		Saturation:  settings.ColorSaturation,
+		Contrast:    settings.Contrast,
+		Sharpness:   settings.Sharpness,
+		IrCutFilter: settings.IrCutFilter,
+	}
+	if e := settings.Exposure; e != nil {
+		out.ExposureMode = e.Mode
+	}
+	if w := settings.WideDynamicRange; w != nil {
+		out.WDRMode, out.WDRLevel = w.Mode, w.Level
+	}
+	if b := settings.BacklightCompensation; b != nil {
+		out.BacklightMode, out.BacklightLevel = b.Mode, b.Level
+	}
+
+	if options == nil {
+		return out
+	}
+
+	opts := &imagingOptionsOutput{
+		Brightness:       newRangeOutput(options.Brightness),
+		Saturation:       newRangeOutput(options.ColorSaturation),
+		Contrast:         newRangeOutput(options.Contrast),
+		Sharpness:        newRangeOutput(options.Sharpness),
+		IrCutFilterModes: options.IrCutFilterModes,
+	}
+	if e := options.Exposure; e != nil {
+		opts.ExposureModes = e.Mode
+	}
+	if w := options.WideDynamicRange; w != nil {
+		opts.WDRModes, opts.WDRLevel = w.Mode, newRangeOutput(w.Level)
+	}
+	if b := options.BacklightCompensation; b != nil {
+		opts.BacklightModes, opts.BacklightLevel = b.Mode, newRangeOutput(b.Level)
+	}
+	out.Options = opts
+
+	return out
+}
+
+func newVideoConfigOutput(config onvif.VideoEncoderConfiguration) videoConfigOutput {
+	return videoConfigOutput{
+		Token:     config.Token,
+		Name:      config.Name,
+		Encoding:  config.Encoding,
+		Width:     config.Resolution.Width,
+		Height:    config.Resolution.Height,
+		Quality:   config.Quality,
+		FrameRate: config.RateControl.FrameRateLimit,
+		Bitrate:   config.RateControl.BitrateLimit,
+	}
+}
+
+func newVideoConfigOutputs(configs []onvif.VideoEncoderConfiguration) []videoConfigOutput {
+	out := make([]videoConfigOutput, len(configs))
+	for i, config := range configs {
+		out[i] = newVideoConfigOutput(config)
+	}
+	return out
+}
+
+func newNetworkInterfaceOutputs(interfaces []onvif.NetworkInterface) []networkInterfaceOutput {
+	out := make([]networkInterfaceOutput, len(interfaces))
+	for i, iface := range interfaces {
+		out[i] = networkInterfaceOutput{
+			Token:       iface.Token,
+			Name:        iface.Info.Name,
+			Enabled:     iface.Enabled,
+			MACAddress:  iface.Info.HwAddress,
+			MTU:         iface.Info.MTU,
+			IPv4Enabled: iface.IPv4.Enabled,
+			DHCP:        iface.IPv4.DHCP,
+			Addresses:   []string{},
+		}
+		for _, addr := range iface.IPv4.Manual {
+			out[i].Addresses = append(out[i].Addresses, fmt.Sprintf("%s/%d", addr.Address, addr.PrefixLength))
+		}
+		if addr := iface.IPv4.FromDHCP; addr != nil && addr.Address != "" {
+			out[i].DHCPAddress = fmt.Sprintf("%s/%d", addr.Address, addr.PrefixLength)
+		}
+	}
+	return out
+}
+
+func newSystemTimeOutput(dt *onvif.SystemDateAndTime) systemTimeOutput {
+	return systemTimeOutput{
+		TimeType:      dt.DateTimeType,
+		UTC:           formatTime(dt.UTC()),
+		OffsetSeconds: int64(math.Round(time.Until(dt.UTC()).Seconds())),
+	}
+}
+
+func newEventOutput(msg onvif.NotificationMessage) eventOutput {
+	out := eventOutput{
+		Time:      msg.Message.UtcTime,
+		Topic:     msg.Topic.Value,
+		Operation: msg.Message.PropertyOperation,
+		Source:    make(map[string]string),
+		Data:      make(map[string]string),
+	}
+	for _, item := range msg.Message.Source.SimpleItem {
+		out.Source[item.Name] = item.Value
+	}
+	for _, item := range msg.Message.Data.SimpleItem {
+		out.Data[item.Name] = item.Value
+	}
+	return out
+}
+
+// 打印设备信息, dt 为 nil 时不显示时间
+func printDeviceInfo(info *onvif.DeviceInformation, dt *onvif.SystemDateAndTime) {
+	fmt.Println("=== 设备信息 ===")
+	fmt.Printf("制造商:       %s\n", info.Manufacturer)
+	fmt.Printf("型号:         %s\n", info.Model)
+	fmt.Printf("固件版本:     %s\n", info.FirmwareVersion)
+	fmt.Printf("序列号:       %s\n", info.SerialNumber)
+	fmt.Printf("硬件 ID:      %s\n", info.HardwareId)
+
+	if dt != nil {
+		fmt.Printf("时间类型:     %s\n", dt.DateTimeType)
+		fmt.Printf("设备时间:     %s\n", formatDeviceTime(dt))
+	}
+}
+
+// 打印设备时间及与本机时间的差异
+func printSystemTime(dt *onvif.SystemDateAndTime) {
+	fmt.Println("=== 设备时间 ===")
+	fmt.Printf("时间类型: %s\n", dt.DateTimeType)
+	fmt.Printf("设备时间: %s\n", formatDeviceTime(dt))
+
+	// 显示与系统时间的差异
+	diff := time.Since(dt.UTC())
+	fmt.Printf("时间差异: %.0f 秒\n", diff.Seconds())
+}
+
+// 格式化设备 UTC 时间
+func formatDeviceTime(dt *onvif.SystemDateAndTime) string {
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d UTC",
+		dt.UTCDateTime.Date.Year,
+		dt.UTCDateTime.Date.Month,
+		dt.UTCDateTime.Date.Day,
+		dt.UTCDateTime.Time.Hour,
+		dt.UTCDateTime.Time.Minute,
+		dt.UTCDateTime.Time.Second,
+	)
+}
+
+// 打印流地址和全部可用配置
+func printStreamURI(profile onvif.Profile, uri *onvif.StreamURI, profiles []onvif.Profile) {
+	fmt.Println("=== 视频流信息 ===")
+	fmt.Printf("配置名称:     %s\n", profile.Name)
+	fmt.Printf("配置 Token:   %s\n", profile.Token)
+	fmt.Printf("RTSP 地址:    %s\n", uri.URI)
+
+	fmt.Println("\n所有可用配置:")
+	for i, p := range profiles {
+		fmt.Printf("  [%d] %s (Token: %s)\n", i, p.Name, p.Token)
+	}
+}
+
+// 打印预置位列表
+func printPresets(presets []onvif.PTZPreset) {
+	fmt.Println("=== PTZ 预置位列表 ===")
+	if len(presets) == 0 {
+		fmt.Println("  (无预置位)")
+		return
+	}
+
+	for _, preset := range presets {
+		if pos := preset.Position; pos != nil {
+			fmt.Printf("  [%s] %s (水平 %g, 垂直 %g, 缩放 %g)\n", preset.Token, preset.Name, pos.PanTilt.X, pos.PanTilt.Y, pos.Zoom.X)
+		} else {
+			fmt.Printf("  [%s] %s\n", preset.Token, preset.Name)
+		}
+	}
+}
+
+// 打印预置位导入结果
+func printPresetImport(results []presetImportOutput) {
+	labels := map[string]string{
+		"created": "新建",
+		"updated": "更新",
+		"removed": "删除",
+		"skipped": "跳过",
+	}
+
+	fmt.Println("=== 预置位导入结果 ===")
+	for _, r := range results {
+		if r.Token != "" {
+			fmt.Printf("  %s  %s (Token: %s)\n", labels[r.Result], r.Name, r.Token)
+		} else {
+			fmt.Printf("  %s  %s\n", labels[r.Result], r.Name)
+		}
+	}
+}
+
+// 打印云台状态
+func printTours(tours []tourOutput) {
+	fmt.Println("=== PTZ 预置位巡航列表 ===")
+	if len(tours) == 0 {
+		fmt.Println("  (无巡航)")
+		return
+	}
+
+	for _, tour := range tours {
+		fmt.Printf("  [%s] %s\n", tour.Token, tour.Name)
+
+		details := []string{"状态: " + tour.State}
+		if tour.AutoStart {
+			details = append(details, "自动开始")
+		}
+		if tour.Random {
+			details = append(details, "随机顺序")
+		} else if tour.Direction != "" {
+			details = append(details, "方向: "+tour.Direction)
+		}
+		if tour.RecurringTime > 0 {
+			details = append(details, fmt.Sprintf("循环 %d 次", tour.RecurringTime))
+		}
+		if tour.RecurringDuration != "" {
+			details = append(details, "持续 "+tour.RecurringDuration)
+		}
+		fmt.Printf("      %s\n", strings.Join(details, ", "))
+
+		for i, spot := range tour.Spots {
+			var target string
+			switch {
+			case spot.Home:
+				target = "原点"
+			case spot.PresetToken != "" && spot.Preset != "" && spot.Preset != spot.PresetToken:
+				target = fmt.Sprintf("%s (Token: %s)", spot.Preset, spot.PresetToken)
+			case spot.PresetToken != "":
+				target = spot.PresetToken
+			default:
+				target = "坐标"
+				if spot.Pan != nil {
+					target += fmt.Sprintf(" 水平 %g, 垂直 %g", *spot.Pan, *spot.Tilt)
+				}
+				if spot.Zoom != nil {
+					target += fmt.Sprintf(" 缩放 %g", *spot.Zoom)
+				}
+			}
+
+			line := fmt.Sprintf("      %d. %s", i+1, target)
+			if spot.Dwell != "" {
+				line += ", 停留 " + spot.Dwell
+			}
+			if spot.Speed != nil {
+				line += fmt.Sprintf(", 速度 %g", *spot.Speed)
+			}
+			if spot.ZoomSpeed != nil {
+				line += fmt.Sprintf(", 缩放速度 %g", *spot.ZoomSpeed)
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+func printTourApply(results []tourApplyOutput) {
+	labels := map[string]string{
+		"created": "新建",
+		"updated": "更新",
+		"removed": "删除",
+	}
+
+	fmt.Println("=== 巡航应用结果 ===")
+	for _, r := range results {
+		fmt.Printf("  %s  %s (Token: %s)\n", labels[r.Result], r.Name, r.Token)
+	}
+}
+
+func newCalibrationOutput(c modelCalibration, file string) calibrationOutput {
+	out := calibrationOutput{
+		Manufacturer: c.Manufacturer,
+		Model:        c.Model,
+		Calibrated:   c.Calibrated,
+		File:         file,
+		Points:       make([]fovPointOutput, len(c.Points)),
+	}
+	for i, p := range c.Points {
+		out.Points[i] = fovPointOutput(p)
+	}
+	return out
+}
+
+func printCalibration(out calibrationOutput) {
+	fmt.Printf("✓ 已保存型号 %s %s 的视场校准表: %s\n", out.Manufacturer, out.Model, out.File)
+	for _, p := range out.Points {
+		fmt.Printf("  缩放 %g: 水平视场 %.4f, 垂直视场 %.4f\n", p.Zoom, p.Pan, p.Tilt)
+	}
+}
+
+// 坐标空间类别的显示名称
+var ptzSpaceKinds = map[string]string{
+	"absolutePanTilt":   "绝对水平/垂直",
+	"relativePanTilt":   "相对水平/垂直",
+	"continuousPanTilt": "连续水平/垂直",
+	"absoluteZoom":      "绝对缩放",
+	"relativeZoom":      "相对缩放",
+	"continuousZoom":    "连续缩放",
+	"panTiltSpeed":      "水平/垂直速度",
+	"zoomSpeed":         "缩放速度",
+	"panTiltLimits":     "水平/垂直限位",
+	"zoomLimits":        "缩放限位",
+}
+
+func printPTZSpaces(spaces []ptzSpaceOutput) {
+	for _, space := range spaces {
+		if space.YMin != nil {
+			fmt.Printf("    %-14s x [%g, %g] y [%g, %g]  %s\n", ptzSpaceKinds[space.Kind], space.XMin, space.XMax, *space.YMin, *space.YMax, space.URI)
+		} else {
+			fmt.Printf("    %-14s x [%g, %g]  %s\n", ptzSpaceKinds[space.Kind], space.XMin, space.XMax, space.URI)
+		}
+	}
+}
+
+// 布尔值的显示文本
+func yesNo(b bool) string {
+	if b {
+		return "是"
+	}
+	return "否"
+}
+
+func printPTZNodes(nodes []ptzNodeOutput) {
+	fmt.Println("=== PTZ 云台节点 ===")
+	if len(nodes) == 0 {
+		fmt.Println("  (无节点)")
+		return
+	}
+
+	for _, node := range nodes {
+		fmt.Printf("\n[%s] %s\n", node.Token, node.Name)
+		fmt.Printf("  最大预置位数: %d\n", node.MaxPresets)
+		home := yesNo(node.HomeSupported)
+		if node.HomeSupported && node.FixedHomePosition != nil && *node.FixedHomePosition {
+			home += " (原点固定, 不可修改)"
+		}
+		fmt.Printf("  支持原点: %s\n", home)
+		if node.MaxPresetTours > 0 || len(node.PresetTourOperations) > 0 {
+			fmt.Printf("  预置位巡航: 最多 %d 个 (操作: %s)\n", node.MaxPresetTours, strings.Join(node.PresetTourOperations, ", "))
+		} else {
+			fmt.Println("  预置位巡航: 不支持")
+		}
+		if len(node.AuxiliaryCommands) > 0 {
+			fmt.Printf("  辅助命令: %s\n", strings.Join(node.AuxiliaryCommands, ", "))
+		} else {
+			fmt.Println("  辅助命令: (无)")
+		}
+		fmt.Println("  坐标空间:")
+		printPTZSpaces(node.Spaces)
+	}
+}
+
+func printPTZConfigs(configs []ptzConfigOutput) {
+	fmt.Println("=== PTZ 配置 ===")
+	if len(configs) == 0 {
+		fmt.Println("  (无配置)")
+		return
+	}
+
+	for _, config := range configs {
+		fmt.Printf("\n[%s] %s\n", config.Token, config.Name)
+		fmt.Printf("  云台节点: %s\n", config.NodeToken)
+		fmt.Printf("  使用次数: %d\n", config.UseCount)
+		if config.DefaultPanTiltSpeed != nil || config.DefaultZoomSpeed != nil {
+			var speeds []string
+			if config.DefaultPanTiltSpeed != nil {
+				speeds = append(speeds, fmt.Sprintf("水平/垂直 %g", *config.DefaultPanTiltSpeed))
+			}
+			if config.DefaultZoomSpeed != nil {
+				speeds = append(speeds, fmt.Sprintf("缩放 %g", *config.DefaultZoomSpeed))
+			}
+			fmt.Printf("  默认速度: %s\n", strings.Join(speeds, ", "))
+		}
+		if config.DefaultTimeout != "" {
+			timeout := config.DefaultTimeout
+			if config.TimeoutMin != "" || config.TimeoutMax != "" {
+				timeout += fmt.Sprintf(" (范围 %s - %s)", config.TimeoutMin, config.TimeoutMax)
+			}
+			fmt.Printf("  默认超时: %s\n", timeout)
+		}
+		if len(config.Limits) > 0 {
+			fmt.Println("  限位:")
+			printPTZSpaces(config.Limits)
+		}
+		if len(config.Spaces) > 0 {
+			fmt.Println("  支持的坐标空间:")
+			printPTZSpaces(config.Spaces)
+		}
+	}
+}
+
+func printPTZStatus(status *onvif.PTZStatus) {
+	fmt.Println("=== PTZ 状态 ===")
+	if pos := status.Position; pos != nil {
+		fmt.Printf("水平:         %g\n", pos.PanTilt.X)
+		fmt.Printf("垂直:         %g\n", pos.PanTilt.Y)
+		fmt.Printf("缩放:         %g\n", pos.Zoom.X)
+	} else {
+		fmt.Println("位置:         (设备未返回)")
+	}
+
+	panTilt, zoom := status.MoveStatus.PanTilt, status.MoveStatus.Zoom
+	if panTilt == "" {
+		panTilt = "UNKNOWN"
+	}
+	if zoom == "" {
+		zoom = "UNKNOWN"
+	}
+	fmt.Printf("云台状态:     %s\n", panTilt)
+	fmt.Printf("缩放状态:     %s\n", zoom)
+	if status.UtcTime != "" {
+		fmt.Printf("设备时间:     %s\n", status.UtcTime)
+	}
+	if status.Error != "" {
+		fmt.Printf("错误:         %s\n", status.Error)
+	}
+}
+
+// 打印图像设置, 有取值范围时附在数值后
+func printImaging(out imagingOutput) {
+	opts := out.Options
+	if opts == nil {
+		opts = &imagingOptionsOutput{}
+	}
+
+	fmt.Printf("=== 图像设置 (视频源 %s) ===\n", out.SourceToken)
+	printImagingLevel("亮度:         ", out.Brightness, opts.Brightness)
+	printImagingLevel("饱和度:       ", out.Saturation, opts.Saturation)
+	printImagingLevel("对比度:       ", out.Contrast, opts.Contrast)
+	printImagingLevel("锐度:         ", out.Sharpness, opts.Sharpness)
+	printImagingMode("曝光模式:     ", out.ExposureMode, opts.ExposureModes)
+	printImagingMode("红外滤光片:   ", out.IrCutFilter, opts.IrCutFilterModes)
+	printImagingMode("宽动态:       ", out.WDRMode, opts.WDRModes)
+	printImagingLevel("宽动态强度:   ", out.WDRLevel, opts.WDRLevel)
+	printImagingMode("背光补偿:     ", out.BacklightMode, opts.BacklightModes)
+	printImagingLevel("背光补偿强度: ", out.BacklightLevel, opts.BacklightLevel)
+}
+
+// 设备既未返回数值也未声明范围的项不打印
+func printImagingLevel(label string, v *float64, r *rangeOutput) {
+	switch {
+	case v != nil && r != nil:
+		fmt.Printf("%s%g  (范围 %g - %g)\n", label, *v, r.Min, r.Max)
+	case v != nil:
+		fmt.Printf("%s%g\n", label, *v)
+	case r != nil:
+		fmt.Printf("%s-  (范围 %g - %g)\n", label, r.Min, r.Max)
+	}
+}
+
+func printImagingMode(label, mode string, modes []string) {
+	switch {
+	case mode != "" && len(modes) > 0:
+		fmt.Printf("%s%s  (支持: %s)\n", label, mode, strings.Join(modes, ", "))
+	case mode != "":
+		fmt.Printf("%s%s\n", label, mode)
+	case len(modes) > 0:
+		fmt.Printf("%s-  (支持: %s)\n", label, strings.Join(modes, ", "))
+	}
+}
+
+// 打印对焦状态
+func printFocusStatus(out focusStatusOutput) {
+	fmt.Printf("=== 对焦状态 (视频源 %s) ===\n", out.SourceToken)
+	if out.Position == nil {
+		fmt.Println("设备未返回对焦状态")
+	} else {
+		fmt.Printf("位置:         %g\n", *out.Position)
+		fmt.Printf("移动状态:     %s\n", out.MoveStatus)
+	}
+	if out.Error != "" {
+		fmt.Printf("错误:         %s\n", out.Error)
+	}
+	if out.AutoFocusMode != "" {
+		fmt.Printf("自动对焦模式: %s\n", out.AutoFocusMode)
+	}
+}
+
+// 打印对焦移动选项
+func printFocusOptions(out focusOptionsOutput) {
+	fmt.Printf("=== 对焦移动选项 (视频源 %s) ===\n", out.SourceToken)
+	printFocusRange("绝对对焦位置: ", out.AbsolutePosition)
+	printFocusRange("绝对对焦速度: ", out.AbsoluteSpeed)
+	printFocusRange("相对对焦距离: ", out.RelativeDistance)
+	printFocusRange("相对对焦速度: ", out.RelativeSpeed)
+	printFocusRange("连续对焦速度: ", out.ContinuousSpeed)
+	if out.AbsolutePosition == nil && out.RelativeDistance == nil && out.ContinuousSpeed == nil {
+		fmt.Println("设备不支持对焦移动")
+	}
+	printImagingMode("自动对焦模式: ", "", out.AutoFocusModes)
+}
+
+func printFocusRange(label string, r *rangeOutput) {
+	if r != nil {
+		fmt.Printf("%s%g - %g\n", label, r.Min, r.Max)
+	}
+}
+
+// 打印视频编码配置
+func printVideoConfigs(configs []onvif.VideoEncoderConfiguration) {
+	fmt.Println("=== 视频编码配置 ===")
+	for i, config := range configs {
+		fmt.Printf("\n配置 %d:\n", i)
+		fmt.Printf("  Token:      %s\n", config.Token)
+		fmt.Printf("  名称:       %s\n", config.Name)
+		fmt.Printf("  编码:       %s\n", config.Encoding)
+		fmt.Printf("  分辨率:     %dx%d\n", config.Resolution.Width, config.Resolution.Height)
+		fmt.Printf("  质量:       %v\n", config.Quality)
+		fmt.Printf("  帧率:       %d fps\n", config.RateControl.FrameRateLimit)
+		fmt.Printf("  比特率:     %d kbps\n", config.RateControl.BitrateLimit)
+	}
+}
+
+// 打印网络接口配置
+func printNetworkInterfaces(interfaces []onvif.NetworkInterface) {
+	fmt.Println("=== 网络配置 ===")
+	for _, iface := range interfaces {
+		fmt.Printf("\n接口: %s\n", iface.Info.Name)
+		fmt.Printf("  Token:      %s\n", iface.Token)
+		fmt.Printf("  启用:       %t\n", iface.Enabled)
+		fmt.Printf("  MAC 地址:   %s\n", iface.Info.HwAddress)
+		fmt.Printf("  MTU:        %d\n", iface.Info.MTU)
+		fmt.Printf("  IPv4 启用:  %t\n", iface.IPv4.Enabled)
+		fmt.Printf("  DHCP:       %t\n", iface.IPv4.DHCP)
+
+		if len(iface.IPv4.Manual) > 0 {
+			fmt.Println("  手动 IP:")
+			for _, addr := range iface.IPv4.Manual {
+				fmt.Printf("    %s/%d\n", addr.Address, addr.PrefixLength)
+			}
+		}
+	}
+}
+
+// 打印事件消息
+func printEventMessage(count int, msg onvif.NotificationMessage) {
+	fmt.Printf("\n[事件 #%d]\n", count)
+	fmt.Printf("时间: %s\n", msg.Message.UtcTime)
+	fmt.Printf("主题: %s\n", msg.Topic.Value)
+
+	// 打印事件源
+	if len(msg.Message.Source.SimpleItem) > 0 {
+		fmt.Println("来源:")
+		for _, item := range msg.Message.Source.SimpleItem {
+			fmt.Printf("  %s: %s\n", item.Name, item.Value)
+		}
+	}
+
+	// 打印事件数据
+	if len(msg.Message.Data.SimpleItem) > 0 {
+		fmt.Println("数据:")
+		for _, item := range msg.Message.Data.SimpleItem {
+			fmt.Printf("  %s: %s\n", item.Name, item.Value)
+		}
+	}
+
+	// 解析常见事件类型
+	printEventType(msg)
+}
+
+// 解析并提示常见事件类型
+func printEventType(msg onvif.NotificationMessage) {
+	topic := msg.Topic.Value
+
+	// 运动检测
+	if strings.Contains(topic, "MotionDetector") || strings.Contains(topic, "CellMotionDetector") {
+		for _, item := range msg.Message.Data.SimpleItem {
+			if item.Name == "State" {
+				if item.Value == "true" {
+					fmt.Println(">>> 检测到运动!")
+				} else {
+					fmt.Println(">>> 运动结束")
+				}
+			}
+		}
+	}
+
+	// 篡改检测
+	if strings.Contains(topic, "TamperDetector") {
+		for _, item := range msg.Message.Data.SimpleItem {
+			if item.Name == "State" {
+				if item.Value == "true" {
+					fmt.Println(">>> 检测到篡改!")
+				} else {
+					fmt.Println(">>> 篡改结束")
+				}
+			}
+		}
+	}
+
+	// 音频检测
+	if strings.Contains(topic, "AudioAnalytics") {
+		fmt.Println(">>> 音频事件触发")
+	}
+
+	// 区域入侵
+	if strings.Contains(topic, "FieldDetector") {
+		fmt.Println(">>> 区域入侵检测")
+	}
+
+	// 越界检测
+	if strings.Contains(topic, "LineDetector") {
+		fmt.Println(">>> 越界检测")
+	}
+}
+
+// 打印批量配置中的设备列表
+func printBatchDevices(devices []batchDeviceOutput) {
+	fmt.Printf("✓ 成功加载 %d 个设备配置\n", len(devices))
+	for i, device := range devices {
+		fmt.Printf("  [%d] %s - %s:%d\n", i+1, device.Name, device.Host, device.Port)
+	}
+}
+
+// 打印批量获取设备信息的结果
+func printBatchInfo(results []batchInfoOutput) {
+	for i, r := range results {
+		if !r.OK {
+			fmt.Printf("[%d] %s - %s\n", i+1, r.Name, r.Error)
+			continue
+		}
+		fmt.Printf("[%d] %s - %s %s (固件: %s)\n", i+1, r.Name, r.Manufacturer, r.Model, r.FirmwareVersion)
+	}
+
+	fmt.Println("\n✓ 批量查询完成")
+}
+
+// 打印批量抓图的结果
+func printBatchSnapshot(results []batchSnapshotOutput, outputDir string) {
+	for i, r := range results {
+		if !r.OK {
+			fmt.Printf("[%d] %s - %s\n", i+1, r.Name, r.Error)
+			continue
+		}
+		fmt.Printf("[%d] %s - ✓ 已保存到 %s\n", i+1, r.Name, r.File)
+	}
+
+	fmt.Printf("\n✓ 批量抓图完成，图像保存在: %s\n", outputDir)
+}
+
+// 打印批量同步时间的结果
+func printBatchSyncTime(results []batchSyncTimeOutput) {
+	for i, r := range results {
+		if !r.OK {
+			fmt.Printf("[%d] %s - %s\n", i+1, r.Name, r.Error)
+			continue
+		}
+		fmt.Printf("[%d] %s - ✓ 时间同步成功\n", i+1, r.Name)
+	}
+
+	fmt.Println("\n✓ 批量时间同步完成")
+}
+
+func newContextOutputs(config *UserConfig) []contextOutput {
+	contexts := make([]contextOutput, len(config.Devices))
+	for i, dev := range config.Devices {
+		contexts[i] = contextOutput{
+			Name:     dev.Name,
+			Current:  dev.Name == config.CurrentContext,
+			Host:     dev.Host,
+			Port:     dev.Port,
+			Username: dev.Username,
+			UseHTTPS: dev.UseHTTPS,
+			Auth:     dev.Auth,
+		}
+	}
+	return contexts
+}
+
+// 打印设备上下文列表, 当前上下文以 * 标记
+func printContexts(contexts []contextOutput) {
+	if len(contexts) == 0 {
+		fmt.Println("没有设备上下文 (使用 ctx add 添加)")
+		return
+	}
+
+	for _, c := range contexts {
+		mark := " "
+		if c.Current {
+			mark = "*"
+		}
+
+		scheme := "http"
+		if c.UseHTTPS {
+			scheme = "https"
+		}
+
+		auth := c.Auth
+		if auth == "" {
+			auth = "ws-security"
+		}
+
+		fmt.Printf("%s %-16s %s://%s:%d  用户: %s  认证: %s\n", mark, c.Name, scheme, c.Host, c.Port, c.Username, auth)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 视场角校准表文件: 按设备厂商/型号保存, 同型号的摄像机共用
+type calibrationFile struct {
+	Models []modelCalibration `yaml:"models"`
+}
+
+// 一个型号的校准表, Manufacturer/Model 为 GetDeviceInformation 返回的值
+type modelCalibration struct {
+	Manufacturer string     `yaml:"manufacturer"`
+	Model        string     `yaml:"model"`
+	Calibrated   time.Time  `yaml:"calibrated"`
+	Points       []fovPoint `yaml:"points"` // 按 zoom 升序
+}
+
+// 某个缩放位置的视场: 画面宽度/高度对应的相对移动量 (通用坐标空间)
+// 负值表示云台移动方向与画面方向相反 (如倒装)
+type fovPoint struct {
+	Zoom float64 `yaml:"zoom"`
+	Pan  float64 `yaml:"pan"`
+	Tilt float64 `yaml:"tilt"`
+}
+
+// 校准表文件路径: 与用户配置文件同目录的 ptz-calibration.yaml
+func calibrationPath() (string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "ptz-calibration.yaml"), nil
+}
+
+// 加载校准表, 文件不存在时返回空表
+func loadCalibrations() (*calibrationFile, string, error) {
+	path, err := calibrationPath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file calibrationFile
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &file, path, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("读取校准文件失败: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, "", fmt.Errorf("解析校准文件 %s 失败: %w", path, err)
+	}
+
+	return &file, path, nil
+}
+
+// 保存校准表
+func (f *calibrationFile) save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入校准文件失败: %w", err)
+	}
+	return nil
+}
+
+// 查找型号的校准表
+func (f *calibrationFile) find(manufacturer, model string) *modelCalibration {
+	for i := range f.Models {
+		c := &f.Models[i]
+		if c.Manufacturer == manufacturer && c.Model == model {
+			return c
+		}
+	}
+	return nil
+}
+
+// 添加或替换型号的校准表
+func (f *calibrationFile) put(c modelCalibration) {
+	sort.Slice(c.Points, func(i, j int) bool { return c.Points[i].Zoom < c.Points[j].Zoom })
+
+	if old := f.find(c.Manufacturer, c.Model); old != nil {
+		*old = c
+		return
+	}
+	f.Models = append(f.Models, c)
+}
+
+// 型号的显示名称
+func (c *modelCalibration) label() string {
+	return strings.TrimSpace(c.Manufacturer + " " + c.Model)
+}
+
+// 指定缩放位置的视场, 在相邻校准点之间按对数插值 (视场随缩放近似按比例变化)
+// 超出校准范围时使用最近的校准点
+func (c *modelCalibration) fov(zoom float64) (pan, tilt float64) {
+	points := c.Points
+	if zoom <= points[0].Zoom {
+		return points[0].Pan, points[0].Tilt
+	}
+
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]
+		if zoom <= hi.Zoom {
+			f := (zoom - lo.Zoom) / (hi.Zoom - lo.Zoom)
+			return interpolateFOV(lo.Pan, hi.Pan, f), interpolateFOV(lo.Tilt, hi.Tilt, f)
+		}
+	}
+
+	last := points[len(points)-1]
+	return last.Pan, last.Tilt
+}
+
+// 对数插值, 保留 a 的符号
+func interpolateFOV(a, b, f float64) float64 {
+	v := math.Exp(math.Log(math.Abs(a)) + (math.Log(math.Abs(b))-math.Log(math.Abs(a)))*f)
+	return math.Copysign(v, a)
+}
+
+// 检查校准点是否可用
+func (c *modelCalibration) validate() error {
+	if len(c.Points) == 0 {
+		return fmt.Errorf("型号 %s 的校准表为空", c.label())
+	}
+	for _, p := range c.Points {
+		if p.Pan == 0 || p.Tilt == 0 || math.IsNaN(p.Pan) || math.IsNaN(p.Tilt) {
+			return fmt.Errorf("型号 %s 在缩放 %g 处的校准值无效", c.label(), p.Zoom)
+		}
+	}
+	return nil
+}
+
+// 抓图缩小后的宽度 (像素), 测量画面平移时使用
+const frameWidth = 128
+
+// 匹配代价上限, 超过时认为前后两张图不是同一场景 (归一化后不相关图像的代价约为 1.1)
+const maxMatchCost = 0.5
+
+// 画面平移超出搜索范围或前后抓图无法匹配
+var errNoMatch = errors.New("前后抓图无法匹配")
+
+// 缩小后的灰度图, 已归一化为零均值、单位方差, 以消除曝光变化的影响
+type grayFrame struct {
+	w, h int
+	pix  []float64
+}
+
+// 解码抓图 (JPEG/PNG) 并缩小到 frameWidth 宽
+func decodeFrame(data []byte) (*grayFrame, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码抓图失败: %w", err)
+	}
+
+	b := img.Bounds()
+	if b.Dx() < frameWidth || b.Dy() < 16 {
+		return nil, fmt.Errorf("抓图分辨率过低: %dx%d", b.Dx(), b.Dy())
+	}
+
+	w := frameWidth
+	h := int(math.Round(float64(w) * float64(b.Dy()) / float64(b.Dx())))
+	sum := make([]float64, w*h)
+	count := make([]int, w*h)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := (y - b.Min.Y) * h / b.Dy() * w
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			i := row + (x-b.Min.X)*w/b.Dx()
+			sum[i] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+			count[i]++
+		}
+	}
+
+	var mean float64
+	for i := range sum {
+		sum[i] /= float64(count[i])
+		mean += sum[i]
+	}
+	mean /= float64(len(sum))
+
+	var variance float64
+	for _, v := range sum {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(sum)))
+	if std < 1 {
+		return nil, fmt.Errorf("画面没有可用于匹配的纹理")
+	}
+
+	for i := range sum {
+		sum[i] = (sum[i] - mean) / std
+	}
+
+	return &grayFrame{w: w, h: h, pix: sum}, nil
+}
+
+// b 中 (x, y) 处的像素与 a 中 (x+dx, y+dy) 处比较的平均差
+func (a *grayFrame) cost(b *grayFrame, dx, dy int) float64 {
+	var sum float64
+	for y := max(0, -dy); y < min(a.h, a.h-dy); y++ {
+		ra, rb := (y+dy)*a.w+dx, y*a.w
+		for x := max(0, -dx); x < min(a.w, a.w-dx); x++ {
+			sum += math.Abs(b.pix[rb+x] - a.pix[ra+x])
+		}
+	}
+	return sum / float64((a.w-abs(dx))*(a.h-abs(dy)))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// 估计 b 相对 a 的画面平移, 返回占画面宽度/高度的比例
+// 视野向右、向上移动时 x、y 为正; 搜索范围为画面宽度/高度的 maxX、maxY 倍 (不超过一半)
+func frameShift(a, b *grayFrame, maxX, maxY float64) (x, y float64, err error) {
+	if a.w != b.w || a.h != b.h {
+		return 0, 0, fmt.Errorf("前后抓图分辨率不同")
+	}
+
+	rx := min(int(maxX*float64(a.w)), a.w/2)
+	ry := min(int(maxY*float64(a.h)), a.h/2)
+
+	best, bx, by := math.Inf(1), 0, 0
+	for dy := -ry; dy <= ry; dy++ {
+		for dx := -rx; dx <= rx; dx++ {
+			if c := a.cost(b, dx, dy); c < best {
+				best, bx, by = c, dx, dy
+			}
+		}
+	}
+
+	// 最佳位置在搜索边界上时实际平移可能超出范围
+	if best > maxMatchCost || (rx > 0 && abs(bx) == rx) || (ry > 0 && abs(by) == ry) {
+		return 0, 0, errNoMatch
+	}
+
+	// 在相邻位置拟合抛物线得到亚像素平移
+	fx := float64(bx) + subpixel(a.cost(b, bx-1, by), best, a.cost(b, bx+1, by))
+	fy := float64(by) + subpixel(a.cost(b, bx, by-1), best, a.cost(b, bx, by+1))
+
+	// 画面内容向左移动表示视野向右; 图像 y 轴向下, 取反使向上为正
+	return fx / float64(a.w), -fy / float64(a.h), nil
+}
+
+// 三点抛物线的极值偏移
+func subpixel(prev, cur, next float64) float64 {
+	d := prev - 2*cur + next
+	if d <= 0 {
+		return 0
+	}
+	return (prev - next) / (2 * d)
+}
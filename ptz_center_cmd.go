@@ -0,0 +1,458 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
+)
+
+// 校准时单次测量的最多尝试次数 (按测得的平移调整步长后重试)
+const calibrateAttempts = 5
+
+// 测量时期望的画面平移比例, 太小时测量误差大, 太大时前后抓图重叠部分少
+const (
+	minShift    = 0.1
+	targetShift = 0.25
+)
+
+// 设备的型号校准表, 没有时提示先运行 ptz calibrate
+func deviceCalibration(ctx context.Context, client *onvif.Client) (*modelCalibration, error) {
+	info, err := client.GetDeviceInformation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取设备信息失败: %w", err)
+	}
+
+	file, path, err := loadCalibrations()
+	if err != nil {
+		return nil, err
+	}
+
+	c := file.find(info.Manufacturer, info.Model)
+	if c == nil {
+		return nil, fmt.Errorf("型号 %s %s 没有视场校准数据 (%s), 请先运行 onvifctl ptz calibrate", info.Manufacturer, info.Model, path)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// 视场校准: 在各缩放位置按已知步长相对移动, 比较前后抓图的画面平移得到视场
+type fovCalibrator struct {
+	client       *onvif.Client
+	profileToken string
+	settle       time.Duration // 云台停止后等待画面稳定的时间
+	waitTimeout  time.Duration
+}
+
+// 等待云台停止并稳定后抓图
+func (c *fovCalibrator) frame(ctx context.Context) (*grayFrame, error) {
+	if err := c.waitIdle(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	sleepContext(ctx, c.settle)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	data, err := c.client.GetSnapshot(ctx, c.profileToken)
+	if err != nil {
+		return nil, fmt.Errorf("抓图失败: %w", err)
+	}
+
+	return decodeFrame(data)
+}
+
+// 等待云台停止或到达 target (可为 nil)
+func (c *fovCalibrator) waitIdle(ctx context.Context, target *onvif.PTZVector) error {
+	waitCtx, cancel := context.WithTimeout(ctx, c.waitTimeout)
+	defer cancel()
+
+	_, err := c.client.WaitIdle(waitCtx, c.profileToken, onvif.WaitOptions{Target: target, Tolerance: 0.01})
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("等待云台停止超时 (%s)", c.waitTimeout)
+		}
+		return fmt.Errorf("获取 PTZ 状态失败: %w", err)
+	}
+	return nil
+}
+
+// 相对移动一个轴
+func (c *fovCalibrator) move(ctx context.Context, pan bool, step float64) error {
+	pt := &onvif.PanTilt{Space: onvif.PanTiltTranslationGenericSpace}
+	if pan {
+		pt.X = step
+	} else {
+		pt.Y = step
+	}
+
+	if err := c.client.RelativeMove(ctx, c.profileToken, onvif.PTZVector{PanTilt: pt}, nil); err != nil {
+		return fmt.Errorf("PTZ 移动失败: %w", err)
+	}
+	return nil
+}
+
+// 沿一个轴移动 step 并测量画面平移比例, 之后移回原处
+func (c *fovCalibrator) measure(ctx context.Context, pan bool, step float64) (float64, error) {
+	before, err := c.frame(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.move(ctx, pan, step); err != nil {
+		return 0, err
+	}
+
+	after, err := c.frame(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.move(ctx, pan, -step); err != nil {
+		return 0, err
+	}
+
+	var x, y float64
+	if pan {
+		x, _, err = frameShift(before, after, 0.5, 0.1)
+	} else {
+		_, y, err = frameShift(before, after, 0.1, 0.5)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if pan {
+		return x, nil
+	}
+	return y, nil
+}
+
+// 测量一个轴在当前缩放位置的视场
+// guess 为估计的视场大小, dir 为移动方向 (朝远离限位的一侧)
+func (c *fovCalibrator) axis(ctx context.Context, pan bool, guess, dir float64) (float64, error) {
+	name := "垂直"
+	if pan {
+		name = "水平"
+	}
+
+	step := guess * targetShift
+	for attempt := 0; attempt < calibrateAttempts; attempt++ {
+		shift, err := c.measure(ctx, pan, dir*step)
+		if errors.Is(err, errNoMatch) {
+			// 步长过大, 画面移出搜索范围
+			step /= 3
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if math.Abs(shift) < minShift {
+			if step >= 1 {
+				break
+			}
+			step = min(1, step*targetShift/max(math.Abs(shift), targetShift/8))
+			continue
+		}
+
+		statusf("  %s: 移动 %.4f, 画面平移 %.1f%%\n", name, dir*step, shift*100)
+		return dir * step / shift, nil
+	}
+
+	return 0, fmt.Errorf("无法测量%s视场: 请确认云台可以移动且画面中有足够的静止纹理", name)
+}
+
+// 远离限位的移动方向
+func awayFromLimit(v float64) float64 {
+	if v > 0 {
+		return -1
+	}
+	return 1
+}
+
+// 校准的缩放位置: 在设备声明的绝对缩放范围内均匀取 n 个
+// 设备不支持缩放或 n 为 1 时只校准当前缩放位置
+func calibrationZooms(options *onvif.PTZConfigurationOptions, current float64, n int) []float64 {
+	if n <= 1 || options == nil {
+		return []float64{current}
+	}
+
+	var zoomRange *onvif.FloatRange
+	for _, s := range options.Spaces.AbsoluteZoomPositionSpace {
+		if s.URI == onvif.ZoomPositionGenericSpace {
+			zoomRange = &s.XRange
+			break
+		}
+	}
+	if zoomRange == nil {
+		return []float64{current}
+	}
+
+	zooms := make([]float64, n)
+	for i := range zooms {
+		zooms[i] = zoomRange.Min + (zoomRange.Max-zoomRange.Min)*float64(i)/float64(n-1)
+	}
+	return zooms
+}
+
+func ptzCalibrateCmd() *cobra.Command {
+	var (
+		zoomLevels  int
+		settle      time.Duration
+		waitTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "calibrate",
+		Short: "校准云台视场 (供 ptz center 使用), 按设备厂商/型号保存",
+		Long: `在多个缩放位置按已知步长相对移动云台, 比较移动前后的抓图测量画面平移, 得到画面宽度/高度对应的移动量
+校准表按 GetDeviceInformation 返回的厂商/型号保存在用户配置目录的 ptz-calibration.yaml 中, 同型号的摄像机只需校准一次
+
+校准时云台会在当前位置附近移动和缩放, 结束后回到原来的位置
+请选择画面中有丰富静止纹理 (建筑、树木等) 的位置, 避免大片天空、墙面或移动的物体`,
+		Example: `  # 在 5 个缩放位置校准
+  onvifctl ptz calibrate --device lobby
+
+  # 图像延迟较大的设备, 每次移动后多等待一会儿再抓图
+  onvifctl ptz calibrate --zoom-levels 8 --settle 2s --device lobby`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if zoomLevels < 1 {
+				return fmt.Errorf("--zoom-levels 必须大于 0")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			info, err := client.GetDeviceInformation(ctx)
+			if err != nil {
+				return fmt.Errorf("获取设备信息失败: %w", err)
+			}
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			start, err := client.GetStatus(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取 PTZ 状态失败: %w", err)
+			}
+			if start.Position == nil {
+				return fmt.Errorf("设备没有返回云台位置, 无法校准")
+			}
+
+			var options *onvif.PTZConfigurationOptions
+			if profile.PTZConfiguration != nil {
+				if options, err = client.GetConfigurationOptions(ctx, profile.PTZConfiguration.Token); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					fmt.Fprintf(os.Stderr, "⚠️  获取 PTZ 配置选项失败, 只校准当前缩放位置: %v\n", err)
+				}
+			}
+			zooms := calibrationZooms(options, start.Position.Zoom.X, zoomLevels)
+			zoomable := len(zooms) > 1
+
+			origin := onvif.PTZVector{PanTilt: &start.Position.PanTilt}
+			if zoomable {
+				origin.Zoom = &start.Position.Zoom
+			}
+
+			// 无论成功与否都回到开始时的位置
+			defer func() {
+				restoreCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := client.AbsoluteMove(restoreCtx, profile.Token, origin, nil); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  回到校准前的位置失败: %v\n", err)
+				}
+			}()
+
+			c := &fovCalibrator{client: client, profileToken: profile.Token, settle: settle, waitTimeout: waitTimeout}
+			calibration := modelCalibration{Manufacturer: info.Manufacturer, Model: info.Model}
+
+			statusf("校准型号 %s %s, 缩放位置: %v\n", info.Manufacturer, info.Model, zooms)
+
+			// 初始估计的视场, 之后用上一个缩放位置的结果
+			guessPan, guessTilt := 0.3, 0.3
+			for _, zoom := range zooms {
+				if zoomable {
+					target := onvif.PTZVector{Zoom: &onvif.Zoom{X: zoom, Space: onvif.ZoomPositionGenericSpace}}
+					if err := client.AbsoluteMove(ctx, profile.Token, target, nil); err != nil {
+						return fmt.Errorf("PTZ 缩放失败: %w", err)
+					}
+					if err := c.waitIdle(ctx, &target); err != nil {
+						return err
+					}
+				}
+
+				status, err := client.GetStatus(ctx, profile.Token)
+				if err != nil {
+					return fmt.Errorf("获取 PTZ 状态失败: %w", err)
+				}
+				var pos onvif.PanTilt
+				if status.Position != nil {
+					pos = status.Position.PanTilt
+				}
+
+				statusf("缩放 %g:\n", zoom)
+
+				pan, err := c.axis(ctx, true, math.Abs(guessPan), awayFromLimit(pos.X))
+				if err != nil {
+					return err
+				}
+				tilt, err := c.axis(ctx, false, math.Abs(guessTilt), awayFromLimit(pos.Y))
+				if err != nil {
+					return err
+				}
+
+				calibration.Points = append(calibration.Points, fovPoint{Zoom: zoom, Pan: pan, Tilt: tilt})
+				guessPan, guessTilt = pan, tilt
+			}
+			calibration.Calibrated = time.Now().UTC().Truncate(time.Second)
+
+			file, path, err := loadCalibrations()
+			if err != nil {
+				return err
+			}
+			file.put(calibration)
+			if err := file.save(path); err != nil {
+				return err
+			}
+
+			out := newCalibrationOutput(calibration, path)
+			return render(out, func() { printCalibration(out) })
+		},
+	}
+
+	cmd.Flags().IntVar(&zoomLevels, "zoom-levels", 5, "校准的缩放位置数量, 在设备的缩放范围内均匀分布 (1 表示只校准当前缩放)")
+	cmd.Flags().DurationVar(&settle, "settle", time.Second, "云台停止后等待画面稳定再抓图的时间")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "每次移动等待云台停止的最长时间")
+
+	return cmd
+}
+
+func ptzCenterCmd() *cobra.Command {
+	var (
+		x, y  float64
+		speed float64
+		wait  ptzWaitArgs
+	)
+
+	cmd := &cobra.Command{
+		Use:   "center",
+		Short: "将画面中的一点移到中心 (点击居中)",
+		Long: `将抓图中的一点移到画面中心: --x/--y 为归一化的图像坐标, 左上角为 (0, 0), 右下角为 (1, 1)
+按当前缩放位置的视场计算相对移动量, 视场来自 ptz calibrate 按型号保存的校准表
+画面边缘附近和垂直角度较大时误差较大, 可以多次居中逐步逼近`,
+		Example: `  # 将抓图中 (0.73, 0.41) 处的目标移到画面中心
+  onvifctl ptz center --x 0.73 --y 0.41 --device lobby
+
+  # 等待云台停止后再抓图
+  onvifctl ptz center --x 0.2 --y 0.8 --wait --device lobby && onvifctl snapshot --device lobby`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if x < 0 || x > 1 || y < 0 || y > 1 {
+				return fmt.Errorf("--x 和 --y 必须在 0 到 1 之间")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			calibration, err := deviceCalibration(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			status, err := client.GetStatus(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取 PTZ 状态失败: %w", err)
+			}
+			var zoom float64
+			if status.Position != nil {
+				zoom = status.Position.Zoom.X
+			}
+
+			fovPan, fovTilt := calibration.fov(zoom)
+			target := onvif.PTZVector{PanTilt: &onvif.PanTilt{
+				X:     (x - 0.5) * fovPan,
+				Y:     (0.5 - y) * fovTilt,
+				Space: onvif.PanTiltTranslationGenericSpace,
+			}}
+
+			var speedVec *onvif.PTZVector
+			if cmd.Flags().Changed("speed") {
+				speedVec = &onvif.PTZVector{PanTilt: &onvif.PanTilt{X: speed, Y: speed}}
+			}
+			if err := validatePTZMove(ctx, client, profile, target, speedVec, false); err != nil {
+				return err
+			}
+
+			if err := client.RelativeMove(ctx, profile.Token, target, speedVec); err != nil {
+				return fmt.Errorf("PTZ 移动失败: %w", err)
+			}
+
+			result := ptzActionOutput{
+				Action:       "center",
+				ProfileToken: profile.Token,
+				Pan:          &target.PanTilt.X,
+				Tilt:         &target.PanTilt.Y,
+				Space:        "generic",
+			}
+			if speedVec != nil {
+				result.Speed = &speed
+			}
+
+			if wait.wait {
+				if status, err = wait.run(ctx, client, profile.Token, nil); err != nil {
+					return err
+				}
+				statusOut := newPTZStatusOutput(profile.Token, status)
+				result.Status = &statusOut
+			}
+
+			return render(result, func() {
+				fmt.Printf("✓ 正在将画面 (%g, %g) 移到中心\n", x, y)
+				fmt.Printf("  相对移动: 水平 %.4f, 垂直 %.4f (缩放 %g, 视场 %.4f x %.4f)\n",
+					target.PanTilt.X, target.PanTilt.Y, zoom, math.Abs(fovPan), math.Abs(fovTilt))
+				if wait.wait {
+					printWaitResult(status)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&x, "x", 0, "目标的水平图像坐标, 0 (左) 到 1 (右) (必填)")
+	cmd.Flags().Float64Var(&y, "y", 0, "目标的垂直图像坐标, 0 (上) 到 1 (下) (必填)")
+	cmd.Flags().Float64Var(&speed, "speed", 0, "水平/垂直移动速度 (默认使用设备默认速度)")
+	wait.addFlags(cmd, "等待云台停止再返回")
+	cmd.MarkFlagRequired("x")
+	cmd.MarkFlagRequired("y")
+
+	return cmd
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
+)
+
+// ptz 命令及其子命令共用的 Profile 选择参数
+var (
+	ptzProfileToken string
+	ptzProfileName  string
+)
+
+// 按 --profile-token / --profile-name 选择 PTZ 操作使用的 Profile
+// 都未指定时使用第一个绑定了 PTZ 配置的 Profile
+func ptzProfile(ctx context.Context, client *onvif.Client) (onvif.Profile, error) {
+	return client.PTZProfile(ctx, ptzProfileToken, ptzProfileName)
+}
+
+// 绝对/相对移动的命令行参数
+type ptzMoveArgs struct {
+	pan, tilt, zoom  float64
+	speed, zoomSpeed float64
+	space            string
+}
+
+// 根据 --space 取得水平/垂直坐标空间 URI, 缩放始终使用通用坐标空间
+// 支持 generic、degrees 或设备 GetConfigurationOptions 中通告的完整 URI
+func ptzSpaces(space string, absolute bool) (panTilt, zoom string, err error) {
+	zoom = onvif.ZoomTranslationGenericSpace
+	if absolute {
+		zoom = onvif.ZoomPositionGenericSpace
+	}
+
+	switch {
+	case space == "generic":
+		panTilt = onvif.PanTiltTranslationGenericSpace
+		if absolute {
+			panTilt = onvif.PanTiltPositionGenericSpace
+		}
+	case space == "degrees":
+		panTilt = onvif.PanTiltTranslationDegreesSpace
+		if absolute {
+			panTilt = onvif.PanTiltPositionDegreesSpace
+		}
+	case strings.Contains(space, "://"):
+		panTilt = space
+	default:
+		return "", "", fmt.Errorf("未知的坐标空间: %s (支持: generic, degrees 或完整的坐标空间 URI)", space)
+	}
+
+	return panTilt, zoom, nil
+}
+
+// 构造绝对移动的目标位置 (或相对移动的位移) 和速度, 只包含命令行中指定的轴
+func (a ptzMoveArgs) vectors(cmd *cobra.Command, absolute bool) (onvif.PTZVector, *onvif.PTZVector, error) {
+	flags := cmd.Flags()
+
+	panTiltSpace, zoomSpace, err := ptzSpaces(a.space, absolute)
+	if err != nil {
+		return onvif.PTZVector{}, nil, err
+	}
+
+	var target onvif.PTZVector
+	if flags.Changed("pan") || flags.Changed("tilt") {
+		// 绝对位置缺少的轴无法取默认值, 否则会转到 0
+		if absolute && !(flags.Changed("pan") && flags.Changed("tilt")) {
+			return onvif.PTZVector{}, nil, fmt.Errorf("绝对移动需要同时指定 --pan 和 --tilt")
+		}
+		target.PanTilt = &onvif.PanTilt{X: a.pan, Y: a.tilt, Space: panTiltSpace}
+	}
+	if flags.Changed("zoom") {
+		target.Zoom = &onvif.Zoom{X: a.zoom, Space: zoomSpace}
+	}
+	if target.PanTilt == nil && target.Zoom == nil {
+		return onvif.PTZVector{}, nil, fmt.Errorf("必须指定 --pan/--tilt 或 --zoom")
+	}
+
+	var speed *onvif.PTZVector
+	if flags.Changed("speed") && target.PanTilt != nil {
+		speed = &onvif.PTZVector{}
+		speed.PanTilt = &onvif.PanTilt{X: a.speed, Y: a.speed}
+	}
+	if flags.Changed("zoom-speed") && target.Zoom != nil {
+		if speed == nil {
+			speed = &onvif.PTZVector{}
+		}
+		speed.Zoom = &onvif.Zoom{X: a.zoomSpeed}
+	}
+
+	return target, speed, nil
+}
+
+// 按设备声明的坐标空间和范围检查移动参数
+// 无法获取配置选项时只给出警告, 由设备自行校验
+func validatePTZMove(ctx context.Context, client *onvif.Client, profile onvif.Profile, target onvif.PTZVector, speed *onvif.PTZVector, absolute bool) error {
+	if profile.PTZConfiguration == nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Profile %s 未声明 PTZ 配置, 跳过坐标范围校验\n", profile.Token)
+		return nil
+	}
+
+	options, err := client.GetConfigurationOptions(ctx, profile.PTZConfiguration.Token)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  获取 PTZ 配置选项失败, 跳过坐标范围校验: %v\n", err)
+		return nil
+	}
+
+	if absolute {
+		err = options.ValidatePosition(target)
+	} else {
+		err = options.ValidateTranslation(target)
+	}
+	if err != nil {
+		return err
+	}
+
+	if speed != nil {
+		return options.ValidateSpeed(*speed)
+	}
+
+	return nil
+}
+
+// --wait 相关参数
+type ptzWaitArgs struct {
+	wait      bool
+	timeout   time.Duration
+	tolerance float64
+}
+
+// 注册 --wait / --wait-timeout / --tolerance 参数
+func (w *ptzWaitArgs) addFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().BoolVar(&w.wait, "wait", false, usage)
+	cmd.Flags().DurationVar(&w.timeout, "wait-timeout", 30*time.Second, "--wait 的最长等待时间")
+	cmd.Flags().Float64Var(&w.tolerance, "tolerance", 0.01, "--wait 判断到达目标位置的坐标容差")
+}
+
+// 等待云台停止移动或到达 target (可为 nil), 超过 --wait-timeout 返回错误
+// 停止后未到达 target 时只提示
+func (w ptzWaitArgs) run(ctx context.Context, client *onvif.Client, profileToken string, target *onvif.PTZVector) (*onvif.PTZStatus, error) {
+	status, err := w.idle(ctx, client, profileToken, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if target != nil && !status.Near(*target, w.tolerance) {
+		fmt.Fprintf(os.Stderr, "⚠️  云台已停止, 但未到达目标位置 (容差 %g)\n", w.tolerance)
+	}
+
+	return status, nil
+}
+
+// 等待云台到达 target, 停止后未到达时返回错误
+// 用于随后要保存当前位置的操作 (SetPreset 总是保存云台当前位置)
+func (w ptzWaitArgs) reach(ctx context.Context, client *onvif.Client, profileToken string, target onvif.PTZVector) (*onvif.PTZStatus, error) {
+	status, err := w.idle(ctx, client, profileToken, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	if !status.Near(target, w.tolerance) {
+		return nil, fmt.Errorf("云台已停止, 但未到达目标位置 (容差 %g)", w.tolerance)
+	}
+
+	return status, nil
+}
+
+// 等待云台停止移动或到达 target, 超过 --wait-timeout 返回错误
+func (w ptzWaitArgs) idle(ctx context.Context, client *onvif.Client, profileToken string, target *onvif.PTZVector) (*onvif.PTZStatus, error) {
+	statusf("等待云台停止...\n")
+
+	waitCtx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	status, err := client.WaitIdle(waitCtx, profileToken, onvif.WaitOptions{
+		Target:    target,
+		Tolerance: w.tolerance,
+	})
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("等待云台停止超时 (%s)", w.timeout)
+		}
+		return nil, fmt.Errorf("获取 PTZ 状态失败: %w", err)
+	}
+
+	return status, nil
+}
+
+// 打印 --wait 结束后的位置
+func printWaitResult(status *onvif.PTZStatus) {
+	if pos := status.Position; pos != nil {
+		fmt.Printf("✓ 云台已停止: 水平 %g, 垂直 %g, 缩放 %g\n", pos.PanTilt.X, pos.PanTilt.Y, pos.Zoom.X)
+	} else {
+		fmt.Println("✓ 云台已停止")
+	}
+}
+
+func ptzCmd() *cobra.Command {
+	var (
+		move    ptzMoveArgs
+		wait    ptzWaitArgs
+		timeout int
+		preset  string
+		action  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ptz",
+		Short: "PTZ 云台控制",
+		Long: `控制摄像头云台移动、缩放、预置位等
+
+move 为连续移动, --pan/--tilt/--zoom 为速度 (-1.0 到 1.0);
+absolute 和 relative 为绝对/相对移动, --pan/--tilt/--zoom 为坐标或位移,
+取值范围由 --space 指定的坐标空间决定 (generic 为 -1.0 到 1.0, 缩放 0.0 到 1.0; degrees 为角度),
+发送前按设备 GetConfigurationOptions 声明的范围校验`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			switch action {
+			case "move", "absolute", "relative", "stop", "goto", "setpreset", "list":
+			default:
+				return fmt.Errorf("未知的操作: %s (支持: move, absolute, relative, stop, goto, setpreset, list)", action)
+			}
+			if (action == "goto" || action == "setpreset") && preset == "" {
+				return fmt.Errorf("必须指定预置位 (--preset)")
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			result := ptzActionOutput{Action: action, ProfileToken: profile.Token}
+
+			switch action {
+			case "move":
+				if err := client.ContinuousMove(ctx, profile.Token, move.pan, move.tilt, move.zoom, time.Duration(timeout)*time.Second); err != nil {
+					return fmt.Errorf("PTZ 移动失败: %w", err)
+				}
+
+				result.Pan, result.Tilt, result.Zoom = &move.pan, &move.tilt, &move.zoom
+				result.TimeoutSeconds = timeout
+				return render(result, func() {
+					fmt.Printf("✓ PTZ 移动命令已发送\n")
+					fmt.Printf("  水平速度: %.2f\n", move.pan)
+					fmt.Printf("  垂直速度: %.2f\n", move.tilt)
+					fmt.Printf("  缩放速度: %.2f\n", move.zoom)
+					if timeout > 0 {
+						fmt.Printf("  持续时间: %d 秒\n", timeout)
+					}
+				})
+			case "absolute", "relative":
+				absolute := action == "absolute"
+
+				target, speed, err := move.vectors(cmd, absolute)
+				if err != nil {
+					return err
+				}
+				if err := validatePTZMove(ctx, client, profile, target, speed, absolute); err != nil {
+					return err
+				}
+
+				if absolute {
+					err = client.AbsoluteMove(ctx, profile.Token, target, speed)
+				} else {
+					err = client.RelativeMove(ctx, profile.Token, target, speed)
+				}
+				if err != nil {
+					return fmt.Errorf("PTZ 移动失败: %w", err)
+				}
+
+				var status *onvif.PTZStatus
+				if wait.wait {
+					// 设备报告的位置使用通用坐标空间, 其它坐标空间只按移动状态判断
+					var waitTarget *onvif.PTZVector
+					if absolute && move.space == "generic" {
+						waitTarget = &target
+					}
+					if status, err = wait.run(ctx, client, profile.Token, waitTarget); err != nil {
+						return err
+					}
+					statusOut := newPTZStatusOutput(profile.Token, status)
+					result.Status = &statusOut
+				}
+
+				result.Space = move.space
+				if pt := target.PanTilt; pt != nil {
+					result.Pan, result.Tilt = &pt.X, &pt.Y
+				}
+				if z := target.Zoom; z != nil {
+					result.Zoom = &z.X
+				}
+				if speed != nil && speed.PanTilt != nil {
+					result.Speed = &speed.PanTilt.X
+				}
+				if speed != nil && speed.Zoom != nil {
+					result.ZoomSpeed = &speed.Zoom.X
+				}
+				return render(result, func() {
+					if absolute {
+						fmt.Printf("✓ PTZ 绝对移动命令已发送 (坐标空间: %s)\n", move.space)
+					} else {
+						fmt.Printf("✓ PTZ 相对移动命令已发送 (坐标空间: %s)\n", move.space)
+					}
+					if pt := target.PanTilt; pt != nil {
+						fmt.Printf("  水平: %g\n", pt.X)
+						fmt.Printf("  垂直: %g\n", pt.Y)
+					}
+					if z := target.Zoom; z != nil {
+						fmt.Printf("  缩放: %g\n", z.X)
+					}
+					if result.Speed != nil {
+						fmt.Printf("  速度: %g\n", *result.Speed)
+					}
+					if result.ZoomSpeed != nil {
+						fmt.Printf("  缩放速度: %g\n", *result.ZoomSpeed)
+					}
+					if status != nil {
+						printWaitResult(status)
+					}
+				})
+			case "stop":
+				if err := client.Stop(ctx, profile.Token); err != nil {
+					return fmt.Errorf("PTZ 停止失败: %w", err)
+				}
+
+				return render(result, func() { fmt.Println("✓ PTZ 已停止") })
+			case "goto":
+				p, status, err := gotoPreset(ctx, client, profile.Token, preset, wait)
+				if err != nil {
+					return err
+				}
+
+				result.PresetToken, result.PresetName = p.Token, p.Name
+				if status != nil {
+					statusOut := newPTZStatusOutput(profile.Token, status)
+					result.Status = &statusOut
+				}
+
+				return render(result, func() {
+					fmt.Printf("✓ 正在转到预置位 %s\n", presetLabel(p))
+					if status != nil {
+						printWaitResult(status)
+					}
+				})
+			case "setpreset":
+				p, created, err := savePreset(ctx, client, profile.Token, preset)
+				if err != nil {
+					return err
+				}
+
+				result.PresetToken, result.PresetName = p.Token, p.Name
+				return render(result, func() { printSavedPreset(p, created) })
+			default: // list
+				presets, err := client.GetPresets(ctx, profile.Token)
+				if err != nil {
+					return fmt.Errorf("获取预置位列表失败: %w", err)
+				}
+
+				return render(newPresetOutputs(presets), func() { printPresets(presets) })
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&ptzProfileToken, "profile-token", "", "按 Token 指定 PTZ 使用的 Profile (默认第一个带 PTZ 配置的 Profile)")
+	cmd.PersistentFlags().StringVar(&ptzProfileName, "profile-name", "", "按名称指定 PTZ 使用的 Profile")
+	cmd.MarkFlagsMutuallyExclusive("profile-token", "profile-name")
+	cmd.Flags().StringVar(&action, "action", "", "操作类型: move, absolute, relative, stop, goto, setpreset, list (必填)")
+	cmd.Flags().Float64Var(&move.pan, "pan", 0, "水平速度 (move, -1.0 到 1.0, 负值向左) 或坐标/位移 (absolute/relative)")
+	cmd.Flags().Float64Var(&move.tilt, "tilt", 0, "垂直速度 (move, -1.0 到 1.0, 负值向下) 或坐标/位移 (absolute/relative)")
+	cmd.Flags().Float64Var(&move.zoom, "zoom", 0, "缩放速度 (move, -1.0 到 1.0, 负值缩小) 或坐标/位移 (absolute/relative)")
+	cmd.Flags().StringVar(&move.space, "space", "generic", "absolute/relative 的水平/垂直坐标空间: generic, degrees 或坐标空间 URI")
+	cmd.Flags().Float64Var(&move.speed, "speed", 0, "absolute/relative 的水平/垂直移动速度 (默认使用设备默认速度)")
+	cmd.Flags().Float64Var(&move.zoomSpeed, "zoom-speed", 0, "absolute/relative 的缩放速度 (默认使用设备默认速度)")
+	cmd.Flags().IntVar(&timeout, "timeout", 1, "移动持续时间（秒）")
+	cmd.Flags().StringVar(&preset, "preset", "", "预置位 Token 或名称 (setpreset 时不存在则以此为名新建)")
+	wait.addFlags(cmd, "goto/absolute/relative 后等待云台停止再返回")
+	cmd.MarkFlagRequired("action")
+
+	cmd.AddCommand(ptzStatusCmd())
+	cmd.AddCommand(ptzPresetCmd())
+	cmd.AddCommand(ptzHomeCmd())
+	cmd.AddCommand(ptzTourCmd())
+	cmd.AddCommand(ptzRunCmd())
+	cmd.AddCommand(ptzInteractiveCmd())
+	cmd.AddCommand(ptzNodesCmd())
+	cmd.AddCommand(ptzConfigCmd())
+	cmd.AddCommand(ptzAuxCmd())
+	cmd.AddCommand(ptzCalibrateCmd())
+	cmd.AddCommand(ptzCenterCmd())
+
+	return cmd
+}
+
+func ptzStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "查看云台当前位置和移动状态",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			status, err := client.GetStatus(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取 PTZ 状态失败: %w", err)
+			}
+
+			return render(newPTZStatusOutput(profile.Token, status), func() { printPTZStatus(status) })
+		},
+	}
+}
+
+func ptzHomeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "home",
+		Short: "原点位置",
+	}
+
+	cmd.AddCommand(ptzHomeGotoCmd())
+	cmd.AddCommand(ptzHomeSetCmd())
+
+	return cmd
+}
+
+func ptzHomeGotoCmd() *cobra.Command {
+	var (
+		speed, zoomSpeed float64
+		wait             ptzWaitArgs
+	)
+
+	cmd := &cobra.Command{
+		Use:   "goto",
+		Short: "转到原点位置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			result := ptzActionOutput{Action: "gotohome", ProfileToken: profile.Token}
+
+			var speedVec *onvif.PTZVector
+			if cmd.Flags().Changed("speed") {
+				speedVec = &onvif.PTZVector{PanTilt: &onvif.PanTilt{X: speed, Y: speed}}
+				result.Speed = &speed
+			}
+			if cmd.Flags().Changed("zoom-speed") {
+				if speedVec == nil {
+					speedVec = &onvif.PTZVector{}
+				}
+				speedVec.Zoom = &onvif.Zoom{X: zoomSpeed}
+				result.ZoomSpeed = &zoomSpeed
+			}
+
+			if err := client.GotoHomePosition(ctx, profile.Token, speedVec); err != nil {
+				return fmt.Errorf("转到原点位置失败: %w", err)
+			}
+
+			var status *onvif.PTZStatus
+			if wait.wait {
+				// 原点坐标无法从设备查询, 只按移动状态判断
+				if status, err = wait.run(ctx, client, profile.Token, nil); err != nil {
+					return err
+				}
+				statusOut := newPTZStatusOutput(profile.Token, status)
+				result.Status = &statusOut
+			}
+
+			return render(result, func() {
+				fmt.Println("✓ 正在转到原点位置")
+				if status != nil {
+					printWaitResult(status)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 0, "水平/垂直移动速度 (默认使用设备默认速度)")
+	cmd.Flags().Float64Var(&zoomSpeed, "zoom-speed", 0, "缩放速度 (默认使用设备默认速度)")
+	wait.addFlags(cmd, "等待云台到达原点后再返回")
+
+	return cmd
+}
+
+func ptzHomeSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set",
+		Short: "将当前位置设为原点位置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			if err := client.SetHomePosition(ctx, profile.Token); err != nil {
+				return fmt.Errorf("设置原点位置失败: %w", err)
+			}
+
+			result := ptzActionOutput{Action: "sethome", ProfileToken: profile.Token}
+			return render(result, func() { fmt.Println("✓ 已将当前位置设为原点位置") })
+		},
+	}
+}
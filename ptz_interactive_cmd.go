@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"onvifctl/onvif"
+)
+
+// 交互模式的按键操作
+type joyAction int
+
+const (
+	joyUp joyAction = iota
+	joyDown
+	joyLeft
+	joyRight
+	joyZoomIn
+	joyZoomOut
+	joyStop
+	joyFaster
+	joySlower
+	joyHome
+	joyPreset
+	joyQuit
+)
+
+// 一次按键
+type joyInput struct {
+	action joyAction
+	preset int // joyPreset 时为数字键对应的编号 (1-10, 0 键为 10)
+}
+
+// 解析终端原始模式下读到的字节, 不认识的按键忽略
+func parseJoyInput(buf []byte) []joyInput {
+	var inputs []joyInput
+
+	for i := 0; i < len(buf); i++ {
+		b := buf[i]
+
+		// 方向键: ESC [ A-D (或应用模式下的 ESC O A-D)
+		if b == 0x1b {
+			if i+2 < len(buf) && (buf[i+1] == '[' || buf[i+1] == 'O') {
+				switch buf[i+2] {
+				case 'A':
+					inputs = append(inputs, joyInput{action: joyUp})
+				case 'B':
+					inputs = append(inputs, joyInput{action: joyDown})
+				case 'C':
+					inputs = append(inputs, joyInput{action: joyRight})
+				case 'D':
+					inputs = append(inputs, joyInput{action: joyLeft})
+				}
+				i += 2
+				continue
+			}
+			// 单独的 ESC 退出
+			if i+1 == len(buf) {
+				inputs = append(inputs, joyInput{action: joyQuit})
+			}
+			continue
+		}
+
+		switch b {
+		case 'w', 'W':
+			inputs = append(inputs, joyInput{action: joyUp})
+		case 's', 'S':
+			inputs = append(inputs, joyInput{action: joyDown})
+		case 'a', 'A':
+			inputs = append(inputs, joyInput{action: joyLeft})
+		case 'd', 'D':
+			inputs = append(inputs, joyInput{action: joyRight})
+		case '+', '=':
+			inputs = append(inputs, joyInput{action: joyZoomIn})
+		case '-', '_':
+			inputs = append(inputs, joyInput{action: joyZoomOut})
+		case ' ':
+			inputs = append(inputs, joyInput{action: joyStop})
+		case ']':
+			inputs = append(inputs, joyInput{action: joyFaster})
+		case '[':
+			inputs = append(inputs, joyInput{action: joySlower})
+		case 'h', 'H':
+			inputs = append(inputs, joyInput{action: joyHome})
+		case 'q', 'Q', 0x03, 0x04: // Ctrl-C、Ctrl-D
+			inputs = append(inputs, joyInput{action: joyQuit})
+		default:
+			if b >= '0' && b <= '9' {
+				n := int(b - '0')
+				if n == 0 {
+					n = 10
+				}
+				inputs = append(inputs, joyInput{action: joyPreset, preset: n})
+			}
+		}
+	}
+
+	return inputs
+}
+
+// 数字键对应的预置位: 优先匹配 Token 或名称为该数字的预置位, 否则按列表顺序
+func presetKeys(presets []onvif.PTZPreset) map[int]onvif.PTZPreset {
+	keys := make(map[int]onvif.PTZPreset)
+	for n := 1; n <= 10; n++ {
+		if p, ok, _ := onvif.FindPreset(presets, strconv.Itoa(n)); ok {
+			keys[n] = p
+		} else if n <= len(presets) {
+			keys[n] = presets[n-1]
+		}
+	}
+	return keys
+}
+
+// GetStatus 轮询结果
+type joyStatus struct {
+	status *onvif.PTZStatus
+	err    error
+}
+
+// 交互模式状态
+type joystick struct {
+	client       *onvif.Client
+	profileToken string
+	presets      map[int]onvif.PTZPreset
+
+	speed, step float64
+	release     time.Duration // 为 0 时按键锁定移动, 直到按空格
+
+	velocity [3]float64 // 当前发送的水平、垂直、缩放速度
+	moving   bool
+	deadline time.Time // 超过该时刻未收到重复按键则认为已松开
+
+	status string // 最近一次位置
+}
+
+// 清除当前行后输出一行消息, 再重画状态行 (原始模式下换行需要 \r\n)
+func (j *joystick) logf(format string, args ...interface{}) {
+	fmt.Printf("\r\033[K"+format+"\r\n", args...)
+	j.draw()
+}
+
+// 重画状态行
+func (j *joystick) draw() {
+	dir := "停止"
+	if j.moving {
+		var parts []string
+		if v := j.velocity[0]; v != 0 {
+			parts = append(parts, map[bool]string{true: "→", false: "←"}[v > 0])
+		}
+		if v := j.velocity[1]; v != 0 {
+			parts = append(parts, map[bool]string{true: "↑", false: "↓"}[v > 0])
+		}
+		if v := j.velocity[2]; v != 0 {
+			parts = append(parts, map[bool]string{true: "放大", false: "缩小"}[v > 0])
+		}
+		dir = "移动 " + strings.Join(parts, "")
+	}
+	fmt.Printf("\r\033[K%s | 速度 %.1f | %s", j.status, j.speed, dir)
+}
+
+// 按当前速度发送连续移动, 速度未变且正在移动时只延长松开期限
+func (j *joystick) move(ctx context.Context, v [3]float64) error {
+	if j.release > 0 {
+		j.deadline = time.Now().Add(j.release)
+	}
+	if j.moving && j.velocity == v {
+		return nil
+	}
+
+	if err := j.client.ContinuousMove(ctx, j.profileToken, v[0], v[1], v[2], 0); err != nil {
+		return fmt.Errorf("PTZ 移动失败: %w", err)
+	}
+	j.velocity, j.moving = v, true
+	j.draw()
+
+	return nil
+}
+
+func (j *joystick) stop(ctx context.Context) error {
+	if err := j.client.Stop(ctx, j.profileToken); err != nil {
+		return fmt.Errorf("PTZ 停止失败: %w", err)
+	}
+	j.velocity, j.moving = [3]float64{}, false
+	j.draw()
+
+	return nil
+}
+
+// 调整速度, 正在移动时按新速度重新发送
+func (j *joystick) adjustSpeed(ctx context.Context, delta float64) error {
+	j.speed = math.Round(math.Max(j.step, math.Min(1, j.speed+delta))*100) / 100
+	if !j.moving {
+		j.draw()
+		return nil
+	}
+
+	v := j.velocity
+	for i := range v {
+		if v[i] != 0 {
+			v[i] = math.Copysign(j.speed, v[i])
+		}
+	}
+	j.moving = false // 强制重新发送
+	return j.move(ctx, v)
+}
+
+// 处理一次按键, 返回 true 表示退出
+func (j *joystick) handle(ctx context.Context, in joyInput) (bool, error) {
+	s := j.speed
+
+	switch in.action {
+	case joyUp:
+		return false, j.move(ctx, [3]float64{0, s, 0})
+	case joyDown:
+		return false, j.move(ctx, [3]float64{0, -s, 0})
+	case joyLeft:
+		return false, j.move(ctx, [3]float64{-s, 0, 0})
+	case joyRight:
+		return false, j.move(ctx, [3]float64{s, 0, 0})
+	case joyZoomIn:
+		return false, j.move(ctx, [3]float64{0, 0, s})
+	case joyZoomOut:
+		return false, j.move(ctx, [3]float64{0, 0, -s})
+	case joyStop:
+		return false, j.stop(ctx)
+	case joyFaster:
+		return false, j.adjustSpeed(ctx, j.step)
+	case joySlower:
+		return false, j.adjustSpeed(ctx, -j.step)
+	case joyHome:
+		j.velocity, j.moving = [3]float64{}, false
+		if err := j.client.GotoHomePosition(ctx, j.profileToken, nil); err != nil {
+			j.logf("⚠️  转到原点位置失败: %v", err)
+			return false, nil
+		}
+		j.logf("转到原点位置")
+	case joyPreset:
+		p, ok := j.presets[in.preset]
+		if !ok {
+			j.logf("⚠️  数字键 %d 没有对应的预置位", in.preset%10)
+			return false, nil
+		}
+		j.velocity, j.moving = [3]float64{}, false
+		if err := j.client.GotoPreset(ctx, j.profileToken, p.Token); err != nil {
+			j.logf("⚠️  转到预置位 %s 失败: %v", presetLabel(p), err)
+			return false, nil
+		}
+		j.logf("转到预置位 %s", presetLabel(p))
+	case joyQuit:
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// 后台轮询 GetStatus, 只保留最新结果
+func pollJoyStatus(ctx context.Context, client *onvif.Client, profileToken string, interval time.Duration, out chan joyStatus) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := client.GetStatus(ctx, profileToken)
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-out:
+		default:
+		}
+		out <- joyStatus{status: status, err: err}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 状态行中的位置
+func formatJoyStatus(st joyStatus) string {
+	if st.err != nil {
+		return "位置未知"
+	}
+	pos := st.status.Position
+	if pos == nil {
+		return fmt.Sprintf("位置未知 %s", st.status.MoveStatus.PanTilt)
+	}
+	state := st.status.MoveStatus.PanTilt
+	if st.status.Moving() {
+		state = "MOVING"
+	}
+	return fmt.Sprintf("水平 %6.3f 垂直 %6.3f 缩放 %5.3f %-6s", pos.PanTilt.X, pos.PanTilt.Y, pos.Zoom.X, state)
+}
+
+func ptzInteractiveCmd() *cobra.Command {
+	var (
+		speed          float64
+		step           float64
+		release        time.Duration
+		statusInterval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:     "interactive",
+		Aliases: []string{"joy"},
+		Short:   "键盘控制云台 (交互模式)",
+		Long: `在终端中用键盘控制云台, 连接和 Profile 只在启动时获取一次
+
+  ←↑↓→ / WASD   水平/垂直移动
+  + / -         放大/缩小
+  空格          停止
+  [ / ]         减小/增大速度
+  1-9, 0        转到预置位 (优先匹配 Token 或名称为该数字的预置位, 否则按列表顺序, 0 为第 10 个)
+  h             转到原点位置
+  q / Esc       退出
+
+终端无法检测按键松开: 按住方向键时终端会重复发送按键, 超过 --release 没有收到重复按键即认为已松开并发送 Stop;
+--release 0 时移动一直持续到按空格。退出时总是发送 Stop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fd := int(os.Stdin.Fd())
+			if !term.IsTerminal(fd) {
+				return fmt.Errorf("交互模式需要在终端中运行")
+			}
+			if speed <= 0 || speed > 1 {
+				return fmt.Errorf("--speed 必须在 0 到 1.0 之间")
+			}
+			if step <= 0 || step > 1 {
+				return fmt.Errorf("--speed-step 必须在 0 到 1.0 之间")
+			}
+			if release < 0 {
+				return fmt.Errorf("--release 不能为负数")
+			}
+			if statusInterval <= 0 {
+				return fmt.Errorf("--status-interval 必须大于 0")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			// 预置位只用于数字键, 获取失败时仍可移动
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Fprintf(os.Stderr, "⚠️  获取预置位列表失败, 数字键不可用: %v\n", err)
+			}
+
+			j := &joystick{
+				client:       client,
+				profileToken: profile.Token,
+				presets:      presetKeys(presets),
+				speed:        speed,
+				step:         step,
+				release:      release,
+				status:       "位置未知",
+			}
+
+			fmt.Printf("PTZ 交互模式 (Profile: %s)\n", profile.Token)
+			fmt.Println("←↑↓→/WASD 移动  +/- 缩放  空格 停止  [/] 速度  1-9,0 预置位  h 原点  q 退出")
+			if len(j.presets) > 0 {
+				var keys []string
+				for n := 1; n <= 10; n++ {
+					if p, ok := j.presets[n]; ok {
+						keys = append(keys, fmt.Sprintf("%d=%s", n%10, p.Name))
+					}
+				}
+				fmt.Printf("预置位: %s\n", strings.Join(keys, "  "))
+			}
+
+			oldState, err := term.MakeRaw(fd)
+			if err != nil {
+				return fmt.Errorf("设置终端原始模式失败: %w", err)
+			}
+			defer term.Restore(fd, oldState)
+
+			// 读取按键, 读取失败 (如标准输入关闭) 时关闭通道
+			keys := make(chan []byte)
+			go func() {
+				defer close(keys)
+				buf := make([]byte, 64)
+				for {
+					n, err := os.Stdin.Read(buf)
+					if err != nil {
+						return
+					}
+					keys <- append([]byte(nil), buf[:n]...)
+				}
+			}()
+
+			pollCtx, cancelPoll := context.WithCancel(ctx)
+			defer cancelPoll()
+
+			statuses := make(chan joyStatus, 1)
+			go pollJoyStatus(pollCtx, client, profile.Token, statusInterval, statuses)
+
+			ticker := time.NewTicker(50 * time.Millisecond)
+			defer ticker.Stop()
+
+			j.draw()
+
+			var runErr error
+		loop:
+			for {
+				select {
+				case <-ctx.Done():
+					runErr = ctx.Err()
+					break loop
+				case buf, ok := <-keys:
+					if !ok {
+						break loop
+					}
+					for _, in := range parseJoyInput(buf) {
+						quit, err := j.handle(ctx, in)
+						if err != nil {
+							j.logf("⚠️  %v", err)
+						}
+						if quit {
+							break loop
+						}
+					}
+				case st := <-statuses:
+					j.status = formatJoyStatus(st)
+					j.draw()
+				case <-ticker.C:
+					if j.moving && j.release > 0 && time.Now().After(j.deadline) {
+						if err := j.stop(ctx); err != nil {
+							j.logf("⚠️  %v", err)
+						}
+					}
+				}
+			}
+
+			cancelPoll()
+			fmt.Print("\r\033[K")
+
+			// 退出时总是停止云台, 被中断时 ctx 已取消, 使用新的 ctx
+			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := client.Stop(stopCtx, profile.Token); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  停止云台失败: %v\r\n", err)
+			}
+
+			return runErr
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 0.5, "初始移动速度 (0 到 1.0)")
+	cmd.Flags().Float64Var(&step, "speed-step", 0.1, "[ / ] 每次调整的速度")
+	// 默认值需大于按住按键到第一次重复的延迟 (X11 为 660ms, macOS 相近), 否则按住时会先停再动
+	cmd.Flags().DurationVar(&release, "release", 750*time.Millisecond, "多久未收到重复按键视为松开 (0 表示移动持续到按空格)")
+	cmd.Flags().DurationVar(&statusInterval, "status-interval", 500*time.Millisecond, "查询云台位置的间隔")
+
+	return cmd
+}
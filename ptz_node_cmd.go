@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"onvifctl/onvif"
+)
+
+// 辅助命令简写, 如 "wiper on" 对应 tt:Wiper|On
+var auxCommandNames = map[string]string{
+	"wiper":   "tt:Wiper",
+	"washer":  "tt:Washer",
+	"washing": "tt:WashingProcedure",
+	"ir":      "tt:IRLamp",
+	"irlamp":  "tt:IRLamp",
+	"heater":  "tt:Heater",
+}
+
+// 由命令行参数得到辅助命令: 一个参数时原样使用 (如 tt:Wiper|On), 两个参数时按简写展开
+func auxCommand(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+
+	name, ok := auxCommandNames[strings.ToLower(args[0])]
+	if !ok {
+		return "", fmt.Errorf("未知的辅助设备: %s (支持: wiper, washer, washing, ir, heater, 或直接指定完整命令如 tt:Wiper|On)", args[0])
+	}
+
+	state := strings.ToLower(args[1])
+	if state == "" {
+		return "", fmt.Errorf("必须指定辅助设备的状态 (如 on, off, auto)")
+	}
+
+	return name + "|" + strings.ToUpper(state[:1]) + state[1:], nil
+}
+
+// 在节点支持的辅助命令中查找 (忽略大小写), 返回设备声明的写法
+func matchAuxCommand(supported []string, command string) (string, bool) {
+	for _, c := range supported {
+		if strings.EqualFold(c, command) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Profile 的 PTZ 配置所属的云台节点, Profile 未声明 PTZ 配置时使用第一个节点
+func ptzNode(ctx context.Context, client *onvif.Client, profile onvif.Profile) (*onvif.PTZNode, error) {
+	if config := profile.PTZConfiguration; config != nil && config.NodeToken != "" {
+		node, err := client.GetNode(ctx, config.NodeToken)
+		if err != nil {
+			return nil, fmt.Errorf("获取云台节点 %s 失败: %w", config.NodeToken, err)
+		}
+		return node, nil
+	}
+
+	nodes, err := client.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取云台节点失败: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("设备没有云台节点")
+	}
+
+	return &nodes[0], nil
+}
+
+func ptzNodesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "nodes",
+		Short: "查看云台节点能力 (坐标空间、预置位数量、原点、辅助命令)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			nodes, err := client.GetNodes(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("获取云台节点失败: %w", err)
+			}
+
+			out := newPTZNodeOutputs(nodes)
+			return render(out, func() { printPTZNodes(out) })
+		},
+	}
+}
+
+func ptzConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "查看 PTZ 配置及其支持的坐标空间和取值范围",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			configs, err := client.GetConfigurations(ctx)
+			if err != nil {
+				return fmt.Errorf("获取 PTZ 配置失败: %w", err)
+			}
+
+			out := make([]ptzConfigOutput, len(configs))
+			for i, config := range configs {
+				// 个别配置获取选项失败时仍输出其余信息
+				options, err := client.GetConfigurationOptions(ctx, config.Token)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					fmt.Fprintf(os.Stderr, "⚠️  获取 PTZ 配置 %s 的选项失败: %v\n", config.Token, err)
+					options = nil
+				}
+				out[i] = newPTZConfigOutput(config, options)
+			}
+
+			return render(out, func() { printPTZConfigs(out) })
+		},
+	}
+}
+
+func ptzAuxCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "aux [COMMAND | DEVICE STATE]",
+		Short: "发送辅助命令 (雨刷、清洗、红外灯、加热器等)",
+		Long: `发送 ONVIF 辅助命令 (SendAuxiliaryCommand), 不带参数时列出云台节点支持的辅助命令
+
+命令可以直接使用设备声明的写法 (如 tt:Wiper|On), 或使用简写:
+  wiper on|off      雨刷 (tt:Wiper)
+  washer on|off     清洗 (tt:Washer)
+  washing on|off    清洗流程 (tt:WashingProcedure)
+  ir on|off|auto    红外灯 (tt:IRLamp)
+  heater on|off     加热器 (tt:Heater)
+
+发送前按节点的 AuxiliaryCommands 检查, --force 跳过检查`,
+		Example: `  # 列出支持的辅助命令
+  onvifctl ptz aux --device lobby
+
+  # 启动雨刷
+  onvifctl ptz aux wiper on --device lobby
+
+  # 发送厂商自定义命令
+  onvifctl ptz aux "Vendor:Defog|On" --force --device lobby`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var command string
+			if len(args) > 0 {
+				var err error
+				if command, err = auxCommand(args); err != nil {
+					return err
+				}
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			var node *onvif.PTZNode
+			if command == "" || !force {
+				if node, err = ptzNode(ctx, client, profile); err != nil {
+					return err
+				}
+			}
+
+			if command == "" {
+				out := ptzAuxListOutput{NodeToken: node.Token, NodeName: node.Name, Commands: node.AuxiliaryCommands}
+				if out.Commands == nil {
+					out.Commands = []string{}
+				}
+				return render(out, func() {
+					fmt.Printf("=== 云台节点 %s 支持的辅助命令 ===\n", node.Token)
+					if len(out.Commands) == 0 {
+						fmt.Println("  (无)")
+					}
+					for _, c := range out.Commands {
+						fmt.Printf("  %s\n", c)
+					}
+				})
+			}
+
+			if !force {
+				matched, ok := matchAuxCommand(node.AuxiliaryCommands, command)
+				if !ok {
+					if len(node.AuxiliaryCommands) == 0 {
+						return fmt.Errorf("云台节点 %s 没有声明辅助命令 (可用 --force 强制发送)", node.Token)
+					}
+					return fmt.Errorf("云台节点 %s 不支持辅助命令 %s (支持: %s; 可用 --force 强制发送)",
+						node.Token, command, strings.Join(node.AuxiliaryCommands, ", "))
+				}
+				command = matched
+			}
+
+			response, err := client.SendAuxiliaryCommand(ctx, profile.Token, command)
+			if err != nil {
+				return fmt.Errorf("发送辅助命令失败: %w", err)
+			}
+
+			result := ptzActionOutput{Action: "aux", ProfileToken: profile.Token, AuxCommand: command, AuxResponse: response}
+			return render(result, func() {
+				fmt.Printf("✓ 已发送辅助命令 %s\n", command)
+				if response != "" {
+					fmt.Printf("  设备响应: %s\n", response)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "不检查节点声明的辅助命令, 直接发送")
+
+	return cmd
+}
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"onvifctl/onvif"
+)
+
+// 预置位导出文件, 导入时按名称匹配设备上的预置位 (Token 仅供参考)
+type presetFile struct {
+	Presets []presetOutput `yaml:"presets"`
+}
+
+// 位置是否使用通用坐标空间, 未记录坐标空间 (旧版本导出的文件) 视为通用坐标空间
+func (p presetOutput) genericSpace() bool {
+	return (p.PanTiltSpace == "" || p.PanTiltSpace == onvif.PanTiltPositionGenericSpace) &&
+		(p.ZoomSpace == "" || p.ZoomSpace == onvif.ZoomPositionGenericSpace)
+}
+
+// 坐标空间的显示文本
+func (p presetOutput) spaceLabel() string {
+	var spaces []string
+	for _, s := range []string{p.PanTiltSpace, p.ZoomSpace} {
+		if s != "" {
+			spaces = append(spaces, s)
+		}
+	}
+	return strings.Join(spaces, ", ")
+}
+
+// 预置位的显示名称
+func presetLabel(p onvif.PTZPreset) string {
+	if p.Name == "" || p.Name == p.Token {
+		return p.Token
+	}
+	return fmt.Sprintf("%s (Token: %s)", p.Name, p.Token)
+}
+
+// 预置位记录的位置, 设备未返回位置时为 nil
+func presetVector(p onvif.PTZPreset) *onvif.PTZVector {
+	if p.Position == nil {
+		return nil
+	}
+	return &onvif.PTZVector{PanTilt: &p.Position.PanTilt, Zoom: &p.Position.Zoom}
+}
+
+// 按 Token 或名称查找预置位, 未找到时返回错误
+func findPreset(ctx context.Context, client *onvif.Client, profileToken, ref string) (onvif.PTZPreset, error) {
+	presets, err := client.GetPresets(ctx, profileToken)
+	if err != nil {
+		return onvif.PTZPreset{}, fmt.Errorf("获取预置位列表失败: %w", err)
+	}
+
+	p, ok, err := onvif.FindPreset(presets, ref)
+	if err != nil {
+		return onvif.PTZPreset{}, err
+	}
+	if !ok {
+		return onvif.PTZPreset{}, fmt.Errorf("未找到预置位: %s (使用 ptz preset list 查看)", ref)
+	}
+
+	return p, nil
+}
+
+// 转到 Token 或名称对应的预置位, 指定 --wait 时等待云台到达
+func gotoPreset(ctx context.Context, client *onvif.Client, profileToken, ref string, wait ptzWaitArgs) (onvif.PTZPreset, *onvif.PTZStatus, error) {
+	p, err := findPreset(ctx, client, profileToken, ref)
+	if err != nil {
+		return onvif.PTZPreset{}, nil, err
+	}
+
+	if err := client.GotoPreset(ctx, profileToken, p.Token); err != nil {
+		return onvif.PTZPreset{}, nil, fmt.Errorf("转到预置位失败: %w", err)
+	}
+
+	if !wait.wait {
+		return p, nil, nil
+	}
+
+	status, err := wait.run(ctx, client, profileToken, presetVector(p))
+	if err != nil {
+		return onvif.PTZPreset{}, nil, err
+	}
+
+	return p, status, nil
+}
+
+// 将当前位置保存到 Token 或名称对应的预置位, 不存在时以 ref 为名新建
+func savePreset(ctx context.Context, client *onvif.Client, profileToken, ref string) (onvif.PTZPreset, bool, error) {
+	presets, err := client.GetPresets(ctx, profileToken)
+	if err != nil {
+		return onvif.PTZPreset{}, false, fmt.Errorf("获取预置位列表失败: %w", err)
+	}
+
+	p, ok, err := onvif.FindPreset(presets, ref)
+	if err != nil {
+		return onvif.PTZPreset{}, false, err
+	}
+
+	if ok {
+		if err := client.UpdatePreset(ctx, profileToken, p.Token, ""); err != nil {
+			return onvif.PTZPreset{}, false, fmt.Errorf("设置预置位失败: %w", err)
+		}
+		return p, false, nil
+	}
+
+	token, err := client.SetPreset(ctx, profileToken, ref)
+	if err != nil {
+		return onvif.PTZPreset{}, false, fmt.Errorf("设置预置位失败: %w", err)
+	}
+
+	return onvif.PTZPreset{Token: token, Name: ref}, true, nil
+}
+
+// 打印保存预置位的结果
+func printSavedPreset(p onvif.PTZPreset, created bool) {
+	if created {
+		fmt.Printf("✓ 已新建预置位 %s\n", p.Name)
+	} else {
+		fmt.Printf("✓ 已将当前位置保存到预置位 %s\n", p.Name)
+	}
+	fmt.Printf("  Token: %s\n", p.Token)
+}
+
+func ptzPresetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preset",
+		Short: "预置位管理",
+		Long: `管理 PTZ 预置位, 预置位可以用 Token 或名称指定 (Token 优先)
+导出/导入可以在型号相同的摄像机之间复制预置位布局`,
+		Example: `  # 列出预置位 (含位置)
+  onvifctl ptz preset list --device lobby
+
+  # 保存当前位置: 已有同名 (或同 Token) 预置位时原地更新, 否则新建
+  onvifctl ptz preset set Door --device lobby
+
+  # 复制预置位布局到另一台摄像机
+  onvifctl ptz preset export -f presets.yaml --device lobby
+  onvifctl ptz preset import -f presets.yaml --device gate`,
+	}
+
+	cmd.AddCommand(ptzPresetListCmd())
+	cmd.AddCommand(ptzPresetGotoCmd())
+	cmd.AddCommand(ptzPresetSetCmd())
+	cmd.AddCommand(ptzPresetRenameCmd())
+	cmd.AddCommand(ptzPresetRemoveCmd())
+	cmd.AddCommand(ptzPresetExportCmd())
+	cmd.AddCommand(ptzPresetImportCmd())
+
+	return cmd
+}
+
+func ptzPresetListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出预置位及其位置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取预置位列表失败: %w", err)
+			}
+
+			return render(newPresetOutputs(presets), func() { printPresets(presets) })
+		},
+	}
+}
+
+func ptzPresetGotoCmd() *cobra.Command {
+	var wait ptzWaitArgs
+
+	cmd := &cobra.Command{
+		Use:   "goto TOKEN|NAME",
+		Short: "转到预置位",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			p, status, err := gotoPreset(ctx, client, profile.Token, args[0], wait)
+			if err != nil {
+				return err
+			}
+
+			result := ptzActionOutput{Action: "goto", ProfileToken: profile.Token, PresetToken: p.Token, PresetName: p.Name}
+			if status != nil {
+				statusOut := newPTZStatusOutput(profile.Token, status)
+				result.Status = &statusOut
+			}
+
+			return render(result, func() {
+				fmt.Printf("✓ 正在转到预置位 %s\n", presetLabel(p))
+				if status != nil {
+					printWaitResult(status)
+				}
+			})
+		},
+	}
+
+	wait.addFlags(cmd, "等待云台到达预置位后再返回")
+
+	return cmd
+}
+
+func ptzPresetSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set TOKEN|NAME",
+		Short: "将当前位置保存为预置位 (已存在时原地更新, 否则以该名称新建)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			p, created, err := savePreset(ctx, client, profile.Token, args[0])
+			if err != nil {
+				return err
+			}
+
+			result := ptzActionOutput{Action: "setpreset", ProfileToken: profile.Token, PresetToken: p.Token, PresetName: p.Name}
+			return render(result, func() { printSavedPreset(p, created) })
+		},
+	}
+}
+
+func ptzPresetRenameCmd() *cobra.Command {
+	var wait ptzWaitArgs
+
+	cmd := &cobra.Command{
+		Use:   "rename TOKEN|NAME NEW_NAME",
+		Short: "重命名预置位",
+		Long: `重命名预置位
+
+ONVIF 的 SetPreset 总是保存云台当前位置, 因此重命名时会先转到该预置位,
+等待云台到达后再以新名称保存, 预置位位置保持不变
+云台未到达预置位 (或设备未返回预置位位置) 时不重命名`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			p, err := findPreset(ctx, client, profile.Token, args[0])
+			if err != nil {
+				return err
+			}
+
+			target := presetVector(p)
+			if target == nil {
+				return fmt.Errorf("设备未返回预置位 %s 的位置, 无法确认云台到达, 未重命名", presetLabel(p))
+			}
+
+			if err := client.GotoPreset(ctx, profile.Token, p.Token); err != nil {
+				return fmt.Errorf("转到预置位失败: %w", err)
+			}
+			if _, err := wait.reach(ctx, client, profile.Token, *target); err != nil {
+				return fmt.Errorf("%w, 未重命名预置位 %s", err, presetLabel(p))
+			}
+
+			if err := client.UpdatePreset(ctx, profile.Token, p.Token, args[1]); err != nil {
+				return fmt.Errorf("重命名预置位失败: %w", err)
+			}
+
+			result := ptzActionOutput{Action: "rename", ProfileToken: profile.Token, PresetToken: p.Token, PresetName: args[1]}
+			return render(result, func() {
+				fmt.Printf("✓ 预置位 %s 已重命名为 %s\n", presetLabel(p), args[1])
+			})
+		},
+	}
+
+	cmd.Flags().DurationVar(&wait.timeout, "wait-timeout", 30*time.Second, "等待云台到达预置位的最长时间")
+	cmd.Flags().Float64Var(&wait.tolerance, "tolerance", 0.01, "判断到达预置位的坐标容差")
+
+	return cmd
+}
+
+func ptzPresetRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove TOKEN|NAME",
+		Aliases: []string{"rm"},
+		Short:   "删除预置位",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			p, err := findPreset(ctx, client, profile.Token, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := client.RemovePreset(ctx, profile.Token, p.Token); err != nil {
+				return fmt.Errorf("删除预置位失败: %w", err)
+			}
+
+			result := ptzActionOutput{Action: "remove", ProfileToken: profile.Token, PresetToken: p.Token, PresetName: p.Name}
+			return render(result, func() { fmt.Printf("✓ 已删除预置位 %s\n", presetLabel(p)) })
+		},
+	}
+}
+
+func ptzPresetExportCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出预置位 (名称和位置) 到 YAML 文件",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取预置位列表失败: %w", err)
+			}
+
+			data, err := yaml.Marshal(presetFile{Presets: newPresetOutputs(presets)})
+			if err != nil {
+				return err
+			}
+
+			if file == "-" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("写入文件失败: %w", err)
+			}
+
+			missing, nonGeneric := 0, 0
+			for _, p := range newPresetOutputs(presets) {
+				if p.Pan == nil {
+					missing++
+				} else if !p.genericSpace() {
+					nonGeneric++
+				}
+			}
+
+			statusf("✓ 已导出 %d 个预置位到 %s\n", len(presets), file)
+			if missing > 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  %d 个预置位设备未返回位置, 导入时将被跳过\n", missing)
+			}
+			if nonGeneric > 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  %d 个预置位的位置不是通用坐标空间, 无法导入\n", nonGeneric)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "presets.yaml", "输出文件 (- 表示标准输出)")
+
+	return cmd
+}
+
+func ptzPresetImportCmd() *cobra.Command {
+	var (
+		file  string
+		prune bool
+		wait  ptzWaitArgs
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "从 YAML 文件导入预置位",
+		Long: `从 ptz preset export 导出的文件导入预置位
+
+依次移动到每个预置位的位置并保存: 设备上已有同名预置位时原地更新, 否则新建
+--prune 删除设备上文件中没有的预置位
+
+只支持通用坐标空间的位置 (未记录坐标空间的按通用坐标空间处理);
+云台未到达某个预置位的位置时停止导入, 不保存该预置位`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("读取文件失败: %w", err)
+			}
+
+			var pf presetFile
+			if err := yaml.Unmarshal(data, &pf); err != nil {
+				return fmt.Errorf("解析预置位文件失败: %w", err)
+			}
+
+			// 在移动云台之前检查整个文件
+			for _, entry := range pf.Presets {
+				if entry.Name == "" {
+					return fmt.Errorf("预置位文件中有未命名的预置位")
+				}
+				if !entry.genericSpace() {
+					return fmt.Errorf("预置位 %s 的位置不是通用坐标空间 (%s), 不支持导入", entry.Name, entry.spaceLabel())
+				}
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			existing, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取预置位列表失败: %w", err)
+			}
+
+			byName := make(map[string]onvif.PTZPreset)
+			for _, p := range existing {
+				byName[p.Name] = p
+			}
+
+			var results []presetImportOutput
+			imported := make(map[string]bool)
+
+			for _, entry := range pf.Presets {
+				imported[entry.Name] = true
+
+				if entry.Pan == nil || entry.Tilt == nil || entry.Zoom == nil {
+					fmt.Fprintf(os.Stderr, "⚠️  预置位 %s 没有位置信息, 已跳过\n", entry.Name)
+					results = append(results, presetImportOutput{Name: entry.Name, Result: "skipped"})
+					continue
+				}
+
+				statusf("导入预置位 %s...\n", entry.Name)
+
+				target := onvif.PTZVector{
+					PanTilt: &onvif.PanTilt{X: *entry.Pan, Y: *entry.Tilt, Space: onvif.PanTiltPositionGenericSpace},
+					Zoom:    &onvif.Zoom{X: *entry.Zoom, Space: onvif.ZoomPositionGenericSpace},
+				}
+				if err := client.AbsoluteMove(ctx, profile.Token, target, nil); err != nil {
+					return fmt.Errorf("移动到预置位 %s 的位置失败: %w", entry.Name, err)
+				}
+				if _, err := wait.reach(ctx, client, profile.Token, target); err != nil {
+					return fmt.Errorf("%w, 已停止导入 (预置位 %s 未保存)", err, entry.Name)
+				}
+
+				out := presetImportOutput{Name: entry.Name}
+				if p, ok := byName[entry.Name]; ok {
+					if err := client.UpdatePreset(ctx, profile.Token, p.Token, ""); err != nil {
+						return fmt.Errorf("更新预置位 %s 失败: %w", entry.Name, err)
+					}
+					out.Token, out.Result = p.Token, "updated"
+				} else {
+					token, err := client.SetPreset(ctx, profile.Token, entry.Name)
+					if err != nil {
+						return fmt.Errorf("新建预置位 %s 失败: %w", entry.Name, err)
+					}
+					out.Token, out.Result = token, "created"
+				}
+				results = append(results, out)
+			}
+
+			if prune {
+				for _, p := range existing {
+					if imported[p.Name] {
+						continue
+					}
+					if err := client.RemovePreset(ctx, profile.Token, p.Token); err != nil {
+						return fmt.Errorf("删除预置位 %s 失败: %w", presetLabel(p), err)
+					}
+					results = append(results, presetImportOutput{Name: p.Name, Token: p.Token, Result: "removed"})
+				}
+			}
+
+			return render(results, func() { printPresetImport(results) })
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "presets.yaml", "预置位文件")
+	cmd.Flags().BoolVar(&prune, "prune", false, "删除设备上文件中没有的预置位")
+	cmd.Flags().DurationVar(&wait.timeout, "wait-timeout", 30*time.Second, "移动到每个预置位位置的最长等待时间")
+	cmd.Flags().Float64Var(&wait.tolerance, "tolerance", 0.01, "判断到达预置位位置的坐标容差")
+
+	return cmd
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"onvifctl/onvif"
+)
+
+// 巡逻脚本: 由客户端依次执行的云台步骤, 不依赖设备的巡航功能
+type patrolScript struct {
+	Repeat      int             `yaml:"repeat"`      // 整个脚本的执行轮数, 0 表示一直运行
+	Schedule    *patrolSchedule `yaml:"schedule"`    // 运行时间窗, 为空时不限制
+	WaitTimeout time.Duration   `yaml:"waitTimeout"` // wait 的最长等待时间, 默认 30s
+	Tolerance   float64         `yaml:"tolerance"`   // wait 判断到达目标位置的坐标容差, 默认 0.01
+	Steps       []patrolStep    `yaml:"steps"`
+}
+
+// 运行时间窗 (本地时间), end 早于 start 时跨越午夜
+type patrolSchedule struct {
+	Start string   `yaml:"start"` // HH:MM
+	End   string   `yaml:"end"`   // HH:MM
+	Days  []string `yaml:"days"`  // mon、tue ... sun, 为空时每天; 跨午夜的时间窗按开始的那天计算
+
+	start, end int // 距午夜的分钟数
+	days       map[time.Weekday]bool
+}
+
+// 巡逻步骤, 每步只能指定一种操作
+type patrolStep struct {
+	Goto     string          `yaml:"goto"`     // 转到预置位 (Token 或名称)
+	Absolute *patrolPosition `yaml:"absolute"` // 绝对移动
+	Move     *patrolMove     `yaml:"move"`     // 连续移动一段时间后停止
+	WaitIdle *time.Duration  `yaml:"waitIdle"` // 等待云台停止, 值为最长等待时间 (0 表示使用 waitTimeout)
+	Dwell    time.Duration   `yaml:"dwell"`    // 停留
+	Snapshot string          `yaml:"snapshot"` // 抓图保存路径, 支持 {time}、{loop}、{preset}
+	Stop     bool            `yaml:"stop"`     // 停止移动
+	Loop     *patrolLoop     `yaml:"loop"`     // 重复执行一组步骤
+
+	Wait bool `yaml:"wait"` // goto/absolute 后等待云台到达
+}
+
+// 绝对移动的目标位置, 未指定的轴保持不动
+type patrolPosition struct {
+	Pan       *float64 `yaml:"pan"`
+	Tilt      *float64 `yaml:"tilt"`
+	Zoom      *float64 `yaml:"zoom"`
+	Speed     *float64 `yaml:"speed"`
+	ZoomSpeed *float64 `yaml:"zoomSpeed"`
+	Space     string   `yaml:"space"` // generic (默认)、degrees 或坐标空间 URI
+}
+
+// 连续移动的速度 (-1.0 到 1.0) 和持续时间
+type patrolMove struct {
+	Pan      float64       `yaml:"pan"`
+	Tilt     float64       `yaml:"tilt"`
+	Zoom     float64       `yaml:"zoom"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+type patrolLoop struct {
+	Repeat int          `yaml:"repeat"`
+	Steps  []patrolStep `yaml:"steps"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// 读取并检查巡逻脚本
+func loadPatrolScript(path string) (*patrolScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	var script patrolScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("解析巡逻脚本失败: %w", err)
+	}
+
+	if script.Repeat < 0 {
+		return nil, fmt.Errorf("repeat 不能为负数")
+	}
+	if script.WaitTimeout < 0 {
+		return nil, fmt.Errorf("waitTimeout 不能为负数")
+	}
+	if script.WaitTimeout == 0 {
+		script.WaitTimeout = 30 * time.Second
+	}
+	if script.Tolerance <= 0 {
+		script.Tolerance = 0.01
+	}
+
+	if script.Schedule != nil {
+		if err := script.Schedule.parse(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkPatrolSteps(script.Steps, "steps"); err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
+
+// 检查每一步只指定了一种操作且参数有效, where 用于错误信息中的位置
+func checkPatrolSteps(steps []patrolStep, where string) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%s 中没有步骤", where)
+	}
+
+	for i, step := range steps {
+		at := fmt.Sprintf("%s 第 %d 步", where, i+1)
+
+		actions := 0
+		for _, set := range []bool{
+			step.Goto != "", step.Absolute != nil, step.Move != nil, step.WaitIdle != nil,
+			step.Dwell != 0, step.Snapshot != "", step.Stop, step.Loop != nil,
+		} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("%s 必须且只能指定一种操作 (goto, absolute, move, waitIdle, dwell, snapshot, stop, loop)", at)
+		}
+		if step.Wait && step.Goto == "" && step.Absolute == nil {
+			return fmt.Errorf("%s: wait 只能用于 goto 和 absolute", at)
+		}
+
+		switch {
+		case step.Absolute != nil:
+			a := step.Absolute
+			if (a.Pan == nil) != (a.Tilt == nil) {
+				return fmt.Errorf("%s: 绝对移动需要同时指定 pan 和 tilt", at)
+			}
+			if a.Pan == nil && a.Zoom == nil {
+				return fmt.Errorf("%s: 绝对移动必须指定 pan/tilt 或 zoom", at)
+			}
+			if a.Space == "" {
+				a.Space = "generic"
+			}
+			if _, _, err := ptzSpaces(a.Space, true); err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+		case step.Move != nil:
+			if step.Move.Duration <= 0 {
+				return fmt.Errorf("%s: 连续移动必须指定大于 0 的 duration", at)
+			}
+		case step.WaitIdle != nil:
+			if *step.WaitIdle < 0 {
+				return fmt.Errorf("%s: waitIdle 不能为负数", at)
+			}
+		case step.Dwell < 0:
+			return fmt.Errorf("%s: dwell 不能为负数", at)
+		case step.Loop != nil:
+			if step.Loop.Repeat < 1 {
+				return fmt.Errorf("%s: loop 的 repeat 必须大于 0", at)
+			}
+			if err := checkPatrolSteps(step.Loop.Steps, at+" 的 loop"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 步骤中是否有会等待的操作 (dwell、waitIdle、wait 或 move 的持续时间)
+// repeat 为 0 时脚本一直运行, 没有这类步骤会不间断地向设备发送请求
+func patrolBlocks(steps []patrolStep) bool {
+	for _, step := range steps {
+		switch {
+		case step.Dwell > 0, step.WaitIdle != nil, step.Wait, step.Move != nil:
+			return true
+		case step.Loop != nil && patrolBlocks(step.Loop.Steps):
+			return true
+		}
+	}
+	return false
+}
+
+// 解析 HH:MM
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("无效的时间: %q (格式为 HH:MM)", s)
+	}
+	return hour*60 + minute, nil
+}
+
+func (s *patrolSchedule) parse() error {
+	var err error
+	if s.start, err = parseClock(s.Start); err != nil {
+		return fmt.Errorf("schedule.start: %w", err)
+	}
+	if s.end, err = parseClock(s.End); err != nil {
+		return fmt.Errorf("schedule.end: %w", err)
+	}
+	if s.start == s.end {
+		return fmt.Errorf("schedule 的开始和结束时间不能相同")
+	}
+
+	s.days = make(map[time.Weekday]bool)
+	for _, day := range s.Days {
+		wd, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return fmt.Errorf("schedule.days 中有无效的星期: %s (支持: mon, tue, wed, thu, fri, sat, sun)", day)
+		}
+		s.days[wd] = true
+	}
+
+	return nil
+}
+
+// 时间窗是否在 day 这天开始
+func (s *patrolSchedule) startsOn(day time.Weekday) bool {
+	return len(s.days) == 0 || s.days[day]
+}
+
+// 判断 t 是否在时间窗内
+func (s *patrolSchedule) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+
+	if s.start < s.end {
+		return s.startsOn(t.Weekday()) && minute >= s.start && minute < s.end
+	}
+
+	// 跨午夜: 午夜前属于当天开始的时间窗, 午夜后属于前一天开始的时间窗
+	if minute >= s.start {
+		return s.startsOn(t.Weekday())
+	}
+	return minute < s.end && s.startsOn(t.AddDate(0, 0, -1).Weekday())
+}
+
+// t 之后时间窗下一次开始的时刻
+func (s *patrolSchedule) next(t time.Time) time.Time {
+	for i := 0; i <= 7; i++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+i, s.start/60, s.start%60, 0, 0, t.Location())
+		if start.After(t) && s.startsOn(start.Weekday()) {
+			return start
+		}
+	}
+	return t
+}
+
+// 超出运行时间窗, 当前一轮在步骤之间中止
+var errOutsideSchedule = errors.New("超出运行时间窗")
+
+// 巡逻脚本的执行状态
+type patrolRunner struct {
+	client       *onvif.Client
+	profileToken string
+	presets      []onvif.PTZPreset
+	wait         ptzWaitArgs
+	schedule     *patrolSchedule // 每步执行前检查, 为 nil 时不限制
+
+	loop      int    // 当前轮次 (从 1 开始)
+	preset    string // 最近转到的预置位名称, 用于抓图文件名
+	steps     int
+	snapshots []string
+}
+
+// 执行前检查: 预置位必须存在, 绝对移动按设备声明的范围校验
+func (r *patrolRunner) check(ctx context.Context, profile onvif.Profile, steps []patrolStep) error {
+	var options *onvif.PTZConfigurationOptions
+	optionsLoaded := false
+
+	var walk func(steps []patrolStep) error
+	walk = func(steps []patrolStep) error {
+		for _, step := range steps {
+			switch {
+			case step.Goto != "":
+				_, ok, err := onvif.FindPreset(r.presets, step.Goto)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("未找到预置位: %s (使用 ptz preset list 查看)", step.Goto)
+				}
+			case step.Absolute != nil:
+				if !optionsLoaded {
+					optionsLoaded = true
+					options = patrolOptions(ctx, r.client, profile)
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+				}
+				if options == nil {
+					continue
+				}
+				target, speed := step.Absolute.vectors()
+				if err := options.ValidatePosition(target); err != nil {
+					return err
+				}
+				if speed != nil {
+					if err := options.ValidateSpeed(*speed); err != nil {
+						return err
+					}
+				}
+			case step.Loop != nil:
+				if err := walk(step.Loop.Steps); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return walk(steps)
+}
+
+// 获取 PTZ 配置选项用于校验, 无法获取时给出警告并返回 nil
+func patrolOptions(ctx context.Context, client *onvif.Client, profile onvif.Profile) *onvif.PTZConfigurationOptions {
+	if profile.PTZConfiguration == nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Profile %s 未声明 PTZ 配置, 跳过坐标范围校验\n", profile.Token)
+		return nil
+	}
+
+	options, err := client.GetConfigurationOptions(ctx, profile.PTZConfiguration.Token)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "⚠️  获取 PTZ 配置选项失败, 跳过坐标范围校验: %v\n", err)
+		}
+		return nil
+	}
+
+	return options
+}
+
+// 绝对移动的目标位置和速度
+func (p *patrolPosition) vectors() (onvif.PTZVector, *onvif.PTZVector) {
+	panTiltSpace, zoomSpace, _ := ptzSpaces(p.Space, true)
+
+	var target onvif.PTZVector
+	if p.Pan != nil {
+		target.PanTilt = &onvif.PanTilt{X: *p.Pan, Y: *p.Tilt, Space: panTiltSpace}
+	}
+	if p.Zoom != nil {
+		target.Zoom = &onvif.Zoom{X: *p.Zoom, Space: zoomSpace}
+	}
+
+	var speed *onvif.PTZVector
+	if p.Speed != nil && target.PanTilt != nil {
+		speed = &onvif.PTZVector{PanTilt: &onvif.PanTilt{X: *p.Speed, Y: *p.Speed}}
+	}
+	if p.ZoomSpeed != nil && target.Zoom != nil {
+		if speed == nil {
+			speed = &onvif.PTZVector{}
+		}
+		speed.Zoom = &onvif.Zoom{X: *p.ZoomSpeed}
+	}
+
+	return target, speed
+}
+
+// 依次执行步骤, 超出运行时间窗时返回 errOutsideSchedule
+func (r *patrolRunner) run(ctx context.Context, steps []patrolStep) error {
+	for _, step := range steps {
+		if r.schedule != nil && !r.schedule.contains(time.Now()) {
+			return errOutsideSchedule
+		}
+		if err := r.step(ctx, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *patrolRunner) step(ctx context.Context, step patrolStep) error {
+	switch {
+	case step.Loop != nil:
+		for i := 1; i <= step.Loop.Repeat; i++ {
+			if err := r.run(ctx, step.Loop.Steps); err != nil {
+				return err
+			}
+		}
+		return nil
+	case step.Goto != "":
+		p, _, _ := onvif.FindPreset(r.presets, step.Goto)
+		statusf("转到预置位 %s\n", presetLabel(p))
+		if err := r.client.GotoPreset(ctx, r.profileToken, p.Token); err != nil {
+			return fmt.Errorf("转到预置位 %s 失败: %w", presetLabel(p), err)
+		}
+		r.preset = p.Name
+		if p.Name == "" {
+			r.preset = p.Token
+		}
+		if step.Wait {
+			if _, err := r.wait.run(ctx, r.client, r.profileToken, presetVector(p)); err != nil {
+				return err
+			}
+		}
+	case step.Absolute != nil:
+		target, speed := step.Absolute.vectors()
+		statusf("绝对移动到%s\n", describeVector(target))
+		if err := r.client.AbsoluteMove(ctx, r.profileToken, target, speed); err != nil {
+			return fmt.Errorf("PTZ 移动失败: %w", err)
+		}
+		if step.Wait {
+			// 设备报告的位置使用通用坐标空间, 其它坐标空间只按移动状态判断
+			var waitTarget *onvif.PTZVector
+			if step.Absolute.Space == "generic" {
+				waitTarget = &target
+			}
+			if _, err := r.wait.run(ctx, r.client, r.profileToken, waitTarget); err != nil {
+				return err
+			}
+		}
+	case step.Move != nil:
+		m := step.Move
+		statusf("连续移动 %s (水平 %g, 垂直 %g, 缩放 %g)\n", m.Duration, m.Pan, m.Tilt, m.Zoom)
+		// 同时把持续时间作为设备端超时, 客户端中断时设备也会自行停止
+		if err := r.client.ContinuousMove(ctx, r.profileToken, m.Pan, m.Tilt, m.Zoom, m.Duration); err != nil {
+			return fmt.Errorf("PTZ 移动失败: %w", err)
+		}
+		sleepContext(ctx, m.Duration)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.client.Stop(ctx, r.profileToken); err != nil {
+			return fmt.Errorf("PTZ 停止失败: %w", err)
+		}
+	case step.WaitIdle != nil:
+		w := r.wait
+		if *step.WaitIdle > 0 {
+			w.timeout = *step.WaitIdle
+		}
+		if _, err := w.run(ctx, r.client, r.profileToken, nil); err != nil {
+			return err
+		}
+	case step.Dwell != 0:
+		statusf("停留 %s\n", step.Dwell)
+		sleepContext(ctx, step.Dwell)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	case step.Snapshot != "":
+		if err := r.snapshot(ctx, step.Snapshot); err != nil {
+			return err
+		}
+	case step.Stop:
+		statusf("停止移动\n")
+		if err := r.client.Stop(ctx, r.profileToken); err != nil {
+			return fmt.Errorf("PTZ 停止失败: %w", err)
+		}
+	}
+
+	r.steps++
+	return nil
+}
+
+// 抓图并按模板保存, 自动创建目录
+func (r *patrolRunner) snapshot(ctx context.Context, pattern string) error {
+	preset := strings.NewReplacer("/", "_", `\`, "_").Replace(r.preset)
+	path := strings.NewReplacer(
+		"{time}", time.Now().Format("20060102-150405"),
+		"{loop}", strconv.Itoa(r.loop),
+		"{preset}", preset,
+	).Replace(pattern)
+
+	data, err := r.client.GetSnapshot(ctx, r.profileToken)
+	if err != nil {
+		return fmt.Errorf("抓图失败: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存图像失败: %w", err)
+	}
+
+	statusf("抓图已保存到 %s (%d 字节)\n", path, len(data))
+	r.snapshots = append(r.snapshots, path)
+
+	return nil
+}
+
+// 位置的简短描述
+func describeVector(v onvif.PTZVector) string {
+	var parts []string
+	if pt := v.PanTilt; pt != nil {
+		parts = append(parts, fmt.Sprintf("水平 %g, 垂直 %g", pt.X, pt.Y))
+	}
+	if z := v.Zoom; z != nil {
+		parts = append(parts, fmt.Sprintf("缩放 %g", z.X))
+	}
+	return " " + strings.Join(parts, ", ")
+}
+
+func ptzRunCmd() *cobra.Command {
+	var (
+		check  bool
+		repeat int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run SCRIPT",
+		Short: "执行客户端巡逻脚本",
+		Long: `按 YAML 脚本依次执行云台步骤, 由客户端控制, 适用于不支持预置位巡航的设备
+
+脚本格式:
+
+  repeat: 0                 # 执行轮数, 0 表示一直运行
+  waitTimeout: 30s          # wait 的最长等待时间
+  tolerance: 0.01           # wait 判断到达目标位置的坐标容差
+  schedule:                 # 运行时间窗 (本地时间, 可跨午夜), 超出时在步骤之间中止本轮
+    start: "08:00"
+    end: "20:00"
+    days: [mon, tue, wed, thu, fri]
+  steps:
+    - goto: Door            # 转到预置位 (Token 或名称)
+      wait: true            # 等待到达
+    - snapshot: snaps/{preset}-{time}.jpg
+    - absolute: {pan: 0.5, tilt: 0, zoom: 0.2, speed: 0.5}
+      wait: true
+    - dwell: 10s            # 停留
+    - move: {pan: 0.3, duration: 800ms}   # 连续移动一段时间后停止
+    - waitIdle: 10s         # 等待云台停止
+    - stop: true
+    - loop:                 # 重复一组步骤
+        repeat: 3
+        steps:
+          - move: {zoom: 0.5, duration: 500ms}
+          - move: {zoom: -0.5, duration: 500ms}
+
+执行前检查脚本中的预置位是否存在, 绝对移动按设备声明的范围校验
+repeat 为 0 时脚本中必须有 dwell、waitIdle、wait 或 move 步骤
+按 Ctrl-C 或出错结束时总是发送 Stop 停止云台`,
+		Example: `  # 检查脚本
+  onvifctl ptz run patrol.yaml --check --device lobby
+
+  # 执行脚本
+  onvifctl ptz run patrol.yaml --device lobby`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			script, err := loadPatrolScript(args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("repeat") {
+				if repeat < 0 {
+					return fmt.Errorf("--repeat 不能为负数")
+				}
+				script.Repeat = repeat
+			}
+			if script.Repeat == 0 && !patrolBlocks(script.Steps) {
+				return fmt.Errorf("repeat 为 0 (一直运行) 时脚本中必须有 dwell、waitIdle、wait 或 move 步骤, 否则会不间断地向设备发送请求")
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取预置位列表失败: %w", err)
+			}
+
+			r := &patrolRunner{
+				client:       client,
+				profileToken: profile.Token,
+				presets:      presets,
+				wait:         ptzWaitArgs{wait: true, timeout: script.WaitTimeout, tolerance: script.Tolerance},
+				schedule:     script.Schedule,
+				snapshots:    []string{},
+			}
+			if err := r.check(ctx, profile, script.Steps); err != nil {
+				return err
+			}
+
+			if check {
+				statusf("✓ 脚本检查通过\n")
+				return nil
+			}
+
+			loops, runErr := 0, error(nil)
+			for loop := 1; script.Repeat == 0 || loop <= script.Repeat; {
+				if s := script.Schedule; s != nil && !s.contains(time.Now()) {
+					next := s.next(time.Now())
+					statusf("不在运行时间窗内, 等待到 %s\n", next.Format("2006-01-02 15:04"))
+					sleepContext(ctx, time.Until(next))
+					if runErr = ctx.Err(); runErr != nil {
+						break
+					}
+				}
+
+				statusf("=== 第 %d 轮 ===\n", loop)
+				r.loop = loop
+				runErr = r.run(ctx, script.Steps)
+				if errors.Is(runErr, errOutsideSchedule) {
+					// 中止的一轮不计入轮数, 下次进入时间窗时重新执行
+					statusf("已超出运行时间窗, 中止第 %d 轮\n", loop)
+					runErr = nil
+					continue
+				}
+				if runErr != nil {
+					break
+				}
+				loops = loop
+				loop++
+			}
+
+			// 被中断或出错时云台可能仍在移动, 使用新的 ctx 发送 Stop
+			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := client.Stop(stopCtx, profile.Token); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  停止云台失败: %v\n", err)
+			} else if runErr != nil {
+				statusf("✓ 云台已停止\n")
+			}
+
+			if runErr != nil {
+				statusf("已完成 %d 轮, 执行 %d 步, 抓图 %d 张\n", loops, r.steps, len(r.snapshots))
+				return runErr
+			}
+
+			result := ptzRunOutput{
+				Script:       args[0],
+				ProfileToken: profile.Token,
+				Loops:        loops,
+				Steps:        r.steps,
+				Snapshots:    r.snapshots,
+			}
+			return render(result, func() {
+				fmt.Printf("✓ 巡逻完成: %d 轮, 执行 %d 步\n", loops, r.steps)
+				if len(r.snapshots) > 0 {
+					fmt.Printf("  抓图 %d 张\n", len(r.snapshots))
+				}
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "只检查脚本和预置位, 不移动云台")
+	cmd.Flags().IntVar(&repeat, "repeat", 0, "覆盖脚本中的执行轮数 (0 表示一直运行)")
+
+	return cmd
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 解析时间窗, 解析失败时测试失败
+func mustSchedule(t *testing.T, start, end string, days ...string) *patrolSchedule {
+	t.Helper()
+	s := &patrolSchedule{Start: start, End: end, Days: days}
+	if err := s.parse(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// 2026-10-16 为星期五
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestPatrolScheduleContains(t *testing.T) {
+	fridayNight := mustSchedule(t, "22:00", "06:00", "fri")
+	mondayDay := mustSchedule(t, "08:00", "18:00", "mon")
+	sundayNight := mustSchedule(t, "22:00", "06:00", "Sun")
+	everyNight := mustSchedule(t, "23:30", "00:30")
+
+	tests := []struct {
+		name     string
+		schedule *patrolSchedule
+		t        time.Time
+		want     bool
+	}{
+		{"跨午夜: 开始前", fridayNight, at(16, 21, 59), false},
+		{"跨午夜: 开始时刻包含在内", fridayNight, at(16, 22, 0), true},
+		{"跨午夜: 午夜后属于前一天的时间窗", fridayNight, at(17, 3, 0), true},
+		{"跨午夜: 结束时刻不包含", fridayNight, at(17, 6, 0), false},
+		{"跨午夜: 当天不在 days 中", fridayNight, at(17, 23, 0), false},
+		{"跨午夜: 前一天不在 days 中", fridayNight, at(18, 3, 0), false},
+		{"星期回绕: 周日开始的时间窗延续到周一", sundayNight, at(19, 2, 0), true},
+		{"星期回绕: 周日凌晨属于周六的时间窗", sundayNight, at(18, 2, 0), false},
+		{"不跨午夜: 开始时刻", mondayDay, at(19, 8, 0), true},
+		{"不跨午夜: 结束前一分钟", mondayDay, at(19, 17, 59), true},
+		{"不跨午夜: 结束时刻", mondayDay, at(19, 18, 0), false},
+		{"不跨午夜: 其它日期", mondayDay, at(18, 12, 0), false},
+		{"每天: 午夜后", everyNight, at(17, 0, 15), true},
+		{"每天: 结束时刻", everyNight, at(17, 0, 30), false},
+		{"每天: 时间窗外", everyNight, at(17, 12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.contains(tt.t); got != tt.want {
+				t.Errorf("contains(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatrolScheduleNext(t *testing.T) {
+	fridayNight := mustSchedule(t, "22:00", "06:00", "fri")
+	mondayDay := mustSchedule(t, "08:00", "18:00", "mon")
+	sundayNight := mustSchedule(t, "22:00", "06:00", "sun")
+	everyNight := mustSchedule(t, "23:30", "00:30")
+
+	tests := []struct {
+		name     string
+		schedule *patrolSchedule
+		t        time.Time
+		want     time.Time
+	}{
+		{"时间窗内: 下一次为下周", fridayNight, at(16, 23, 0), at(23, 22, 0)},
+		{"跨午夜的后半段", fridayNight, at(17, 3, 0), at(23, 22, 0)},
+		{"当天稍后", mondayDay, at(19, 7, 0), at(19, 8, 0)},
+		{"恰好在开始时刻: 下一次为下周", mondayDay, at(19, 8, 0), at(26, 8, 0)},
+		{"当天已结束: 第七天", mondayDay, at(19, 19, 0), at(26, 8, 0)},
+		{"星期回绕", sundayNight, at(17, 12, 0), at(18, 22, 0)},
+		{"每天", everyNight, at(17, 23, 45), at(18, 23, 30)},
+		{"跨月", everyNight, at(31, 23, 45), time.Date(2026, 11, 1, 23, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.next(tt.t)
+			if !got.Equal(tt.want) {
+				t.Errorf("next(%s) = %s, want %s", tt.t.Format("Mon 01-02 15:04"), got.Format("Mon 01-02 15:04"), tt.want.Format("Mon 01-02 15:04"))
+			}
+			if !tt.schedule.contains(got) {
+				t.Errorf("next 返回的时刻 %s 不在时间窗内", got.Format("Mon 01-02 15:04"))
+			}
+		})
+	}
+}
+
+func TestPatrolScheduleParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		days       []string
+		wantErr    string
+	}{
+		{"小时超出", "24:00", "06:00", nil, "schedule.start"},
+		{"缺少分钟", "22:00", "6", nil, "schedule.end"},
+		{"开始和结束相同", "08:00", "08:00", nil, "不能相同"},
+		{"无效的星期", "08:00", "18:00", []string{"mon", "funday"}, "funday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &patrolSchedule{Start: tt.start, End: tt.end, Days: tt.days}
+			if err := s.parse(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want 包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 从 YAML 解析步骤列表
+func parseSteps(t *testing.T, data string) []patrolStep {
+	t.Helper()
+	var steps []patrolStep
+	if err := yaml.Unmarshal([]byte(data), &steps); err != nil {
+		t.Fatal(err)
+	}
+	return steps
+}
+
+func TestCheckPatrolSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   string
+		wantErr string // 为空表示应通过检查
+	}{
+		{"有效脚本", `
+- goto: Door
+  wait: true
+- absolute: {pan: 0.1, tilt: 0.2, space: degrees}
+- move: {pan: 0.5, duration: 2s}
+- waitIdle: 0s
+- loop:
+    repeat: 2
+    steps:
+      - dwell: 5s
+      - snapshot: "{preset}.jpg"
+- stop: true`, ""},
+		{"没有步骤", `[]`, "没有步骤"},
+		{"一步中有两种操作", `[{goto: Door, dwell: 1s}]`, "只能指定一种操作"},
+		{"没有操作", `[{wait: true}]`, "只能指定一种操作"},
+		{"wait 用于 dwell", `[{dwell: 1s, wait: true}]`, "wait 只能用于"},
+		{"只指定 pan", `[{absolute: {pan: 0.1}}]`, "同时指定 pan 和 tilt"},
+		{"绝对移动没有坐标", `[{absolute: {speed: 0.5}}]`, "必须指定 pan/tilt 或 zoom"},
+		{"未知的坐标空间", `[{absolute: {zoom: 0.5, space: radians}}]`, "未知的坐标空间"},
+		{"连续移动没有持续时间", `[{move: {pan: 0.5}}]`, "duration"},
+		{"dwell 为负数", `[{dwell: -1s}]`, "dwell 不能为负数"},
+		{"waitIdle 为负数", `[{waitIdle: -1s}]`, "waitIdle 不能为负数"},
+		{"loop 的 repeat 为 0", `[{loop: {repeat: 0, steps: [{dwell: 1s}]}}]`, "repeat 必须大于 0"},
+		{"loop 中的错误带有位置", `[{stop: true}, {loop: {repeat: 2, steps: [{dwell: 1s}, {goto: A, stop: true}]}}]`,
+			"steps 第 2 步 的 loop 第 2 步"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPatrolSteps(parseSteps(t, tt.steps), "steps")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want 包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatrolBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		want  bool
+	}{
+		{"goto 不等待", `[{goto: Door}, {snapshot: a.jpg}]`, false},
+		{"goto 等待", `[{goto: Door, wait: true}]`, true},
+		{"absolute 等待", `[{absolute: {zoom: 0.5}, wait: true}]`, true},
+		{"dwell", `[{goto: Door}, {dwell: 10s}]`, true},
+		{"waitIdle 为 0 也会等待", `[{waitIdle: 0s}]`, true},
+		{"move", `[{move: {pan: 0.5, duration: 1s}}]`, true},
+		{"只有 stop 和 snapshot", `[{stop: true}, {snapshot: a.jpg}]`, false},
+		{"loop 中没有等待的步骤", `[{loop: {repeat: 3, steps: [{goto: A}, {goto: B}]}}]`, false},
+		{"loop 中有 dwell", `[{goto: A}, {loop: {repeat: 3, steps: [{goto: B}, {dwell: 1s}]}}]`, true},
+		{"嵌套 loop 中有 move", `[{loop: {repeat: 2, steps: [{loop: {repeat: 2, steps: [{move: {tilt: 1, duration: 1s}}]}}]}}]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := parseSteps(t, tt.steps)
+			if err := checkPatrolSteps(steps, "steps"); err != nil {
+				t.Fatalf("测试脚本无效: %v", err)
+			}
+			if got := patrolBlocks(steps); got != tt.want {
+				t.Errorf("patrolBlocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"onvifctl/onvif"
+)
+
+// 巡航定义文件, apply 时按名称匹配设备上的巡航
+type tourFile struct {
+	Tours []tourDef `yaml:"tours"`
+}
+
+// 巡航定义
+type tourDef struct {
+	Name              string        `yaml:"name"`
+	AutoStart         bool          `yaml:"autoStart"`
+	Direction         string        `yaml:"direction"` // Forward 或 Backward, 为空时由设备决定
+	Random            bool          `yaml:"random"`    // 随机顺序转到各巡航点
+	RecurringTime     int           `yaml:"recurringTime"`
+	RecurringDuration time.Duration `yaml:"recurringDuration"`
+	Spots             []tourSpotDef `yaml:"spots"`
+}
+
+// 巡航点: 预置位 (Token 或名称) 或原点, 停留时间和移动速度
+type tourSpotDef struct {
+	Preset    string        `yaml:"preset"`
+	Home      bool          `yaml:"home"`
+	Dwell     time.Duration `yaml:"dwell"`
+	Speed     *float64      `yaml:"speed"`
+	ZoomSpeed *float64      `yaml:"zoomSpeed"`
+}
+
+// 读取并检查巡航定义文件
+func loadTourFile(path string) (*tourFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	var tf tourFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("解析巡航文件失败: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for _, def := range tf.Tours {
+		if def.Name == "" {
+			return nil, fmt.Errorf("巡航文件中有未命名的巡航")
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("巡航文件中有重名的巡航: %s", def.Name)
+		}
+		names[def.Name] = true
+
+		switch def.Direction {
+		case "", "Forward", "Backward":
+		default:
+			return nil, fmt.Errorf("巡航 %s 的方向无效: %s (支持: Forward, Backward)", def.Name, def.Direction)
+		}
+		if def.RecurringTime < 0 || def.RecurringDuration < 0 {
+			return nil, fmt.Errorf("巡航 %s 的循环次数和时长不能为负数", def.Name)
+		}
+		if len(def.Spots) == 0 {
+			return nil, fmt.Errorf("巡航 %s 没有巡航点", def.Name)
+		}
+
+		for i, spot := range def.Spots {
+			if (spot.Preset == "") == !spot.Home {
+				return nil, fmt.Errorf("巡航 %s 的第 %d 个巡航点必须且只能指定 preset 或 home 之一", def.Name, i+1)
+			}
+			if spot.Dwell < 0 {
+				return nil, fmt.Errorf("巡航 %s 的第 %d 个巡航点停留时间不能为负数", def.Name, i+1)
+			}
+		}
+	}
+
+	return &tf, nil
+}
+
+// 将巡航定义转换为 ModifyPresetTour 的内容, 预置位名称按 presets 解析为 Token
+func (def tourDef) presetTour(presets []onvif.PTZPreset) (onvif.PresetTour, error) {
+	tour := onvif.PresetTour{
+		Name:      def.Name,
+		Status:    onvif.PTZPresetTourStatus{State: "Idle"},
+		AutoStart: def.AutoStart,
+		StartingCondition: onvif.PTZPresetTourStartingCondition{
+			RandomPresetOrder: def.Random,
+			RecurringTime:     def.RecurringTime,
+			Direction:         def.Direction,
+		},
+	}
+	if def.RecurringDuration > 0 {
+		tour.StartingCondition.RecurringDuration = onvif.XSDDuration(def.RecurringDuration)
+	}
+
+	for _, spot := range def.Spots {
+		var ts onvif.PTZPresetTourSpot
+
+		if spot.Home {
+			ts.PresetDetail.Home = true
+		} else {
+			p, ok, err := onvif.FindPreset(presets, spot.Preset)
+			if err != nil {
+				return onvif.PresetTour{}, fmt.Errorf("巡航 %s: %w", def.Name, err)
+			}
+			if !ok {
+				return onvif.PresetTour{}, fmt.Errorf("巡航 %s: 未找到预置位 %s (使用 ptz preset list 查看)", def.Name, spot.Preset)
+			}
+			ts.PresetDetail.PresetToken = p.Token
+		}
+
+		if spot.Speed != nil {
+			ts.Speed = &onvif.PTZVector{PanTilt: &onvif.PanTilt{X: *spot.Speed, Y: *spot.Speed}}
+		}
+		if spot.ZoomSpeed != nil {
+			if ts.Speed == nil {
+				ts.Speed = &onvif.PTZVector{}
+			}
+			ts.Speed.Zoom = &onvif.Zoom{X: *spot.ZoomSpeed}
+		}
+		if spot.Dwell > 0 {
+			ts.StayTime = onvif.XSDDuration(spot.Dwell)
+		}
+
+		tour.TourSpots = append(tour.TourSpots, ts)
+	}
+
+	return tour, nil
+}
+
+// 按 Token 或名称查找巡航, 未找到时返回错误
+func findTour(ctx context.Context, client *onvif.Client, profileToken, ref string) (onvif.PresetTour, error) {
+	tours, err := client.GetPresetTours(ctx, profileToken)
+	if err != nil {
+		return onvif.PresetTour{}, fmt.Errorf("获取巡航列表失败: %w", err)
+	}
+
+	t, ok, err := onvif.FindPresetTour(tours, ref)
+	if err != nil {
+		return onvif.PresetTour{}, err
+	}
+	if !ok {
+		return onvif.PresetTour{}, fmt.Errorf("未找到巡航: %s (使用 ptz tour list 查看)", ref)
+	}
+
+	return t, nil
+}
+
+// 巡航的显示名称
+func tourLabel(t onvif.PresetTour) string {
+	if t.Name == "" || t.Name == t.Token {
+		return t.Token
+	}
+	return fmt.Sprintf("%s (Token: %s)", t.Name, t.Token)
+}
+
+func ptzTourCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tour",
+		Short: "预置位巡航管理",
+		Long: `管理设备端的预置位巡航 (ONVIF PTZ 2.x), 巡航可以用 Token 或名称指定 (Token 优先)
+
+巡航定义文件格式:
+
+  tours:
+    - name: Perimeter
+      autoStart: false
+      direction: Forward      # Forward 或 Backward
+      random: false           # 随机顺序
+      recurringTime: 0        # 循环次数
+      recurringDuration: 1h   # 循环总时长
+      spots:
+        - preset: Door        # 预置位 Token 或名称
+          dwell: 10s          # 停留时间
+          speed: 0.5          # 水平/垂直移动速度
+          zoomSpeed: 0.5      # 缩放速度
+        - home: true          # 原点位置
+          dwell: 5s`,
+		Example: `  # 按文件创建或更新巡航
+  onvifctl ptz tour apply -f tours.yaml --device lobby
+
+  # 开始/暂停/停止巡航
+  onvifctl ptz tour start Perimeter --device lobby
+  onvifctl ptz tour stop Perimeter --device lobby`,
+	}
+
+	cmd.AddCommand(ptzTourListCmd())
+	cmd.AddCommand(ptzTourApplyCmd())
+	cmd.AddCommand(ptzTourOperateCmd("start", onvif.PresetTourStart, "开始巡航"))
+	cmd.AddCommand(ptzTourOperateCmd("stop", onvif.PresetTourStop, "停止巡航"))
+	cmd.AddCommand(ptzTourOperateCmd("pause", onvif.PresetTourPause, "暂停巡航"))
+	cmd.AddCommand(ptzTourRemoveCmd())
+
+	return cmd
+}
+
+func ptzTourListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出预置位巡航",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			tours, err := client.GetPresetTours(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取巡航列表失败: %w", err)
+			}
+
+			// 预置位名称仅用于显示, 获取失败时只显示 Token
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Fprintf(os.Stderr, "⚠️  获取预置位列表失败, 只显示预置位 Token: %v\n", err)
+			}
+
+			out := newTourOutputs(tours, presets)
+			return render(out, func() { printTours(out) })
+		},
+	}
+}
+
+func ptzTourApplyCmd() *cobra.Command {
+	var (
+		file  string
+		prune bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "按 YAML 文件创建或更新巡航",
+		Long: `按巡航定义文件创建或更新巡航 (文件格式见 ptz tour --help)
+
+设备上已有同名巡航时原地修改, 否则新建; 巡航点中的预置位名称按设备上的预置位解析为 Token
+--prune 删除设备上文件中没有的巡航`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tf, err := loadTourFile(file)
+			if err != nil {
+				return err
+			}
+
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			presets, err := client.GetPresets(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取预置位列表失败: %w", err)
+			}
+
+			// 先解析全部巡航, 避免预置位缺失时只应用了一部分
+			tours := make([]onvif.PresetTour, len(tf.Tours))
+			for i, def := range tf.Tours {
+				if tours[i], err = def.presetTour(presets); err != nil {
+					return err
+				}
+			}
+
+			existing, err := client.GetPresetTours(ctx, profile.Token)
+			if err != nil {
+				return fmt.Errorf("获取巡航列表失败: %w", err)
+			}
+
+			var results []tourApplyOutput
+			applied := make(map[string]bool)
+
+			for _, tour := range tours {
+				statusf("应用巡航 %s...\n", tour.Name)
+
+				matches := 0
+				for _, t := range existing {
+					if t.Name == tour.Name {
+						tour.Token = t.Token
+						matches++
+					}
+				}
+				if matches > 1 {
+					return fmt.Errorf("设备上有 %d 个巡航名为 %s, 请先删除多余的巡航", matches, tour.Name)
+				}
+
+				if matches == 1 {
+					if err := client.ModifyPresetTour(ctx, profile.Token, tour); err != nil {
+						return fmt.Errorf("修改巡航 %s 失败: %w", tour.Name, err)
+					}
+					results = append(results, tourApplyOutput{Name: tour.Name, Token: tour.Token, Result: "updated"})
+					applied[tour.Token] = true
+					continue
+				}
+
+				token, err := client.CreatePresetTour(ctx, profile.Token)
+				if err != nil {
+					return fmt.Errorf("新建巡航 %s 失败: %w", tour.Name, err)
+				}
+				tour.Token = token
+
+				if err := client.ModifyPresetTour(ctx, profile.Token, tour); err != nil {
+					// 删除刚创建的空巡航, 删除失败时提示用户手动清理
+					if rmErr := client.RemovePresetTour(ctx, profile.Token, token); rmErr != nil {
+						fmt.Fprintf(os.Stderr, "⚠️  删除未完成的巡航 %s 失败: %v\n", token, rmErr)
+					}
+					return fmt.Errorf("设置巡航 %s 失败: %w", tour.Name, err)
+				}
+				results = append(results, tourApplyOutput{Name: tour.Name, Token: token, Result: "created"})
+				applied[token] = true
+			}
+
+			if prune {
+				for _, t := range existing {
+					if applied[t.Token] {
+						continue
+					}
+					if err := client.RemovePresetTour(ctx, profile.Token, t.Token); err != nil {
+						return fmt.Errorf("删除巡航 %s 失败: %w", tourLabel(t), err)
+					}
+					results = append(results, tourApplyOutput{Name: t.Name, Token: t.Token, Result: "removed"})
+				}
+			}
+
+			return render(results, func() { printTourApply(results) })
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "tours.yaml", "巡航定义文件")
+	cmd.Flags().BoolVar(&prune, "prune", false, "删除设备上文件中没有的巡航")
+
+	return cmd
+}
+
+// start/stop/pause 子命令
+func ptzTourOperateCmd(use, operation, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " TOKEN|NAME",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			t, err := findTour(ctx, client, profile.Token, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := client.OperatePresetTour(ctx, profile.Token, t.Token, operation); err != nil {
+				return fmt.Errorf("%s失败: %w", short, err)
+			}
+
+			result := ptzActionOutput{Action: use + "tour", ProfileToken: profile.Token, TourToken: t.Token, TourName: t.Name}
+			return render(result, func() { fmt.Printf("✓ 已%s %s\n", short, tourLabel(t)) })
+		},
+	}
+}
+
+func ptzTourRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove TOKEN|NAME",
+		Aliases: []string{"rm"},
+		Short:   "删除巡航",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			profile, err := ptzProfile(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			t, err := findTour(ctx, client, profile.Token, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := client.RemovePresetTour(ctx, profile.Token, t.Token); err != nil {
+				return fmt.Errorf("删除巡航失败: %w", err)
+			}
+
+			result := ptzActionOutput{Action: "removetour", ProfileToken: profile.Token, TourToken: t.Token, TourName: t.Name}
+			return render(result, func() { fmt.Printf("✓ 已删除巡航 %s\n", tourLabel(t)) })
+		},
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式 (--output)
+const (
+	outputTable = "table" // 人类可读文本 (默认)
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV}
+
+var outputFormat string
+
+// 命令 Annotations 中的键: 该命令原有的 --output 参数 (在全局 --output 之前) 改成的新名称,
+// 用于给仍按旧用法传入文件路径的脚本提示, 例如 snapshot 的 --output 改名为 --file
+const renamedOutputFlag = "renamedOutputFlag"
+
+// 校验 --output 参数
+func validateOutputFormat(cmd *cobra.Command) error {
+	for _, f := range outputFormats {
+		if f == outputFormat {
+			return nil
+		}
+	}
+
+	if flag, ok := cmd.Annotations[renamedOutputFlag]; ok {
+		return fmt.Errorf("输出格式必须是 %s 之一\n%s 原来的 --output 已改名为 %s (--output 现在是全局输出格式), 请使用 %s %s",
+			strings.Join(outputFormats, ", "), cmd.CommandPath(), flag, flag, outputFormat)
+	}
+	return fmt.Errorf("输出格式必须是 %s 之一", strings.Join(outputFormats, ", "))
+}
+
+// 是否输出机器可读格式
+func machineOutput() bool {
+	return outputFormat != outputTable
+}
+
+// 进度和提示信息的输出目标: 机器可读格式下写到 stderr, 避免混入结果
+func statusOut() io.Writer {
+	if machineOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// 输出进度和提示信息
+func statusf(format string, args ...interface{}) {
+	fmt.Fprintf(statusOut(), format, args...)
+}
+
+// 按 --output 输出命令结果
+// table 格式调用 printTable 输出原有的文本, 其余格式序列化 data (结构体或结构体切片)
+func render(data interface{}, printTable func()) error {
+	switch outputFormat {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(data)
+	case outputYAML:
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(data); err != nil {
+			return err
+		}
+		return enc.Close()
+	case outputCSV:
+		return writeCSV(os.Stdout, data, true)
+	default:
+		printTable()
+		return nil
+	}
+}
+
+// 流式输出 (事件监听等逐条产生结果的命令)
+// json 为每行一个对象 (JSON Lines), yaml 为以 --- 分隔的多文档, csv 只输出一次表头
+type streamRenderer struct {
+	yamlEnc     *yaml.Encoder
+	wroteHeader bool
+}
+
+// 输出一条结果
+func (s *streamRenderer) emit(data interface{}, printTable func()) error {
+	switch outputFormat {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(data)
+	case outputYAML:
+		if s.yamlEnc == nil {
+			s.yamlEnc = yaml.NewEncoder(os.Stdout)
+			s.yamlEnc.SetIndent(2)
+		}
+		return s.yamlEnc.Encode(data)
+	case outputCSV:
+		err := writeCSV(os.Stdout, data, !s.wroteHeader)
+		s.wroteHeader = true
+		return err
+	default:
+		printTable()
+		return nil
+	}
+}
+
+// 结束输出
+func (s *streamRenderer) close() error {
+	if s.yamlEnc != nil {
+		return s.yamlEnc.Close()
+	}
+	return nil
+}
+
+// 将结构体或结构体切片写为 CSV, 列名取自 json 标签, 带 csv:"-" 标签的字段不输出
+// []string 以分号连接, map 按键排序后输出为 k=v 并以分号连接, 其余复合值输出为 JSON
+func writeCSV(w io.Writer, data interface{}, header bool) error {
+	v := reflect.Indirect(reflect.ValueOf(data))
+
+	var rows []reflect.Value
+	elemType := v.Type()
+	if v.Kind() == reflect.Slice {
+		elemType = v.Type().Elem()
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, reflect.Indirect(v.Index(i)))
+		}
+	} else {
+		rows = append(rows, v)
+	}
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("不支持 CSV 输出的数据类型: %s", elemType)
+	}
+
+	var columns []int
+	var names []string
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() || field.Tag.Get("csv") == "-" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		columns = append(columns, i)
+		names = append(names, name)
+	}
+
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(names); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for j, i := range columns {
+			record[j] = csvValue(row.Field(i))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// 将单个字段格式化为 CSV 单元格
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			return strings.Join(v.Interface().([]string), ";")
+		}
+	case reflect.Map:
+		if m, ok := v.Interface().(map[string]string); ok {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			pairs := make([]string, len(keys))
+			for i, k := range keys {
+				pairs[i] = k + "=" + m[k]
+			}
+			return strings.Join(pairs, ";")
+		}
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// 密码和主密钥的环境变量
+const (
+	envPassword      = "ONVIFCTL_PASSWORD"        // 单设备命令的密码 (未通过参数或设备配置指定时使用)
+	envMasterKey     = "ONVIFCTL_MASTER_KEY"      // 加密配置文件的主密钥
+	envMasterKeyFile = "ONVIFCTL_MASTER_KEY_FILE" // 保存主密钥的文件
+)
+
+// 加密配置文件参数
+const (
+	cipherAES256GCM    = "aes-256-gcm"
+	kdfPBKDF2SHA256    = "pbkdf2-sha256"
+	defaultKDFRounds   = 600000
+	encryptionSaltSize = 16
+)
+
+var passwordStdin bool
+
+// 从标准输入读取一行秘密信息, 终端下显示提示且不回显
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("读取输入失败: %w", err)
+		}
+		return string(data), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("从标准输入读取失败: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// 命令行显式指定的密码 (-w 或 --password-stdin), 未指定时 ok 为 false
+func passwordFromFlags(cmd *cobra.Command) (pass string, ok bool, err error) {
+	flags := cmd.Flags()
+
+	if flags.Changed("pass") && passwordStdin {
+		return "", false, fmt.Errorf("-w/--pass 与 --password-stdin 不能同时使用")
+	}
+	if flags.Changed("pass") {
+		return password, true, nil
+	}
+	if passwordStdin {
+		pass, err := readSecret("密码: ")
+		if err != nil {
+			return "", false, err
+		}
+		return pass, true, nil
+	}
+
+	return "", false, nil
+}
+
+// 设备配置中是否指定了密码来源
+func (dev DeviceConfig) hasPassword() bool {
+	return dev.Password != "" || dev.PasswordFile != "" || dev.PasswordCmd != ""
+}
+
+// password、password_file、password_cmd 三者最多指定一个
+func (dev DeviceConfig) checkPasswordSources() error {
+	sources := 0
+	for _, s := range []string{dev.Password, dev.PasswordFile, dev.PasswordCmd} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("设备 %s: password、password_file、password_cmd 只能指定一个", dev.Name)
+	}
+	return nil
+}
+
+// 按设备配置取得密码
+func (dev DeviceConfig) resolvePassword() (string, error) {
+	if err := dev.checkPasswordSources(); err != nil {
+		return "", err
+	}
+
+	switch {
+	case dev.PasswordFile != "":
+		data, err := os.ReadFile(dev.resolvePath(dev.PasswordFile))
+		if err != nil {
+			return "", fmt.Errorf("读取 password_file 失败: %w", err)
+		}
+		return firstLine(string(data)), nil
+	case dev.PasswordCmd != "":
+		return runPasswordCmd(dev.PasswordCmd)
+	default:
+		return dev.Password, nil
+	}
+}
+
+// 解析配置中的路径: 支持 ~/ 开头, 相对路径相对于所在配置文件的目录
+func (dev DeviceConfig) resolvePath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) && dev.configDir != "" {
+		return filepath.Join(dev.configDir, path)
+	}
+	return path
+}
+
+// 执行 password_cmd 并取标准输出的第一行 (可用于 pass、secret-tool 等密钥管理工具)
+func runPasswordCmd(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行 password_cmd 失败: %w", err)
+	}
+
+	return firstLine(string(out)), nil
+}
+
+// 取文本的第一行 (去掉行尾换行)
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimRight(line, "\r")
+}
+
+// 取得主密钥: 依次使用 ONVIFCTL_MASTER_KEY、ONVIFCTL_MASTER_KEY_FILE, 最后在终端中输入
+// confirm 为 true 时 (加密新文件) 终端输入需要重复确认
+func masterKey(confirm bool) ([]byte, error) {
+	if key := os.Getenv(envMasterKey); key != "" {
+		return []byte(key), nil
+	}
+
+	if path := os.Getenv(envMasterKeyFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取主密钥文件失败: %w", err)
+		}
+		key := firstLine(string(data))
+		if key == "" {
+			return nil, fmt.Errorf("主密钥文件 %s 为空", path)
+		}
+		return []byte(key), nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("需要主密钥: 请设置 %s 或 %s, 或在终端中运行", envMasterKey, envMasterKeyFile)
+	}
+
+	key, err := readSecret("主密钥: ")
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("主密钥不能为空")
+	}
+
+	if confirm {
+		again, err := readSecret("再次输入主密钥: ")
+		if err != nil {
+			return nil, err
+		}
+		if again != key {
+			return nil, fmt.Errorf("两次输入的主密钥不一致")
+		}
+	}
+
+	return []byte(key), nil
+}
+
+// 加密后的配置文件 (YAML)
+// 由主密钥经 PBKDF2-SHA256 派生 256 位密钥, 使用 AES-256-GCM 加密整个配置
+type encryptedConfig struct {
+	Encrypted  string `yaml:"encrypted"`
+	KDF        string `yaml:"kdf"`
+	Iterations int    `yaml:"iterations"`
+	Salt       string `yaml:"salt"`
+	Nonce      string `yaml:"nonce"`
+	Data       string `yaml:"data"`
+}
+
+// 附加认证数据: 绑定加密参数, 防止被篡改
+func (e *encryptedConfig) additionalData() []byte {
+	return []byte(fmt.Sprintf("onvifctl:%s:%s:%d", e.Encrypted, e.KDF, e.Iterations))
+}
+
+// 由主密钥派生 AES-GCM
+func (e *encryptedConfig) aead(key, salt []byte) (cipher.AEAD, error) {
+	derived, err := pbkdf2.Key(sha256.New, string(key), salt, e.Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// 判断配置文件内容是否已加密
+func parseEncryptedConfig(data []byte) (*encryptedConfig, bool) {
+	var enc encryptedConfig
+	if err := yaml.Unmarshal(data, &enc); err != nil || enc.Encrypted == "" {
+		return nil, false
+	}
+	return &enc, true
+}
+
+// 使用主密钥加密配置文件内容
+func encryptConfig(plain, key []byte) ([]byte, error) {
+	enc := &encryptedConfig{
+		Encrypted:  cipherAES256GCM,
+		KDF:        kdfPBKDF2SHA256,
+		Iterations: defaultKDFRounds,
+	}
+
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := enc.aead(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	enc.Salt = base64.StdEncoding.EncodeToString(salt)
+	enc.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	enc.Data = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plain, enc.additionalData()))
+
+	return yaml.Marshal(enc)
+}
+
+// 使用主密钥解密配置文件内容
+func (e *encryptedConfig) decrypt(key []byte) ([]byte, error) {
+	if e.Encrypted != cipherAES256GCM || e.KDF != kdfPBKDF2SHA256 {
+		return nil, fmt.Errorf("不支持的加密方式: %s/%s", e.Encrypted, e.KDF)
+	}
+	if e.Iterations <= 0 {
+		return nil, fmt.Errorf("无效的密钥派生迭代次数: %d", e.Iterations)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 nonce: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("无效的密文: %w", err)
+	}
+
+	aead, err := e.aead(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("无效的 nonce 长度: %d", len(nonce))
+	}
+
+	plain, err := aead.Open(nil, nonce, data, e.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: 主密钥错误或文件已损坏")
+	}
+
+	return plain, nil
+}
+
+// 读取配置文件, 已加密时用主密钥解密
+// 返回明文和所用的主密钥 (未加密时为 nil), 便于保存时重新加密
+func readConfigFile(filename string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enc, ok := parseEncryptedConfig(data)
+	if !ok {
+		return data, nil, nil
+	}
+
+	key, err := masterKey(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plain, err := enc.decrypt(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plain, key, nil
+}
+
+// 写入配置文件, key 不为空时加密保存 (文件可能包含密码, 仅当前用户可读写)
+// 先写入同目录下的 0600 临时文件再替换原文件, 原文件权限较宽时也随之收紧, 写入中断不会损坏原文件
+func writeConfigFile(filename string, plain, key []byte) error {
+	data := plain
+	if key != nil {
+		var err error
+		if data, err = encryptConfig(plain, key); err != nil {
+			return fmt.Errorf("加密配置失败: %w", err)
+		}
+	}
+
+	// 配置文件是符号链接时替换链接指向的文件
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+
+	// CreateTemp 以 0600 权限创建文件
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 替换成功后文件已不存在
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testPlainConfig = "devices:\n  - name: lobby\n    host: 192.168.1.100\n    password: \"12345\"\n"
+
+// 用当前格式加密的文件, 主密钥为 "correct horse", 明文为 testPlainConfig
+// 此文件必须始终可以解密, 否则用户已加密的配置将无法读取
+const testEncryptedFixture = `encrypted: aes-256-gcm
+kdf: pbkdf2-sha256
+iterations: 600000
+salt: 5/Xemx7HC3PEsDwDh7ewjg==
+nonce: 4XxafkwHhrVPe2sn
+data: 6JInmXRhTM61oNrhhVixmfwRTiBwQWByhLZmS0Hv6BbotfptADBHyAhg9Zn3MUFdQcnhvhBurzDKnep/PtcV7qiXawMLckM8RI/OD139nYjvCsCwGfzP
+`
+
+// 解析加密文件, 不是加密格式时测试失败
+func mustParseEncrypted(t *testing.T, data []byte) *encryptedConfig {
+	t.Helper()
+	enc, ok := parseEncryptedConfig(data)
+	if !ok {
+		t.Fatalf("未识别为加密文件:\n%s", data)
+	}
+	return enc
+}
+
+func TestEncryptConfigRoundTrip(t *testing.T) {
+	key := []byte("correct horse")
+
+	data, err := encryptConfig([]byte(testPlainConfig), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("12345")) || bytes.Contains(data, []byte("lobby")) {
+		t.Fatalf("加密文件中包含明文:\n%s", data)
+	}
+
+	plain, err := mustParseEncrypted(t, data).decrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != testPlainConfig {
+		t.Errorf("解密结果 = %q, want %q", plain, testPlainConfig)
+	}
+
+	// 每次加密使用新的 salt 和 nonce
+	again, err := encryptConfig([]byte(testPlainConfig), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(data, again) {
+		t.Error("两次加密结果相同")
+	}
+}
+
+func TestDecryptFixture(t *testing.T) {
+	plain, err := mustParseEncrypted(t, []byte(testEncryptedFixture)).decrypt([]byte("correct horse"))
+	if err != nil {
+		t.Fatalf("无法解密已有格式的文件: %v", err)
+	}
+	if string(plain) != testPlainConfig {
+		t.Errorf("解密结果 = %q, want %q", plain, testPlainConfig)
+	}
+}
+
+func TestDecryptRejectsBadInput(t *testing.T) {
+	// 在 fixture 上做修改, 每项修改都必须导致解密失败
+	tests := []struct {
+		name   string
+		key    string
+		modify func(e *encryptedConfig)
+	}{
+		{"主密钥错误", "wrong horse", nil},
+		{"认证标签被篡改", "correct horse", func(e *encryptedConfig) {
+			e.Data = flipLastByte(e.Data)
+		}},
+		{"密文被截断", "correct horse", func(e *encryptedConfig) {
+			data, _ := base64.StdEncoding.DecodeString(e.Data)
+			e.Data = base64.StdEncoding.EncodeToString(data[:len(data)-4])
+		}},
+		{"密文过短", "correct horse", func(e *encryptedConfig) {
+			e.Data = base64.StdEncoding.EncodeToString([]byte("short"))
+		}},
+		{"nonce 被篡改", "correct horse", func(e *encryptedConfig) {
+			e.Nonce = flipLastByte(e.Nonce)
+		}},
+		{"nonce 长度错误", "correct horse", func(e *encryptedConfig) {
+			e.Nonce = base64.StdEncoding.EncodeToString([]byte("12345678"))
+		}},
+		{"salt 被篡改", "correct horse", func(e *encryptedConfig) {
+			e.Salt = flipLastByte(e.Salt)
+		}},
+		{"迭代次数被篡改", "correct horse", func(e *encryptedConfig) {
+			e.Iterations = 1000
+		}},
+		{"迭代次数无效", "correct horse", func(e *encryptedConfig) {
+			e.Iterations = 0
+		}},
+		{"不支持的算法", "correct horse", func(e *encryptedConfig) {
+			e.Encrypted = "aes-128-cbc"
+		}},
+		{"base64 无效", "correct horse", func(e *encryptedConfig) {
+			e.Data = "!!!"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := mustParseEncrypted(t, []byte(testEncryptedFixture))
+			if tt.modify != nil {
+				tt.modify(enc)
+			}
+
+			plain, err := enc.decrypt([]byte(tt.key))
+			if err == nil {
+				t.Fatalf("解密应失败, 得到 %q", plain)
+			}
+			if plain != nil {
+				t.Errorf("解密失败时不应返回内容: %q", plain)
+			}
+		})
+	}
+}
+
+// 翻转 base64 数据的最后一个字节
+func flipLastByte(s string) string {
+	data, _ := base64.StdEncoding.DecodeString(s)
+	data[len(data)-1] ^= 0x01
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestParseEncryptedConfigPlain(t *testing.T) {
+	for name, data := range map[string]string{
+		"设备配置":   testPlainConfig,
+		"批量配置":   "devices: []\ntimeout: 10\n",
+		"空文件":    "",
+		"非 YAML": "{not yaml",
+	} {
+		if _, ok := parseEncryptedConfig([]byte(data)); ok {
+			t.Errorf("%s: 不应识别为加密文件", name)
+		}
+	}
+}
+
+func TestConfigFileReadWrite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(envMasterKeyFile, "")
+
+	t.Run("未加密", func(t *testing.T) {
+		path := filepath.Join(dir, "plain.yaml")
+		if err := writeConfigFile(path, []byte(testPlainConfig), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// 读取未加密文件不需要主密钥
+		t.Setenv(envMasterKey, "")
+		plain, key, err := readConfigFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plain) != testPlainConfig || key != nil {
+			t.Errorf("got (%q, %q), want (%q, nil)", plain, key, testPlainConfig)
+		}
+	})
+
+	t.Run("加密", func(t *testing.T) {
+		path := filepath.Join(dir, "encrypted.yaml")
+		if err := writeConfigFile(path, []byte(testPlainConfig), []byte("k1")); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv(envMasterKey, "k1")
+		plain, key, err := readConfigFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plain) != testPlainConfig || string(key) != "k1" {
+			t.Errorf("got (%q, %q), want (%q, k1)", plain, key, testPlainConfig)
+		}
+
+		t.Setenv(envMasterKey, "k2")
+		if _, _, err := readConfigFile(path); err == nil || !strings.Contains(err.Error(), "主密钥错误") {
+			t.Errorf("主密钥错误时应报错, got %v", err)
+		}
+	})
+
+	t.Run("收紧已有文件的权限", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Windows 不支持 Unix 权限位")
+		}
+
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte("devices: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeConfigFile(path, []byte(testPlainConfig), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("权限 = %o, want 600", perm)
+		}
+
+		// 不留下临时文件
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tmp") {
+				t.Errorf("残留临时文件: %s", e.Name())
+			}
+		}
+	})
+}
+
+func TestEncryptedConfigFields(t *testing.T) {
+	// 文件中的字段名是格式的一部分, 不能随意修改
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(testEncryptedFixture), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"encrypted", "kdf", "iterations", "salt", "nonce", "data"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("缺少字段 %s", name)
+		}
+	}
+
+	data, err := encryptConfig([]byte(testPlainConfig), []byte("k"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written map[string]any
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != len(fields) {
+		t.Errorf("写入的字段 %v 与已有格式 %v 不一致", written, fields)
+	}
+}
@@ -0,0 +1,225 @@
+package soap
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 认证模式
+const (
+	AuthWSSecurity = "ws-security" // SOAP 头携带 UsernameToken
+	AuthDigest     = "digest"      // HTTP Digest, 设备只支持 Basic 时自动退回
+	AuthBasic      = "basic"       // HTTP Basic
+	AuthNone       = "none"        // 不认证
+)
+
+// Client SOAP 传输客户端, 负责序列化请求、附加认证信息并发送
+// 可在多个 goroutine 中并发使用
+type Client struct {
+	HTTPClient *http.Client
+	Username   string
+	Password   string
+
+	// Now 返回 WS-Security 令牌的创建时间, 为 nil 时使用本机时间
+	// 设备时钟偏差较大时应返回按偏差校正后的设备时间
+	Now func() time.Time
+
+	// Debugf 输出调试信息 (脱敏后的请求/响应报文、Digest 挑战), 为 nil 时不输出
+	Debugf func(format string, args ...interface{})
+
+	// Recorder 记录每次 HTTP 往返 (已脱敏), 为 nil 时不记录
+	Recorder Recorder
+
+	nonces nonceCache // 最近使用的 WS-Security Nonce
+
+	digestMu        sync.Mutex
+	digestChallenge map[string]string // 缓存的 Digest 挑战参数 (nonce 跨请求复用)
+	nc              int               // digest 认证计数器
+}
+
+// 输出调试信息
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.Debugf != nil {
+		c.Debugf(format, args...)
+	}
+}
+
+// Send 使用指定认证模式发送 SOAP 请求, 返回状态码和响应体
+// 非 200 响应不视为错误, 由调用方通过 ResponseError 转换
+func (c *Client) Send(ctx context.Context, url string, request interface{}, mode string) (int, []byte, error) {
+	env := Envelope{
+		Body: Body{
+			Content: request,
+		},
+	}
+
+	// 仅 WS-Security 模式附加 UsernameToken, 其余模式由 HTTP 层认证
+	if mode == AuthWSSecurity {
+		now := time.Now()
+		if c.Now != nil {
+			now = c.Now()
+		}
+		nonce, err := c.nonces.nonce()
+		if err != nil {
+			return 0, nil, fmt.Errorf("生成认证信息失败: %w", err)
+		}
+		env.Header = NewSecurityHeader(c.Username, c.Password, nonce, now)
+	}
+
+	xmlData, err := xml.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return 0, nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	return c.Do(ctx, "POST", url, "application/soap+xml; charset=utf-8", xmlData, mode)
+}
+
+// Call 发送 SOAP 请求并将响应 Body 中的第一个元素解码到 response (为 nil 时忽略响应内容)
+// 非 200 响应返回 *Fault 或 *HTTPError
+func (c *Client) Call(ctx context.Context, url string, request, response interface{}, mode string) error {
+	statusCode, body, err := c.Send(ctx, url, request, mode)
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusOK {
+		return ResponseError(statusCode, body)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return DecodeBody(body, response)
+}
+
+// Do 发送 HTTP 请求, mode 为 digest 时处理 401 挑战 (Digest, 必要时退回 Basic),
+// 为 basic 时直接携带 Basic 认证头
+// Digest nonce 会被缓存复用, nonce 过期 (stale=true) 时自动重试
+func (c *Client) Do(ctx context.Context, method, url, contentType string, payload []byte, mode string) (int, []byte, error) {
+	httpAuth := mode == AuthDigest
+
+	for attempt := 0; attempt < 3; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return 0, nil, fmt.Errorf("创建请求失败: %w", err)
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		if mode == AuthBasic {
+			req.SetBasicAuth(c.Username, c.Password)
+		} else if httpAuth {
+			auth, err := c.digestAuthorization(method, req.URL.RequestURI())
+			if err != nil {
+				return 0, nil, fmt.Errorf("生成认证信息失败: %w", err)
+			}
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			} else if attempt > 0 {
+				req.SetBasicAuth(c.Username, c.Password)
+			}
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			c.trace(newExchange(start, req, payload, nil, nil, err))
+			return 0, nil, fmt.Errorf("发送请求失败: %w", err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.trace(newExchange(start, req, payload, resp, body, err))
+		if err != nil {
+			return 0, nil, fmt.Errorf("读取响应失败: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized || !httpAuth {
+			return resp.StatusCode, body, nil
+		}
+
+		challenge := SelectDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+		if challenge == nil {
+			// 设备只支持 Basic 认证时,带上 Basic 头重试一次
+			if attempt == 0 && strings.HasPrefix(strings.ToLower(resp.Header.Get("WWW-Authenticate")), "basic") {
+				continue
+			}
+			return resp.StatusCode, body, nil
+		}
+
+		hadChallenge := c.setDigestChallenge(challenge)
+
+		c.debugf("收到 Digest 挑战: realm=%s, algorithm=%s, stale=%s\n",
+			challenge["realm"], challenge["algorithm"], challenge["stale"])
+
+		// 首次挑战或缓存的 nonce 已失效时重试,否则说明凭据错误
+		if !hadChallenge || attempt == 0 || strings.EqualFold(challenge["stale"], "true") {
+			continue
+		}
+
+		return resp.StatusCode, body, nil
+	}
+
+	return http.StatusUnauthorized, nil, fmt.Errorf("Digest 认证失败, 多次重试后仍被拒绝: %w", &HTTPError{StatusCode: http.StatusUnauthorized})
+}
+
+// 输出并记录一次往返
+func (c *Client) trace(ex *Exchange) {
+	c.debugf("%s", ex.debugString())
+	if c.Recorder != nil {
+		c.Recorder.Record(ex)
+	}
+}
+
+// 未配置 HTTPClient 时使用默认客户端
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// 缓存新的 Digest 挑战并重置计数器,返回之前是否已有缓存
+func (c *Client) setDigestChallenge(challenge map[string]string) bool {
+	c.digestMu.Lock()
+	defer c.digestMu.Unlock()
+
+	hadChallenge := c.digestChallenge != nil
+	c.digestChallenge = challenge
+	c.nc = 0
+
+	return hadChallenge
+}
+
+// 使用缓存的 Digest 挑战生成 Authorization 头,没有缓存时返回空串
+// 无法生成随机 cnonce 时返回错误
+func (c *Client) digestAuthorization(method, uri string) (string, error) {
+	c.digestMu.Lock()
+	defer c.digestMu.Unlock()
+
+	if c.digestChallenge == nil {
+		return "", nil
+	}
+
+	cnonce := make([]byte, 8)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	c.nc++
+
+	return GenerateDigestAuth(c.Username, c.Password, method, uri,
+		c.digestChallenge, c.nc, hex.EncodeToString(cnonce)), nil
+}
@@ -0,0 +1,198 @@
+package soap
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// ParseDigestAuthHeader 解析 WWW-Authenticate 中的 Digest 挑战参数
+// 支持带引号且包含逗号的参数值 (如 qop="auth,auth-int")
+func ParseDigestAuthHeader(header string) map[string]string {
+	params := make(map[string]string)
+
+	header = strings.TrimSpace(header)
+	if len(header) >= 6 && strings.EqualFold(header[:6], "Digest") {
+		header = header[6:]
+	}
+
+	for header != "" {
+		header = strings.TrimLeft(header, " \t,")
+		eq := strings.Index(header, "=")
+		if eq == -1 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(header[:eq]))
+		header = strings.TrimLeft(header[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(header, "\"") {
+			end := 1
+			for end < len(header) && header[end] != '"' {
+				if header[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end > len(header) {
+				end = len(header)
+			}
+			value = strings.ReplaceAll(header[1:end], "\\", "")
+			if end < len(header) {
+				end++
+			}
+			header = header[end:]
+		} else if comma := strings.Index(header, ","); comma != -1 {
+			value = strings.TrimSpace(header[:comma])
+			header = header[comma:]
+		} else {
+			value = strings.TrimSpace(header)
+			header = ""
+		}
+
+		params[key] = value
+	}
+
+	return params
+}
+
+// SelectDigestChallenge 从多个 WWW-Authenticate 头中选出最合适的 Digest 挑战
+// 一个头中可以包含多个挑战 (如 Basic realm="a", Digest realm="b", ...)
+// 优先选择 SHA-256,其次 MD5,不支持的算法会被忽略
+func SelectDigestChallenge(headers []string) map[string]string {
+	var selected map[string]string
+	for _, header := range headers {
+		for _, challenge := range splitChallenges(header) {
+			if !strings.EqualFold(strings.Fields(challenge)[0], "digest") {
+				continue
+			}
+
+			params := ParseDigestAuthHeader(challenge)
+			switch strings.ToUpper(params["algorithm"]) {
+			case "SHA-256", "SHA-256-SESS":
+				return params
+			case "", "MD5", "MD5-SESS":
+				if selected == nil {
+					selected = params
+				}
+			}
+		}
+	}
+	return selected
+}
+
+// 按挑战拆分 WWW-Authenticate 头, 引号内的逗号不作为分隔
+func splitChallenges(header string) []string {
+	var challenges []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			challenges = append(challenges, s)
+		}
+	}
+
+	start, quoted := 0, false
+	for i := 0; i < len(header); i++ {
+		switch c := header[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted && isChallengeStart(header[i+1:]):
+			add(header[start:i])
+			start = i + 1
+		}
+	}
+	add(header[start:])
+
+	return challenges
+}
+
+// 逗号后是否为新的挑战: 认证方案名后面不是 "=" (参数) 而是空白或结尾
+func isChallengeStart(s string) bool {
+	s = strings.TrimLeft(s, " \t")
+	end := strings.IndexAny(s, " \t=,")
+	if end == -1 {
+		return s != ""
+	}
+	return end > 0 && s[end] != '=' && s[end] != ',' && !strings.HasPrefix(strings.TrimLeft(s[end:], " \t"), "=")
+}
+
+// GenerateDigestAuth 按 RFC 7616 生成 Digest Authorization 头
+// nc 为该 nonce 的请求计数, cnonce 为客户端随机数
+func GenerateDigestAuth(username, password, method, uri string, params map[string]string, nc int, cnonce string) string {
+	realm := params["realm"]
+	nonce := params["nonce"]
+	opaque := params["opaque"]
+	algorithm := params["algorithm"]
+
+	hash := md5Hash
+	if strings.HasPrefix(strings.ToUpper(algorithm), "SHA-256") {
+		hash = sha256Hash
+	}
+
+	// 仅支持 qop=auth, auth-int 需要对消息体做摘要
+	qop := ""
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+			break
+		}
+	}
+
+	uriPath := uri
+	if idx := strings.Index(uri, "://"); idx != -1 {
+		remaining := uri[idx+3:]
+		if idx2 := strings.Index(remaining, "/"); idx2 != -1 {
+			uriPath = remaining[idx2:]
+		} else {
+			uriPath = "/"
+		}
+	}
+
+	ncValue := fmt.Sprintf("%08x", nc)
+
+	ha1 := hash(fmt.Sprintf("%s:%s:%s", username, realm, password))
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = hash(fmt.Sprintf("%s:%s:%s", ha1, nonce, cnonce))
+	}
+
+	ha2 := hash(fmt.Sprintf("%s:%s", method, uriPath))
+
+	var response string
+	if qop != "" {
+		response = hash(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, nonce, ncValue, cnonce, qop, ha2))
+	} else {
+		response = hash(fmt.Sprintf("%s:%s:%s", ha1, nonce, ha2))
+	}
+
+	authStr := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		username, realm, nonce, uriPath, response)
+
+	if algorithm != "" {
+		authStr += fmt.Sprintf(", algorithm=%s", algorithm)
+	}
+
+	if qop != "" {
+		authStr += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="%s"`, qop, ncValue, cnonce)
+	}
+
+	if opaque != "" {
+		authStr += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+
+	return authStr
+}
+
+func md5Hash(text string) string {
+	hash := md5.New()
+	hash.Write([]byte(text))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+func sha256Hash(text string) string {
+	hash := sha256.New()
+	hash.Write([]byte(text))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
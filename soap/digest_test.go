@@ -0,0 +1,222 @@
+package soap
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// RFC 7616 §3.9.1 的挑战, MD5 和 SHA-256 共用
+var rfc7616Challenge = map[string]string{
+	"realm":  "http-auth@example.org",
+	"qop":    "auth, auth-int",
+	"nonce":  "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+	"opaque": "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+}
+
+const rfc7616Cnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+
+// 取出 Authorization 头中的参数值
+func authParam(t *testing.T, header, key string) string {
+	t.Helper()
+	params := ParseDigestAuthHeader(header)
+	v, ok := params[key]
+	if !ok {
+		t.Fatalf("Authorization 头缺少 %s: %s", key, header)
+	}
+	return v
+}
+
+func withAlgorithm(params map[string]string, algorithm string) map[string]string {
+	out := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		out[k] = v
+	}
+	out["algorithm"] = algorithm
+	return out
+}
+
+func TestGenerateDigestAuthRFC7616(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		response  string
+	}{
+		{"MD5", "8ca523f5e9506fed4657c9700eebdbec"},
+		{"SHA-256", "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			params := withAlgorithm(rfc7616Challenge, tt.algorithm)
+			header := GenerateDigestAuth("Mufasa", "Circle of Life", "GET", "/dir/index.html", params, 1, rfc7616Cnonce)
+
+			want := map[string]string{
+				"username":  "Mufasa",
+				"realm":     "http-auth@example.org",
+				"uri":       "/dir/index.html",
+				"response":  tt.response,
+				"algorithm": tt.algorithm,
+				"qop":       "auth",
+				"nc":        "00000001",
+				"cnonce":    rfc7616Cnonce,
+				"opaque":    rfc7616Challenge["opaque"],
+			}
+			for k, v := range want {
+				if got := authParam(t, header, k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDigestAuthRFC2617(t *testing.T) {
+	params := ParseDigestAuthHeader(`Digest realm="testrealm@host.com", qop="auth,auth-int", ` +
+		`nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`)
+
+	// 完整 URL 只取路径参与摘要
+	header := GenerateDigestAuth("Mufasa", "Circle Of Life", "GET", "http://www.nowhere.org/dir/index.html", params, 1, "0a4f113b")
+
+	if got, want := authParam(t, header, "response"), "6629fae49393a05397450978507c4ef1"; got != want {
+		t.Errorf("response = %q, want %q", got, want)
+	}
+	if got := authParam(t, header, "uri"); got != "/dir/index.html" {
+		t.Errorf("uri = %q, want /dir/index.html", got)
+	}
+	if strings.Contains(header, "algorithm=") {
+		t.Errorf("挑战未指定算法时不应发送 algorithm: %s", header)
+	}
+}
+
+func TestGenerateDigestAuthSess(t *testing.T) {
+	hashes := map[string]func(string) string{
+		"MD5-sess": func(s string) string { return fmt.Sprintf("%x", md5.Sum([]byte(s))) },
+		"SHA-256-sess": func(s string) string {
+			return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+		},
+	}
+
+	for algorithm, h := range hashes {
+		t.Run(algorithm, func(t *testing.T) {
+			params := withAlgorithm(rfc7616Challenge, algorithm)
+			header := GenerateDigestAuth("Mufasa", "Circle of Life", "GET", "/dir/index.html", params, 2, rfc7616Cnonce)
+
+			// RFC 7616 §3.4.2: -sess 算法的 HA1 再与 nonce、cnonce 做一次摘要
+			nonce := params["nonce"]
+			ha1 := h(h("Mufasa:http-auth@example.org:Circle of Life") + ":" + nonce + ":" + rfc7616Cnonce)
+			ha2 := h("GET:/dir/index.html")
+			want := h(ha1 + ":" + nonce + ":00000002:" + rfc7616Cnonce + ":auth:" + ha2)
+
+			if got := authParam(t, header, "response"); got != want {
+				t.Errorf("response = %q, want %q", got, want)
+			}
+			if got := authParam(t, header, "nc"); got != "00000002" {
+				t.Errorf("nc = %q, want 00000002", got)
+			}
+		})
+	}
+}
+
+func TestGenerateDigestAuthWithoutQop(t *testing.T) {
+	// RFC 2069 兼容模式: 不带 qop 时不发送 nc/cnonce
+	params := map[string]string{"realm": "testrealm@host.com", "nonce": "dcd98b7102dd2f0e8b11d0f600bfb0c093"}
+	header := GenerateDigestAuth("Mufasa", "Circle Of Life", "GET", "/dir/index.html", params, 1, "0a4f113b")
+
+	h := func(s string) string { return fmt.Sprintf("%x", md5.Sum([]byte(s))) }
+	want := h(h("Mufasa:testrealm@host.com:Circle Of Life") + ":dcd98b7102dd2f0e8b11d0f600bfb0c093:" + h("GET:/dir/index.html"))
+
+	if got := authParam(t, header, "response"); got != want {
+		t.Errorf("response = %q, want %q", got, want)
+	}
+	for _, key := range []string{"qop", "nc", "cnonce"} {
+		if _, ok := ParseDigestAuthHeader(header)[key]; ok {
+			t.Errorf("不带 qop 时不应发送 %s: %s", key, header)
+		}
+	}
+}
+
+func TestParseDigestAuthHeader(t *testing.T) {
+	header := `Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, ` +
+		`nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", stale=FALSE, opaque="a\"b,c"`
+
+	got := ParseDigestAuthHeader(header)
+	want := map[string]string{
+		"realm":     "http-auth@example.org",
+		"qop":       "auth, auth-int",
+		"algorithm": "SHA-256",
+		"nonce":     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+		"stale":     "FALSE",
+		"opaque":    `a"b,c`,
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("解析出 %d 个参数, want %d: %v", len(got), len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestSelectDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		headers   []string
+		wantNonce string // 为空表示没有可用的挑战
+	}{
+		{
+			name: "SHA-256 优先于 MD5",
+			headers: []string{
+				`Digest realm="cam", nonce="md5", algorithm=MD5, qop="auth"`,
+				`Digest realm="cam", nonce="sha", algorithm=SHA-256, qop="auth"`,
+			},
+			wantNonce: "sha",
+		},
+		{
+			name:      "未指定算法视为 MD5",
+			headers:   []string{`Basic realm="cam"`, `Digest realm="cam", nonce="plain"`},
+			wantNonce: "plain",
+		},
+		{
+			name: "一个头中的多个挑战",
+			headers: []string{
+				`Basic realm="cam, main", Digest realm="cam", qop="auth,auth-int", nonce="md5", algorithm=MD5, ` +
+					`Digest realm="cam", qop="auth", nonce="sha", algorithm=SHA-256, Negotiate`,
+			},
+			wantNonce: "sha",
+		},
+		{
+			name:      "多个挑战中跳过不支持的算法",
+			headers:   []string{`Digest realm="cam", nonce="x", algorithm=SHA-512-256, Digest realm="cam", nonce="md5"`},
+			wantNonce: "md5",
+		},
+		{
+			name:    "没有 Digest 挑战",
+			headers: []string{`Basic realm="cam"`, `Negotiate abc==`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectDigestChallenge(tt.headers)
+			if tt.wantNonce == "" {
+				if got != nil {
+					t.Fatalf("got %v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("没有选出挑战, want nonce %q", tt.wantNonce)
+			}
+			if got["nonce"] != tt.wantNonce {
+				t.Errorf("nonce = %q, want %q", got["nonce"], tt.wantNonce)
+			}
+			if got["realm"] != "cam" {
+				t.Errorf("realm = %q, want cam", got["realm"])
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package soap 实现 ONVIF 使用的 SOAP 1.2 传输层:
+// 信封序列化、WS-Security/Digest/Basic 认证、Fault 解析以及忽略命名空间前缀的响应解码
+package soap
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// SOAP 1.2 与 WS-Security 命名空间
+const (
+	NamespaceEnvelope = "http://www.w3.org/2003/05/soap-envelope"
+	NamespaceWSSE     = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	NamespaceWSU      = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+
+	passwordDigestType = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	base64EncodingType = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+)
+
+// Envelope SOAP 信封
+type Envelope struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2003/05/soap-envelope Envelope"`
+	Header  *Header  `xml:"Header,omitempty"`
+	Body    Body     `xml:"Body"`
+}
+
+// Header SOAP 头, 目前只携带 WS-Security
+type Header struct {
+	Security Security `xml:"Security"`
+}
+
+// Security WS-Security 头
+type Security struct {
+	XMLName        xml.Name `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Security"`
+	UsernameToken  UsernameToken
+	MustUnderstand string `xml:"http://www.w3.org/2003/05/soap-envelope mustUnderstand,attr"`
+}
+
+// UsernameToken WS-Security 用户名令牌 (PasswordDigest)
+type UsernameToken struct {
+	Username string   `xml:"Username"`
+	Password Password `xml:"Password"`
+	Nonce    Nonce    `xml:"Nonce"`
+	Created  string   `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd Created"`
+}
+
+// Password 令牌中的密码摘要
+type Password struct {
+	Type  string `xml:"Type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Nonce 令牌中的随机数 (Base64)
+type Nonce struct {
+	EncodingType string `xml:"EncodingType,attr,omitempty"`
+	Value        string `xml:",chardata"`
+}
+
+// Body SOAP 消息体, Content 为具体的请求结构
+type Body struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2003/05/soap-envelope Body"`
+	Content interface{}
+}
+
+// NewSecurityHeader 生成 WS-Security UsernameToken 头, created 为令牌创建时间 (应使用设备时间)
+// nonce 应由 NewNonce 生成, 且每个令牌不同
+func NewSecurityHeader(username, password string, nonce []byte, created time.Time) *Header {
+	createdStr := created.UTC().Format(time.RFC3339)
+
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(createdStr))
+	h.Write([]byte(password))
+	digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	return &Header{
+		Security: Security{
+			MustUnderstand: "1",
+			UsernameToken: UsernameToken{
+				Username: username,
+				Password: Password{
+					Type:  passwordDigestType,
+					Value: digest,
+				},
+				Nonce: Nonce{
+					EncodingType: base64EncodingType,
+					Value:        base64.StdEncoding.EncodeToString(nonce),
+				},
+				Created: createdStr,
+			},
+		},
+	}
+}
+
+// DecodeBody 解码 SOAP 响应 Body 中的第一个元素 (按本地名匹配, 忽略命名空间前缀)
+func DecodeBody(data []byte, v interface{}) error {
+	var env struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	if err := xml.Unmarshal(env.Body.Inner, v); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return nil
+}
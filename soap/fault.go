@@ -0,0 +1,211 @@
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Fault 设备返回的 SOAP 1.2 Fault
+// 可通过 errors.As 从包装后的错误中取出
+type Fault struct {
+	StatusCode int      // HTTP 状态码
+	Code       string   // 顶层错误码, 如 env:Sender / env:Receiver
+	Subcodes   []string // 子码链 (由外到内), 如 ter:NotAuthorized
+	Reason     string   // 设备给出的原因描述
+	Detail     string   // Detail 元素中的文本 (可能为空)
+}
+
+func (f *Fault) Error() string {
+	codes := append([]string{f.Code}, f.Subcodes...)
+	msg := fmt.Sprintf("SOAP Fault [%s]", strings.Join(codes, " / "))
+	if f.Reason != "" {
+		msg += ": " + f.Reason
+	}
+	return msg
+}
+
+// HasCode 判断错误码或子码链中是否包含指定码 (按本地名比较, 忽略命名空间前缀)
+func (f *Fault) HasCode(name string) bool {
+	if localName(f.Code) == name {
+		return true
+	}
+	for _, sub := range f.Subcodes {
+		if localName(sub) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPError 没有 SOAP Fault 的非 200 响应
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("请求失败，状态码: %d", e.StatusCode)
+}
+
+// SOAP 1.2 Fault 结构 (兼容 SOAP 1.1 的 faultcode/faultstring)
+type faultCode struct {
+	Value   string     `xml:"Value"`
+	Subcode *faultCode `xml:"Subcode"`
+}
+
+type faultText struct {
+	Lang  string `xml:"lang,attr"`
+	Value string `xml:",chardata"`
+}
+
+type soapFaultXML struct {
+	Code   faultCode `xml:"Code"`
+	Reason struct {
+		Texts []faultText `xml:"Text"`
+	} `xml:"Reason"`
+	Detail struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"Detail"`
+
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+}
+
+// ParseFault 从响应体中解析 SOAP Fault, 不是 Fault 时返回 nil
+func ParseFault(statusCode int, body []byte) *Fault {
+	var env struct {
+		Body struct {
+			Fault *soapFaultXML `xml:"Fault"`
+		} `xml:"Body"`
+	}
+
+	if err := xml.Unmarshal(body, &env); err != nil || env.Body.Fault == nil {
+		return nil
+	}
+
+	raw := env.Body.Fault
+	fault := &Fault{
+		StatusCode: statusCode,
+		Code:       strings.TrimSpace(raw.Code.Value),
+		Detail:     strings.TrimSpace(stripTags(raw.Detail.Inner)),
+	}
+
+	for sub := raw.Code.Subcode; sub != nil; sub = sub.Subcode {
+		if v := strings.TrimSpace(sub.Value); v != "" {
+			fault.Subcodes = append(fault.Subcodes, v)
+		}
+	}
+
+	// 优先使用英文 Reason, 否则取第一条
+	for _, text := range raw.Reason.Texts {
+		if strings.HasPrefix(strings.ToLower(text.Lang), "en") {
+			fault.Reason = strings.TrimSpace(text.Value)
+			break
+		}
+	}
+	if fault.Reason == "" && len(raw.Reason.Texts) > 0 {
+		fault.Reason = strings.TrimSpace(raw.Reason.Texts[0].Value)
+	}
+
+	// SOAP 1.1 格式
+	if fault.Code == "" {
+		fault.Code = strings.TrimSpace(raw.FaultCode)
+		fault.Reason = strings.TrimSpace(raw.FaultString)
+	}
+
+	return fault
+}
+
+// ResponseError 将非 200 响应转换为错误: 优先解析 SOAP Fault, 否则返回 HTTPError
+func ResponseError(statusCode int, body []byte) error {
+	if fault := ParseFault(statusCode, body); fault != nil {
+		return fault
+	}
+	return &HTTPError{StatusCode: statusCode}
+}
+
+// 去掉 XML 标签, 仅保留文本
+func stripTags(s string) string {
+	var b strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+			b.WriteRune(' ')
+		case r == '>':
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// 去掉 QName 的命名空间前缀
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+// 认证相关的 Fault 码
+var authFaultCodes = []string{
+	"NotAuthorized", "FailedAuthentication", "InvalidSecurity",
+	"InvalidSecurityToken", "SecurityTokenUnavailable", "MessageExpired",
+}
+
+// 不支持操作相关的 Fault 码
+var unsupportedFaultCodes = []string{
+	"ActionNotSupported", "NoSuchService", "NotImplemented",
+	"OptionalActionNotImplemented",
+}
+
+// 参数错误相关的 Fault 码
+var invalidArgsFaultCodes = []string{
+	"InvalidArgVal", "InvalidArgs", "InvalidArgument", "InvalidArg",
+}
+
+// HasAnyCode 判断错误码或子码链中是否包含任意一个指定码
+func (f *Fault) HasAnyCode(names ...string) bool {
+	for _, name := range names {
+		if f.HasCode(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAuth 是否为认证失败 (含 HTTP 401/403)
+func (f *Fault) IsAuth() bool {
+	return f.HasAnyCode(authFaultCodes...) ||
+		f.StatusCode == http.StatusUnauthorized || f.StatusCode == http.StatusForbidden
+}
+
+// IsNotSupported 是否为设备不支持该操作
+func (f *Fault) IsNotSupported() bool {
+	return f.HasAnyCode(unsupportedFaultCodes...)
+}
+
+// IsInvalidArgs 是否为请求参数被拒绝
+func (f *Fault) IsInvalidArgs() bool {
+	return f.HasAnyCode(invalidArgsFaultCodes...)
+}
+
+// IsAuthError 判断错误是否由认证失败引起 (SOAP Fault 或 HTTP 401/403)
+func IsAuthError(err error) bool {
+	var fault *Fault
+	if errors.As(err, &fault) {
+		return fault.IsAuth()
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package soap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// SOAP 1.2 Fault 响应体, code 为 env:Code 的完整内容
+func fault12(code, reason string) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">
+  <env:Body>
+    <env:Fault>
+      <env:Code>%s</env:Code>
+      <env:Reason>%s</env:Reason>
+    </env:Fault>
+  </env:Body>
+</env:Envelope>`, code, reason))
+}
+
+func TestParseFault(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       []byte
+		want       Fault
+
+		auth, notSupported, invalidArgs bool
+	}{
+		{
+			name:       "NotAuthorized",
+			statusCode: http.StatusBadRequest,
+			body: fault12(
+				`<env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:NotAuthorized</env:Value></env:Subcode>`,
+				`<env:Text xml:lang="en">Sender not Authorized</env:Text>`),
+			want: Fault{StatusCode: 400, Code: "env:Sender", Subcodes: []string{"ter:NotAuthorized"}, Reason: "Sender not Authorized"},
+			auth: true,
+		},
+		{
+			name:       "ActionNotSupported",
+			statusCode: http.StatusInternalServerError,
+			body: fault12(
+				`<env:Value>env:Receiver</env:Value><env:Subcode><env:Value>ter:ActionNotSupported</env:Value>`+
+					`<env:Subcode><env:Value>ter:NoImagingForSource</env:Value></env:Subcode></env:Subcode>`,
+				`<env:Text xml:lang="en">Optional Action Not Implemented</env:Text>`),
+			want: Fault{StatusCode: 500, Code: "env:Receiver", Subcodes: []string{"ter:ActionNotSupported", "ter:NoImagingForSource"},
+				Reason: "Optional Action Not Implemented"},
+			notSupported: true,
+		},
+		{
+			name:       "InvalidArgVal 嵌套两层",
+			statusCode: http.StatusBadRequest,
+			body: fault12(
+				`<env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:InvalidArgVal</env:Value>`+
+					`<env:Subcode><env:Value>ter:NoProfile</env:Value></env:Subcode></env:Subcode>`,
+				`<env:Text xml:lang="de">Profil existiert nicht</env:Text><env:Text xml:lang="en">No such profile</env:Text>`),
+			want: Fault{StatusCode: 400, Code: "env:Sender", Subcodes: []string{"ter:InvalidArgVal", "ter:NoProfile"},
+				Reason: "No such profile"},
+			invalidArgs: true,
+		},
+		{
+			name:       "没有英文 Reason 时取第一条",
+			statusCode: http.StatusInternalServerError,
+			body: fault12(`<env:Value>env:Receiver</env:Value>`,
+				`<env:Text xml:lang="zh">内部错误</env:Text><env:Text xml:lang="ja">内部エラー</env:Text>`),
+			want: Fault{StatusCode: 500, Code: "env:Receiver", Reason: "内部错误"},
+		},
+		{
+			name:       "HTTP 401 视为认证失败",
+			statusCode: http.StatusUnauthorized,
+			body:       fault12(`<env:Value>env:Sender</env:Value>`, `<env:Text xml:lang="en">Unauthorized</env:Text>`),
+			want:       Fault{StatusCode: 401, Code: "env:Sender", Reason: "Unauthorized"},
+			auth:       true,
+		},
+		{
+			name:       "SOAP 1.1",
+			statusCode: http.StatusInternalServerError,
+			body: []byte(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>` +
+				`<faultcode>s:Client</faultcode><faultstring>Invalid argument</faultstring>` +
+				`<detail><ter:Info xmlns:ter="http://www.onvif.org/ver10/error">bad token</ter:Info></detail>` +
+				`</s:Fault></s:Body></s:Envelope>`),
+			want: Fault{StatusCode: 500, Code: "s:Client", Reason: "Invalid argument"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseFault(tt.statusCode, tt.body)
+			if got == nil {
+				t.Fatal("ParseFault 返回 nil")
+			}
+			got.Detail = "" // Detail 单独测试
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+
+			if got.IsAuth() != tt.auth {
+				t.Errorf("IsAuth() = %v, want %v", got.IsAuth(), tt.auth)
+			}
+			if got.IsNotSupported() != tt.notSupported {
+				t.Errorf("IsNotSupported() = %v, want %v", got.IsNotSupported(), tt.notSupported)
+			}
+			if got.IsInvalidArgs() != tt.invalidArgs {
+				t.Errorf("IsInvalidArgs() = %v, want %v", got.IsInvalidArgs(), tt.invalidArgs)
+			}
+		})
+	}
+}
+
+func TestParseFaultDetail(t *testing.T) {
+	body := []byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+		`<env:Code><env:Value>env:Receiver</env:Value></env:Code>` +
+		`<env:Reason><env:Text xml:lang="en">Error</env:Text></env:Reason>` +
+		`<env:Detail><env:Text>Encoder   busy</env:Text><code>42</code></env:Detail>` +
+		`</env:Fault></env:Body></env:Envelope>`)
+
+	got := ParseFault(http.StatusInternalServerError, body)
+	if got == nil {
+		t.Fatal("ParseFault 返回 nil")
+	}
+	if want := "Encoder busy 42"; got.Detail != want {
+		t.Errorf("Detail = %q, want %q", got.Detail, want)
+	}
+}
+
+func TestParseFaultNotFault(t *testing.T) {
+	bodies := map[string][]byte{
+		"正常响应": []byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>` +
+			`<tds:GetSystemDateAndTimeResponse xmlns:tds="http://www.onvif.org/ver10/device/wsdl"/></env:Body></env:Envelope>`),
+		"HTML 错误页": []byte(`<html><body>401 Unauthorized</body></html>`),
+		"空响应":      nil,
+	}
+
+	for name, body := range bodies {
+		if got := ParseFault(http.StatusInternalServerError, body); got != nil {
+			t.Errorf("%s: got %+v, want nil", name, got)
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	err := ResponseError(http.StatusUnauthorized, []byte(`<html>Unauthorized</html>`))
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("got %T, want *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want 401", httpErr.StatusCode)
+	}
+	if !IsAuthError(fmt.Errorf("获取设备信息失败: %w", err)) {
+		t.Error("包装后的 401 应判断为认证失败")
+	}
+
+	err = ResponseError(http.StatusBadRequest, fault12(
+		`<env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:FailedAuthentication</env:Value></env:Subcode>`, ""))
+	if _, ok := err.(*Fault); !ok {
+		t.Fatalf("got %T, want *Fault", err)
+	}
+	if !IsAuthError(fmt.Errorf("包装: %w", err)) {
+		t.Error("FailedAuthentication 应判断为认证失败")
+	}
+}
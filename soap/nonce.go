@@ -0,0 +1,77 @@
+package soap
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+)
+
+// WS-Security Nonce 长度 (字节)
+const nonceSize = 16
+
+// 每个客户端记住的最近 Nonce 数量
+const nonceCacheSize = 1024
+
+// NewNonce 生成 WS-Security UsernameToken 使用的随机 Nonce
+func NewNonce() ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return nonce, nil
+}
+
+// NewUUID 生成 RFC 4122 第 4 版 (随机) UUID, 用于 WS-Addressing MessageID
+func NewUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40 // 版本 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 变体
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// 最近使用过的 Nonce, 保证同一客户端 (包括重试) 不会重复发送相同的 Nonce
+// 设备通常会拒绝重放的 Nonce
+type nonceCache struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+	ring []string // 按使用顺序保存, 超过容量时淘汰最早的
+	next int
+}
+
+// 生成一个最近未使用过的 Nonce 并记录
+func (n *nonceCache) nonce() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.seen == nil {
+		n.seen = make(map[string]struct{}, nonceCacheSize)
+	}
+
+	for {
+		nonce, err := NewNonce()
+		if err != nil {
+			return nil, err
+		}
+
+		key := string(nonce)
+		if _, dup := n.seen[key]; dup {
+			continue
+		}
+
+		if len(n.ring) < nonceCacheSize {
+			n.ring = append(n.ring, key)
+		} else {
+			delete(n.seen, n.ring[n.next])
+			n.ring[n.next] = key
+			n.next = (n.next + 1) % nonceCacheSize
+		}
+		n.seen[key] = struct{}{}
+
+		return nonce, nil
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"onvifctl/onvif"
+)
+
+var (
+	knownHostsOnce sync.Once
+	knownHosts     *onvif.KnownHosts
+	knownHostsErr  error
+)
+
+// 首次信任的证书指纹记录, 与用户配置文件位于同一目录
+func knownHostsStore() (*onvif.KnownHosts, error) {
+	knownHostsOnce.Do(func() {
+		path, err := userConfigPath()
+		if err != nil {
+			knownHostsErr = err
+			return
+		}
+
+		path = filepath.Join(filepath.Dir(path), "known_hosts")
+		knownHosts = &onvif.KnownHosts{
+			Path: path,
+			OnAdd: func(addr, fingerprint string) {
+				fmt.Fprintf(os.Stderr, "⚠️  首次连接 %s, 证书未经 CA 签发, 已信任并记录指纹到 %s\n", addr, path)
+				fmt.Fprintf(os.Stderr, "   %s\n", fingerprint)
+			},
+		}
+	})
+
+	return knownHosts, knownHostsErr
+}
+
+// 根据全局参数生成证书校验选项, fingerprint 为设备配置中固定的指纹 (可为空)
+// 出错时仍返回不含 known_hosts 的选项
+func tlsOptions(fingerprint string) (onvif.TLSOptions, error) {
+	opts := onvif.TLSOptions{
+		CAFile:      caFile,
+		ClientCert:  clientCert,
+		ClientKey:   clientKey,
+		Insecure:    insecure,
+		Fingerprint: fingerprint,
+	}
+
+	if !insecure && caFile == "" && fingerprint == "" {
+		store, err := knownHostsStore()
+		if err != nil {
+			return opts, err
+		}
+		opts.KnownHosts = store
+	}
+
+	return opts, nil
+}
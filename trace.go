@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"onvifctl/soap"
+)
+
+// --trace 记录器, 未指定时为 nil
+var (
+	traceRecorder soap.Recorder
+	traceClose    func() error
+)
+
+// 打开 --trace 指定的文件: 扩展名为 .har 时结束后写出 HAR 文档, 否则逐条写入 JSON Lines
+func openTrace(version string) error {
+	if traceFile == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("创建 trace 文件失败: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(traceFile), ".har") {
+		har := &soap.HARRecorder{Creator: "onvifctl", Version: version}
+		traceRecorder = har
+		traceClose = func() error {
+			if err := har.WriteHAR(f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		}
+		return nil
+	}
+
+	jsonl := soap.NewJSONLRecorder(f)
+	traceRecorder = jsonl
+	traceClose = func() error {
+		if err := jsonl.Err(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+
+	return nil
+}
+
+// 结束记录并关闭 trace 文件
+func closeTrace() error {
+	if traceClose == nil {
+		return nil
+	}
+	return traceClose()
+}